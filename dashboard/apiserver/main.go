@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"log"
 	"os"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/handlers"
+	"open-cluster-management-io/lab/apiserver/pkg/history"
 	"open-cluster-management-io/lab/apiserver/pkg/server"
 )
 
@@ -18,6 +21,25 @@ func main() {
 	// Initialize Kubernetes client
 	ocmClient := client.CreateKubernetesClient()
 
+	// Record placement decision changes for the history endpoint. bbolt locks
+	// the database file, so a second process using the same file cannot open it
+	// and runs without history; the chart runs a single replica for this reason.
+	historyOpts := history.OptionsFromEnv()
+	if historyOpts.Path == "" {
+		log.Println("Placement history disabled: DASHBOARD_HISTORY_PATH is not set")
+	} else if store, err := history.Open(historyOpts); err != nil {
+		log.Printf("Placement history disabled: %v", err)
+	} else {
+		defer store.Close()
+		ocmClient.PlacementHistory = store
+		if err := handlers.RecordPlacementHistory(ctx, ocmClient); err != nil {
+			log.Printf("Failed to record placement history: %v", err)
+		}
+	}
+
+	// Start the shared informers that back every read endpoint
+	ocmClient.StartInformers(ctx)
+
 	// Set up and run the server
 	r := server.SetupServer(ocmClient, ctx, debugMode)
 	server.RunServer(r)
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultDecisionTTL is how long a SubjectAccessReview decision is reused
+	DefaultDecisionTTL = 30 * time.Second
+
+	// maxCachedDecisions bounds the decision cache, the least recently used
+	// decision is evicted when it is full
+	maxCachedDecisions = 10000
+)
+
+// decision is a cached SubjectAccessReview result
+type decision struct {
+	key     string
+	allowed bool
+	expires time.Time
+}
+
+// Authorizer checks a user's Kubernetes RBAC with SubjectAccessReview. The
+// dashboard reads from informer caches populated under its own ServiceAccount,
+// so every object has to be checked against the caller before it is returned.
+type Authorizer struct {
+	client     kubernetes.Interface
+	ttl        time.Duration
+	now        func() time.Time
+	maxEntries int
+
+	mu        sync.Mutex
+	decisions map[string]*list.Element
+	lru       *list.List
+}
+
+// NewAuthorizer creates an Authorizer that caches decisions for ttl
+func NewAuthorizer(client kubernetes.Interface, ttl time.Duration) *Authorizer {
+	return &Authorizer{
+		client:     client,
+		ttl:        ttl,
+		now:        time.Now,
+		maxEntries: maxCachedDecisions,
+		decisions:  make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// TTL returns how long a decision is reused
+func (a *Authorizer) TTL() time.Duration {
+	return a.ttl
+}
+
+// Allowed reports whether the user may perform the action described by attrs
+func (a *Authorizer) Allowed(ctx context.Context, user *authv1.UserInfo, attrs authorizationv1.ResourceAttributes) (bool, error) {
+	key := decisionKey(user, attrs)
+
+	if cached, ok := a.lookup(key); ok {
+		return cached.allowed, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:               user.Username,
+			UID:                user.UID,
+			Groups:             user.Groups,
+			Extra:              extra,
+			ResourceAttributes: &attrs,
+		},
+	}
+
+	result, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	a.store(key, result.Status.Allowed)
+	return result.Status.Allowed, nil
+}
+
+// lookup returns an unexpired decision and marks it as recently used
+func (a *Authorizer) lookup(key string) (*decision, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	element, ok := a.decisions[key]
+	if !ok {
+		return nil, false
+	}
+
+	cached := element.Value.(*decision)
+	if !a.now().Before(cached.expires) {
+		a.lru.Remove(element)
+		delete(a.decisions, key)
+		return nil, false
+	}
+
+	a.lru.MoveToFront(element)
+	return cached, true
+}
+
+// store caches a decision, evicting the least recently used one when full
+func (a *Authorizer) store(key string, allowed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry := &decision{key: key, allowed: allowed, expires: a.now().Add(a.ttl)}
+	if element, ok := a.decisions[key]; ok {
+		element.Value = entry
+		a.lru.MoveToFront(element)
+		return
+	}
+
+	for a.lru.Len() >= a.maxEntries {
+		oldest := a.lru.Back()
+		a.lru.Remove(oldest)
+		delete(a.decisions, oldest.Value.(*decision).key)
+	}
+
+	a.decisions[key] = a.lru.PushFront(entry)
+}
+
+// decisionKey identifies a user and set of attributes in the decision cache
+func decisionKey(user *authv1.UserInfo, attrs authorizationv1.ResourceAttributes) string {
+	groups := append([]string(nil), user.Groups...)
+	sort.Strings(groups)
+
+	extra := make([]string, 0, len(user.Extra))
+	for k, v := range user.Extra {
+		extra = append(extra, k+"="+strings.Join(v, ","))
+	}
+	sort.Strings(extra)
+
+	h := sha256.New()
+	for _, part := range []string{
+		user.Username, user.UID, strings.Join(groups, ","), strings.Join(extra, ";"),
+		attrs.Verb, attrs.Group, attrs.Resource, attrs.Subresource, attrs.Namespace, attrs.Name,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
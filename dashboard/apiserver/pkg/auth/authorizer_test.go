@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeSARClient returns a clientset that answers SubjectAccessReviews with
+// allow and counts how many reviews were sent
+func newFakeSARClient(allow func(spec authorizationv1.SubjectAccessReviewSpec) (bool, error), calls *int) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		allowed, err := allow(review.Spec)
+		if err != nil {
+			return true, nil, err
+		}
+		review.Status.Allowed = allowed
+		return true, review, nil
+	})
+	return client
+}
+
+func TestAuthorizerAllowed(t *testing.T) {
+	user := &authv1.UserInfo{Username: "alice", Groups: []string{"tenant-a"}}
+
+	tests := []struct {
+		name     string
+		attrs    authorizationv1.ResourceAttributes
+		expected bool
+	}{
+		{
+			name:     "allowed in bound namespace",
+			attrs:    authorizationv1.ResourceAttributes{Verb: "list", Group: "work.open-cluster-management.io", Resource: "manifestworks", Namespace: "cluster1"},
+			expected: true,
+		},
+		{
+			name:     "denied in other namespace",
+			attrs:    authorizationv1.ResourceAttributes{Verb: "list", Group: "work.open-cluster-management.io", Resource: "manifestworks", Namespace: "cluster2"},
+			expected: false,
+		},
+	}
+
+	calls := 0
+	client := newFakeSARClient(func(spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+		assert.Equal(t, "alice", spec.User)
+		assert.Equal(t, []string{"tenant-a"}, spec.Groups)
+		return spec.ResourceAttributes.Namespace == "cluster1", nil
+	}, &calls)
+	authorizer := NewAuthorizer(client, time.Minute)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := authorizer.Allowed(context.Background(), user, tt.attrs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+func TestAuthorizerCachesDecisions(t *testing.T) {
+	user := &authv1.UserInfo{Username: "alice"}
+	attrs := authorizationv1.ResourceAttributes{Verb: "get", Group: "cluster.open-cluster-management.io", Resource: "managedclustersets", Name: "dev"}
+
+	calls := 0
+	client := newFakeSARClient(func(spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+		return true, nil
+	}, &calls)
+
+	now := time.Now()
+	authorizer := NewAuthorizer(client, time.Minute)
+	authorizer.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		allowed, err := authorizer.Allowed(context.Background(), user, attrs)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	assert.Equal(t, 1, calls)
+
+	// A different user is reviewed separately
+	_, err := authorizer.Allowed(context.Background(), &authv1.UserInfo{Username: "bob"}, attrs)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Decisions are reviewed again once they expire
+	now = now.Add(2 * time.Minute)
+	_, err = authorizer.Allowed(context.Background(), user, attrs)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestAuthorizerDoesNotCacheErrors(t *testing.T) {
+	user := &authv1.UserInfo{Username: "alice"}
+	attrs := authorizationv1.ResourceAttributes{Verb: "list", Resource: "placements"}
+
+	calls := 0
+	client := newFakeSARClient(func(spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+		return false, errors.New("apiserver unavailable")
+	}, &calls)
+	authorizer := NewAuthorizer(client, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		allowed, err := authorizer.Allowed(context.Background(), user, attrs)
+		assert.Error(t, err)
+		assert.False(t, allowed)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestAuthorizerEvictsLeastRecentlyUsed(t *testing.T) {
+	user := &authv1.UserInfo{Username: "alice"}
+	attrs := func(name string) authorizationv1.ResourceAttributes {
+		return authorizationv1.ResourceAttributes{Verb: "get", Group: "cluster.open-cluster-management.io", Resource: "managedclusters", Name: name}
+	}
+
+	calls := 0
+	client := newFakeSARClient(func(spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+		return true, nil
+	}, &calls)
+	authorizer := NewAuthorizer(client, time.Minute)
+	authorizer.maxEntries = 2
+
+	for _, name := range []string{"cluster1", "cluster2", "cluster1", "cluster3"} {
+		_, err := authorizer.Allowed(context.Background(), user, attrs(name))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, authorizer.lru.Len())
+
+	// cluster2 was the least recently used and is the only one reviewed again
+	for _, name := range []string{"cluster1", "cluster3", "cluster2"} {
+		_, err := authorizer.Allowed(context.Background(), user, attrs(name))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, calls)
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
+)
+
+const (
+	// DefaultTokenTTL is how long an authenticated token is trusted without a new TokenReview
+	DefaultTokenTTL = 2 * time.Minute
+
+	// DefaultNegativeTokenTTL is how long a rejected token is remembered
+	DefaultNegativeTokenTTL = 10 * time.Second
+
+	// DefaultTokenCacheSize bounds the number of cached tokens
+	DefaultTokenCacheSize = 4096
+)
+
+// TokenCacheOptions configures the TokenReview cache
+type TokenCacheOptions struct {
+	// TTL is how long an authenticated token is cached; zero disables caching
+	TTL time.Duration
+	// NegativeTTL is how long a rejected token is cached; zero disables negative caching
+	NegativeTTL time.Duration
+	// MaxEntries bounds the cache, the least recently used token is evicted first
+	MaxEntries int
+}
+
+// TokenCacheOptionsFromEnv reads the cache options from DASHBOARD_TOKEN_CACHE_TTL,
+// DASHBOARD_TOKEN_CACHE_NEGATIVE_TTL and DASHBOARD_TOKEN_CACHE_SIZE, falling
+// back to the defaults for unset or invalid values
+func TokenCacheOptionsFromEnv() TokenCacheOptions {
+	opts := TokenCacheOptions{
+		TTL:         DefaultTokenTTL,
+		NegativeTTL: DefaultNegativeTokenTTL,
+		MaxEntries:  DefaultTokenCacheSize,
+	}
+
+	if value := os.Getenv("DASHBOARD_TOKEN_CACHE_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil && ttl >= 0 {
+			opts.TTL = ttl
+		} else {
+			log.Printf("Ignoring invalid DASHBOARD_TOKEN_CACHE_TTL %q", value)
+		}
+	}
+	if value := os.Getenv("DASHBOARD_TOKEN_CACHE_NEGATIVE_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil && ttl >= 0 {
+			opts.NegativeTTL = ttl
+		} else {
+			log.Printf("Ignoring invalid DASHBOARD_TOKEN_CACHE_NEGATIVE_TTL %q", value)
+		}
+	}
+	if value := os.Getenv("DASHBOARD_TOKEN_CACHE_SIZE"); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			opts.MaxEntries = size
+		} else {
+			log.Printf("Ignoring invalid DASHBOARD_TOKEN_CACHE_SIZE %q", value)
+		}
+	}
+
+	return opts
+}
+
+// tokenEntry is a cached TokenReview result
+type tokenEntry struct {
+	key           string
+	user          *authv1.UserInfo
+	authenticated bool
+	expires       time.Time
+}
+
+// TokenAuthenticator validates bearer tokens with TokenReview and caches the
+// results. Tokens are only kept as a SHA-256 hash.
+type TokenAuthenticator struct {
+	client kubernetes.Interface
+	opts   TokenCacheOptions
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewTokenAuthenticator creates a TokenAuthenticator backed by the given client
+func NewTokenAuthenticator(client kubernetes.Interface, opts TokenCacheOptions) *TokenAuthenticator {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultTokenCacheSize
+	}
+	return &TokenAuthenticator{
+		client:  client,
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Authenticate returns the user for a token and whether the token is
+// authenticated. Errors talking to the apiserver are returned and not cached.
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, token string) (*authv1.UserInfo, bool, error) {
+	key := hashToken(token)
+
+	if entry, ok := a.lookup(key); ok {
+		a.hits.Add(1)
+		return entry.user, entry.authenticated, nil
+	}
+	a.misses.Add(1)
+
+	tokenReview := &authv1.TokenReview{
+		Spec: authv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+
+	start := time.Now()
+	result, err := a.client.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
+	metrics.TokenReviewDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.TokenReviewFailures.WithLabelValues("error").Inc()
+		return nil, false, err
+	}
+
+	if !result.Status.Authenticated {
+		metrics.TokenReviewFailures.WithLabelValues("rejected").Inc()
+		log.Printf("Token not authenticated: %s", result.Status.Error)
+		a.store(key, nil, false, a.opts.NegativeTTL)
+		return nil, false, nil
+	}
+
+	user := result.Status.User.DeepCopy()
+	a.store(key, user, true, a.opts.TTL)
+	return user, true, nil
+}
+
+// Stats returns the cache hit and miss counters and the current size. It is
+// exported through metrics.TrackTokenCache.
+func (a *TokenAuthenticator) Stats() metrics.TokenCacheStats {
+	a.mu.Lock()
+	entries := a.lru.Len()
+	a.mu.Unlock()
+
+	return metrics.TokenCacheStats{
+		Hits:    a.hits.Load(),
+		Misses:  a.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// lookup returns an unexpired cache entry and marks it as recently used
+func (a *TokenAuthenticator) lookup(key string) (*tokenEntry, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	element, ok := a.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*tokenEntry)
+	if !a.now().Before(entry.expires) {
+		a.lru.Remove(element)
+		delete(a.entries, key)
+		return nil, false
+	}
+
+	a.lru.MoveToFront(element)
+	return entry, true
+}
+
+// store caches a result for ttl, evicting the least recently used entry when full
+func (a *TokenAuthenticator) store(key string, user *authv1.UserInfo, authenticated bool, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry := &tokenEntry{key: key, user: user, authenticated: authenticated, expires: a.now().Add(ttl)}
+	if element, ok := a.entries[key]; ok {
+		element.Value = entry
+		a.lru.MoveToFront(element)
+		return
+	}
+
+	for a.lru.Len() >= a.opts.MaxEntries {
+		oldest := a.lru.Back()
+		a.lru.Remove(oldest)
+		delete(a.entries, oldest.Value.(*tokenEntry).key)
+	}
+
+	a.entries[key] = a.lru.PushFront(entry)
+}
+
+// hashToken returns the cache key for a token so raw tokens are never kept in memory
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
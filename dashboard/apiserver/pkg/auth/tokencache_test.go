@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
+)
+
+// newFakeTokenReviewClient returns a clientset that authenticates "good-*"
+// tokens as a user of the same name and counts the TokenReviews it receives
+func newFakeTokenReviewClient(calls *int, failWith error) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		if failWith != nil {
+			return true, nil, failWith
+		}
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.TokenReview)
+		if len(review.Spec.Token) > 5 && review.Spec.Token[:5] == "good-" {
+			review.Status.Authenticated = true
+			review.Status.User = authv1.UserInfo{Username: review.Spec.Token[5:], Groups: []string{"system:authenticated"}}
+		} else {
+			review.Status.Error = "invalid token"
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestTokenAuthenticatorAuthenticate(t *testing.T) {
+	tests := []struct {
+		name                  string
+		token                 string
+		expectedAuthenticated bool
+		expectedUser          string
+	}{
+		{
+			name:                  "valid token",
+			token:                 "good-alice",
+			expectedAuthenticated: true,
+			expectedUser:          "alice",
+		},
+		{
+			name:                  "rejected token",
+			token:                 "bad-token",
+			expectedAuthenticated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, nil), TokenCacheOptions{
+				TTL:         time.Minute,
+				NegativeTTL: 10 * time.Second,
+				MaxEntries:  10,
+			})
+
+			for i := 0; i < 3; i++ {
+				user, authenticated, err := authenticator.Authenticate(context.Background(), tt.token)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedAuthenticated, authenticated)
+				if tt.expectedAuthenticated {
+					require.NotNil(t, user)
+					assert.Equal(t, tt.expectedUser, user.Username)
+				} else {
+					assert.Nil(t, user)
+				}
+			}
+
+			// Both accepted and rejected tokens are served from the cache
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, metrics.TokenCacheStats{Hits: 2, Misses: 1, Entries: 1}, authenticator.Stats())
+		})
+	}
+}
+
+func TestTokenAuthenticatorExpiry(t *testing.T) {
+	calls := 0
+	authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, nil), TokenCacheOptions{
+		TTL:         time.Minute,
+		NegativeTTL: 5 * time.Second,
+		MaxEntries:  10,
+	})
+	now := time.Now()
+	authenticator.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	_, _, err := authenticator.Authenticate(ctx, "good-alice")
+	require.NoError(t, err)
+	_, _, err = authenticator.Authenticate(ctx, "bad-token")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// The negative entry expires well before the positive one
+	now = now.Add(10 * time.Second)
+	_, _, err = authenticator.Authenticate(ctx, "good-alice")
+	require.NoError(t, err)
+	_, _, err = authenticator.Authenticate(ctx, "bad-token")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	now = now.Add(time.Minute)
+	_, _, err = authenticator.Authenticate(ctx, "good-alice")
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestTokenAuthenticatorEvictsLeastRecentlyUsed(t *testing.T) {
+	calls := 0
+	authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, nil), TokenCacheOptions{
+		TTL:        time.Minute,
+		MaxEntries: 2,
+	})
+
+	ctx := context.Background()
+	for _, token := range []string{"good-a", "good-b", "good-a", "good-c"} {
+		_, _, err := authenticator.Authenticate(ctx, token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, authenticator.Stats().Entries)
+
+	// good-b was the least recently used and has been evicted
+	_, _, err := authenticator.Authenticate(ctx, "good-a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	_, _, err = authenticator.Authenticate(ctx, "good-b")
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestTokenAuthenticatorDoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, errors.New("apiserver unavailable")), TokenCacheOptions{
+		TTL:         time.Minute,
+		NegativeTTL: time.Minute,
+		MaxEntries:  10,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, authenticated, err := authenticator.Authenticate(context.Background(), "good-alice")
+		assert.Error(t, err)
+		assert.False(t, authenticated)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 0, authenticator.Stats().Entries)
+}
+
+func TestTokenAuthenticatorRecordsMetrics(t *testing.T) {
+	calls := 0
+	authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, nil), TokenCacheOptions{
+		TTL:         time.Minute,
+		NegativeTTL: time.Minute,
+		MaxEntries:  10,
+	})
+	erroring := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, errors.New("apiserver unavailable")), TokenCacheOptions{})
+
+	rejected := metrics.TokenReviewFailures.WithLabelValues("rejected")
+	failed := metrics.TokenReviewFailures.WithLabelValues("error")
+	rejectedBefore, failedBefore := testutil.ToFloat64(rejected), testutil.ToFloat64(failed)
+
+	// Cached results do not send a TokenReview and are not counted again
+	for i := 0; i < 2; i++ {
+		authenticator.Authenticate(context.Background(), "good-alice")
+		authenticator.Authenticate(context.Background(), "bad-token")
+	}
+	erroring.Authenticate(context.Background(), "good-alice")
+
+	assert.Equal(t, rejectedBefore+1, testutil.ToFloat64(rejected))
+	assert.Equal(t, failedBefore+1, testutil.ToFloat64(failed))
+}
+
+func TestTokenAuthenticatorDoesNotKeepRawTokens(t *testing.T) {
+	calls := 0
+	authenticator := NewTokenAuthenticator(newFakeTokenReviewClient(&calls, nil), TokenCacheOptions{TTL: time.Minute})
+
+	_, _, err := authenticator.Authenticate(context.Background(), "good-alice")
+	require.NoError(t, err)
+
+	for key := range authenticator.entries {
+		assert.NotContains(t, key, "good-alice")
+		assert.Equal(t, hashToken("good-alice"), key)
+	}
+}
+
+func TestTokenCacheOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected TokenCacheOptions
+	}{
+		{
+			name:     "defaults",
+			env:      map[string]string{},
+			expected: TokenCacheOptions{TTL: DefaultTokenTTL, NegativeTTL: DefaultNegativeTokenTTL, MaxEntries: DefaultTokenCacheSize},
+		},
+		{
+			name: "overrides",
+			env: map[string]string{
+				"DASHBOARD_TOKEN_CACHE_TTL":          "5m",
+				"DASHBOARD_TOKEN_CACHE_NEGATIVE_TTL": "0s",
+				"DASHBOARD_TOKEN_CACHE_SIZE":         "100",
+			},
+			expected: TokenCacheOptions{TTL: 5 * time.Minute, NegativeTTL: 0, MaxEntries: 100},
+		},
+		{
+			name: "invalid values fall back to defaults",
+			env: map[string]string{
+				"DASHBOARD_TOKEN_CACHE_TTL":  "soon",
+				"DASHBOARD_TOKEN_CACHE_SIZE": "-1",
+			},
+			expected: TokenCacheOptions{TTL: DefaultTokenTTL, NegativeTTL: DefaultNegativeTokenTTL, MaxEntries: DefaultTokenCacheSize},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"DASHBOARD_TOKEN_CACHE_TTL", "DASHBOARD_TOKEN_CACHE_NEGATIVE_TTL", "DASHBOARD_TOKEN_CACHE_SIZE"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			assert.Equal(t, tt.expected, TokenCacheOptionsFromEnv())
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authentication/v1"
+)
+
+// userContextKey is the gin context key holding the authenticated user
+const userContextKey = "ocm-dashboard/user"
+
+// SetUser records the user authenticated for the current request
+func SetUser(c *gin.Context, user *authv1.UserInfo) {
+	c.Set(userContextKey, user)
+}
+
+// UserFromContext returns the user authenticated for the current request, or
+// nil when authentication is bypassed
+func UserFromContext(c *gin.Context) *authv1.UserInfo {
+	value, ok := c.Get(userContextKey)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*authv1.UserInfo)
+	return user
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"k8s.io/client-go/tools/cache"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+// PlacementDecisionsByCluster indexes PlacementDecisions by the names of the
+// clusters they select
+const PlacementDecisionsByCluster = "placementDecisionsByCluster"
+
+// AddIndexers registers the indexers the handlers use to look up cached
+// objects. It must only be called once per informer factory.
+func (c *OCMClient) AddIndexers() error {
+	return c.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer().AddIndexers(cache.Indexers{
+		PlacementDecisionsByCluster: indexPlacementDecisionByCluster,
+	})
+}
+
+func indexPlacementDecisionByCluster(obj interface{}) ([]string, error) {
+	decision, ok := obj.(*clusterv1beta1.PlacementDecision)
+	if !ok {
+		return nil, nil
+	}
+	clusters := make([]string, 0, len(decision.Status.Decisions))
+	for _, d := range decision.Status.Decisions {
+		clusters = append(clusters, d.ClusterName)
+	}
+	return clusters, nil
+}
@@ -1,17 +1,35 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/transport"
 	addonv1alpha1client "open-cluster-management.io/api/client/addon/clientset/versioned"
 	addonv1alpha1informers "open-cluster-management.io/api/client/addon/informers/externalversions"
 	clusterv1client "open-cluster-management.io/api/client/cluster/clientset/versioned"
 	clusterv1informers "open-cluster-management.io/api/client/cluster/informers/externalversions"
 	workv1client "open-cluster-management.io/api/client/work/clientset/versioned"
 	workv1informers "open-cluster-management.io/api/client/work/informers/externalversions"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/history"
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
 )
 
 // OCMClient holds clients for OCM resources
@@ -31,10 +49,44 @@ type OCMClient struct {
 	ClusterInformerFactory clusterv1informers.SharedInformerFactory
 	AddonInformerFactory   addonv1alpha1informers.SharedInformerFactory
 	WorkInformerFactory    workv1informers.SharedInformerFactory
+
+	// KubeInformerFactory caches the Kubernetes resources the dashboard shows,
+	// currently the CSRs created by klusterlets to join the hub
+	KubeInformerFactory informers.SharedInformerFactory
+
+	// TokenAuthenticator validates bearer tokens with a cached TokenReview
+	TokenAuthenticator *auth.TokenAuthenticator
+
+	// Authorizer checks the caller's RBAC before cached objects are returned
+	Authorizer *auth.Authorizer
+
+	// RestConfig is the dashboard's own config, used to build impersonating clients
+	RestConfig *rest.Config
+
+	// ImpersonatedGroups limits the groups forwarded when impersonating, nil
+	// forwards every group of the caller
+	ImpersonatedGroups []string
+
+	// impersonationTransport is built from RestConfig once and shared by the
+	// impersonating clients
+	impersonationOnce      sync.Once
+	impersonationTransport http.RoundTripper
+	impersonationErr       error
+
+	// PlacementHistory records changes to placement decisions, nil when disabled
+	PlacementHistory *history.Store
+
+	// informersSynced is set once every informer cache has completed its initial list
+	informersSynced atomic.Bool
 }
 
-// CreateOCMClient initializes OCM clients using the provided config
+// CreateOCMClient initializes OCM clients using the provided config. Failed
+// requests to the API server are counted in the kube client error metric,
+// including those of the impersonating clients built from the config.
 func CreateOCMClient(config *rest.Config) (*OCMClient, error) {
+	config = rest.CopyConfig(config)
+	config.Wrap(metrics.WrapTransport)
+
 	// Create dynamic client (for backward compatibility)
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
@@ -69,9 +121,13 @@ func CreateOCMClient(config *rest.Config) (*OCMClient, error) {
 	clusterInformerFactory := clusterv1informers.NewSharedInformerFactory(clusterClient, 0)
 	addonInformerFactory := addonv1alpha1informers.NewSharedInformerFactory(addonClient, 0)
 	workInformerFactory := workv1informers.NewSharedInformerFactory(workClient, 0)
+	kubeInformerFactory := NewKubeInformerFactory(kubernetesClient)
 
 	log.Println("Successfully created OCM clients")
 
+	tokenAuthenticator := auth.NewTokenAuthenticator(kubernetesClient, auth.TokenCacheOptionsFromEnv())
+	metrics.TrackTokenCache(tokenAuthenticator.Stats)
+
 	return &OCMClient{
 		Interface:              dynamicClient,
 		KubernetesClient:       kubernetesClient,
@@ -81,5 +137,186 @@ func CreateOCMClient(config *rest.Config) (*OCMClient, error) {
 		ClusterInformerFactory: clusterInformerFactory,
 		AddonInformerFactory:   addonInformerFactory,
 		WorkInformerFactory:    workInformerFactory,
+		KubeInformerFactory:    kubeInformerFactory,
+		TokenAuthenticator:     tokenAuthenticator,
+		Authorizer:             auth.NewAuthorizer(kubernetesClient, auth.DefaultDecisionTTL),
+		RestConfig:             config,
+		ImpersonatedGroups:     ImpersonatedGroupsFromEnv(),
+	}, nil
+}
+
+// impersonatedExtras are the user extras forwarded when impersonating, the
+// chart only grants impersonation of these. Other extras are dropped rather
+// than failing every write of users that carry them.
+var impersonatedExtras = []string{
+	"scopes",
+	"authentication.kubernetes.io/credential-id",
+}
+
+// ImpersonatedGroupsFromEnv reads the groups the dashboard may impersonate from
+// DASHBOARD_IMPERSONATE_GROUPS, a comma separated list. Nil means every group.
+func ImpersonatedGroupsFromEnv() []string {
+	value := os.Getenv("DASHBOARD_IMPERSONATE_GROUPS")
+	if value == "" {
+		return nil
+	}
+	groups := []string{}
+	for _, group := range strings.Split(value, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// ForUser returns clients that impersonate the given user, so that writes are
+// authorized by the Kubernetes apiserver against the caller's own RBAC. The
+// returned client has no informers. When authentication is bypassed (nil user)
+// the dashboard's own clients are returned.
+//
+// The groups come from the TokenReview of the caller, so a member of
+// system:masters is impersonated as such. When ImpersonatedGroups is set only
+// those groups are forwarded, matching a chart that limits the groups the
+// dashboard is allowed to impersonate.
+func (c *OCMClient) ForUser(user *authv1.UserInfo) (*OCMClient, error) {
+	if user == nil {
+		return c, nil
+	}
+	if c.RestConfig == nil {
+		return nil, errors.New("impersonation requires a rest config")
+	}
+
+	// The TLS and metrics transport is shared by every caller, only the
+	// impersonation headers differ
+	c.impersonationOnce.Do(func() {
+		c.impersonationTransport, c.impersonationErr = rest.TransportFor(c.RestConfig)
+	})
+	if c.impersonationErr != nil {
+		return nil, c.impersonationErr
+	}
+
+	impersonate := transport.ImpersonationConfig{
+		UserName: user.Username,
+		UID:      user.UID,
+		Extra:    map[string][]string{},
+	}
+	for _, group := range user.Groups {
+		if c.ImpersonatedGroups == nil || slices.Contains(c.ImpersonatedGroups, group) {
+			impersonate.Groups = append(impersonate.Groups, group)
+		}
+	}
+	for _, key := range impersonatedExtras {
+		if values, ok := user.Extra[key]; ok {
+			impersonate.Extra[key] = values
+		}
+	}
+	httpClient := &http.Client{
+		Transport: transport.NewImpersonatingRoundTripper(impersonate, c.impersonationTransport),
+		Timeout:   c.RestConfig.Timeout,
+	}
+
+	dynamicClient, err := dynamic.NewForConfigAndClient(c.RestConfig, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	kubernetesClient, err := kubernetes.NewForConfigAndClient(c.RestConfig, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	clusterClient, err := clusterv1client.NewForConfigAndClient(c.RestConfig, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	addonClient, err := addonv1alpha1client.NewForConfigAndClient(c.RestConfig, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	workClient, err := workv1client.NewForConfigAndClient(c.RestConfig, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OCMClient{
+		Interface:        dynamicClient,
+		KubernetesClient: kubernetesClient,
+		ClusterClient:    clusterClient,
+		AddonClient:      addonClient,
+		WorkClient:       workClient,
 	}, nil
 }
+
+// NewKubeInformerFactory creates the Kubernetes informer factory. Only CSRs
+// labelled with a cluster name are cached, other CSRs are not relevant to OCM.
+func NewKubeInformerFactory(kubernetesClient kubernetes.Interface) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(kubernetesClient, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = clusterv1.ClusterNameLabelKey
+		}),
+	)
+}
+
+// StartInformers registers the informers that back the API handlers, starts the
+// informer factories and waits in the background for their caches to sync.
+// InformersSynced reports true once the initial sync has completed.
+func (c *OCMClient) StartInformers(ctx context.Context) {
+	if err := c.AddIndexers(); err != nil {
+		log.Printf("Failed to add informer indexers: %v", err)
+	}
+
+	// Informers have to be requested before the factories are started,
+	// otherwise Start has nothing to run. Each one reports its sync state
+	// in the informer metric under its resource name.
+	track := func(resource string, informer cache.SharedIndexInformer) cache.InformerSynced {
+		metrics.TrackInformer(resource, informer.HasSynced)
+		return informer.HasSynced
+	}
+	synced := []cache.InformerSynced{
+		track("managedclusters", c.ClusterInformerFactory.Cluster().V1().ManagedClusters().Informer()),
+		track("managedclustersets", c.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Informer()),
+		track("managedclustersetbindings", c.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Informer()),
+		track("placements", c.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer()),
+		track("placementdecisions", c.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer()),
+		track("addonplacementscores", c.ClusterInformerFactory.Cluster().V1alpha1().AddOnPlacementScores().Informer()),
+		track("managedclusteraddons", c.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Informer()),
+		track("clustermanagementaddons", c.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Informer()),
+		track("addondeploymentconfigs", c.AddonInformerFactory.Addon().V1alpha1().AddOnDeploymentConfigs().Informer()),
+		track("manifestworks", c.WorkInformerFactory.Work().V1().ManifestWorks().Informer()),
+	}
+
+	if c.KubeInformerFactory != nil {
+		synced = append(synced, track("certificatesigningrequests", c.KubeInformerFactory.Certificates().V1().CertificateSigningRequests().Informer()))
+	}
+
+	// ManifestWorkReplicaSets are behind a feature gate on the hub, so the
+	// informer is started but readiness does not wait for it
+	track("manifestworkreplicasets", c.WorkInformerFactory.Work().V1alpha1().ManifestWorkReplicaSets().Informer())
+
+	// The fleet gauges are computed from the caches when scraped
+	metrics.WatchFleet(metrics.FleetListers{
+		Clusters:   c.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister(),
+		Placements: c.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister(),
+		Addons:     c.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister(),
+	})
+
+	c.ClusterInformerFactory.Start(ctx.Done())
+	c.AddonInformerFactory.Start(ctx.Done())
+	c.WorkInformerFactory.Start(ctx.Done())
+	if c.KubeInformerFactory != nil {
+		c.KubeInformerFactory.Start(ctx.Done())
+	}
+
+	go func() {
+		log.Println("Waiting for informer caches to sync")
+		if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+			log.Println("Informer caches failed to sync")
+			return
+		}
+		c.informersSynced.Store(true)
+		log.Println("Informer caches synced")
+	}()
+}
+
+// InformersSynced reports whether all informer caches have completed their initial sync
+func (c *OCMClient) InformersSynced() bool {
+	return c.informersSynced.Load()
+}
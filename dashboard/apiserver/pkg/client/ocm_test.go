@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestForUser(t *testing.T) {
+	user := &authv1.UserInfo{
+		Username: "alice",
+		UID:      "1234",
+		Groups:   []string{"system:masters", "developers", "system:authenticated"},
+		Extra: map[string]authv1.ExtraValue{
+			"scopes":                 {"user:full"},
+			"example.com/department": {"platform"},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		impersonatedGroups []string
+		expectedGroups     []string
+	}{
+		{
+			name:           "every group",
+			expectedGroups: []string{"system:masters", "developers", "system:authenticated"},
+		},
+		{
+			name:               "limited groups",
+			impersonatedGroups: []string{"developers", "system:authenticated"},
+			expectedGroups:     []string{"developers", "system:authenticated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"apiVersion":"cluster.open-cluster-management.io/v1","kind":"ManagedCluster","metadata":{"name":"cluster1"}}`))
+			}))
+			defer server.Close()
+
+			ocmClient := &OCMClient{RestConfig: &rest.Config{Host: server.URL}, ImpersonatedGroups: tt.impersonatedGroups}
+			userClients, err := ocmClient.ForUser(user)
+			require.NoError(t, err)
+
+			_, err = userClients.ClusterClient.ClusterV1().ManagedClusters().Get(context.Background(), "cluster1", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, "alice", headers.Get("Impersonate-User"))
+			assert.Equal(t, "1234", headers.Get("Impersonate-Uid"))
+			assert.Equal(t, tt.expectedGroups, headers.Values("Impersonate-Group"))
+			// Only the extras the chart grants are forwarded
+			extras := map[string]string{}
+			for key := range headers {
+				if strings.HasPrefix(key, "Impersonate-Extra-") {
+					extras[key] = headers.Get(key)
+				}
+			}
+			assert.Equal(t, map[string]string{"Impersonate-Extra-Scopes": "user:full"}, extras)
+
+			// The transport is built once and shared by every caller
+			shared := ocmClient.impersonationTransport
+			_, err = ocmClient.ForUser(&authv1.UserInfo{Username: "bob"})
+			require.NoError(t, err)
+			assert.Same(t, shared, ocmClient.impersonationTransport)
+		})
+	}
+}
+
+func TestForUserWithoutUser(t *testing.T) {
+	ocmClient := &OCMClient{}
+	userClients, err := ocmClient.ForUser(nil)
+	require.NoError(t, err)
+	assert.Same(t, ocmClient, userClients)
+
+	_, err = ocmClient.ForUser(&authv1.UserInfo{Username: "alice"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// tenantAuthorizer allows listing manifestworks in the cluster1 namespace and
+// getting the dev cluster set, and denies everything else
+func tenantAuthorizer() *auth.Authorizer {
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		switch {
+		case attrs.Resource == manifestWorksResource && attrs.Verb == "list" && attrs.Namespace == "cluster1":
+			review.Status.Allowed = true
+		case attrs.Resource == managedClusterSetsResource && attrs.Verb == "get" && attrs.Name == "dev":
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+	return auth.NewAuthorizer(kubeClient, time.Minute)
+}
+
+func TestGetManifestWorksFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-a", Namespace: "cluster1"}},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-b", Namespace: "cluster2"}},
+	)
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	tests := []struct {
+		name           string
+		namespace      string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{
+			name:           "bound namespace",
+			namespace:      "cluster1",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"work-a"},
+		},
+		{
+			name:           "other namespace",
+			namespace:      "cluster2",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "namespace", Value: tt.namespace}}
+			auth.SetUser(c, &authv1.UserInfo{Username: "tenant"})
+
+			GetManifestWorks(c, ocmClient, context.Background())
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var works []models.ManifestWork
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &works))
+			names := []string{}
+			for _, work := range works {
+				names = append(names, work.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestGetClusterSetsFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+	)
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	auth.SetUser(c, &authv1.UserInfo{Username: "tenant"})
+
+	GetClusterSets(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var clusterSets []models.ClusterSet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusterSets))
+	assert.Len(t, clusterSets, 1)
+	assert.Equal(t, "dev", clusterSets[0].Name)
+
+	// Getting a set the caller is not bound to is forbidden
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "name", Value: "prod"}}
+	auth.SetUser(c, &authv1.UserInfo{Username: "tenant"})
+
+	GetClusterSet(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAccessCheckerWithoutAuthorizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t)
+
+	tests := []struct {
+		name     string
+		user     *authv1.UserInfo
+		expected bool
+	}{
+		{
+			name:     "authentication bypassed",
+			user:     nil,
+			expected: true,
+		},
+		{
+			name:     "authenticated user fails closed",
+			user:     &authv1.UserInfo{Username: "tenant"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.user != nil {
+				auth.SetUser(c, tt.user)
+			}
+
+			access := newAccessChecker(c, ocmClient, context.Background(), clusterGroup, managedClustersResource)
+			assert.Equal(t, tt.expected, access.allowed("", "cluster1"))
+		})
+	}
+}
+
+func TestAccessCheckerDecidesListRightsOncePerNamespace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var reviews []authorizationv1.ResourceAttributes
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := *review.Spec.ResourceAttributes
+		reviews = append(reviews, attrs)
+		review.Status.Allowed = (attrs.Verb == "list" && attrs.Namespace == "cluster1") || (attrs.Verb == "get" && attrs.Name == "work-shared")
+		return true, review, nil
+	})
+	ocmClient := newFakeOCMClient(t)
+	ocmClient.Authorizer = auth.NewAuthorizer(kubeClient, time.Minute)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	auth.SetUser(c, &authv1.UserInfo{Username: "tenant"})
+	access := newAccessChecker(c, ocmClient, context.Background(), workGroup, manifestWorksResource)
+
+	// Listable namespace: no per-object reviews
+	for _, name := range []string{"work-a", "work-b", "work-c"} {
+		assert.True(t, access.allowed("cluster1", name))
+	}
+	assert.Len(t, reviews, 2)
+
+	// Other namespace: the list right is reviewed once, then each object
+	assert.False(t, access.allowed("cluster2", "work-a"))
+	assert.True(t, access.allowed("cluster2", "work-shared"))
+	assert.Len(t, reviews, 5)
+	for _, attrs := range reviews[3:] {
+		assert.Equal(t, "get", attrs.Verb)
+	}
+}
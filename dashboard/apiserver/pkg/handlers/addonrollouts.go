@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// Rollout status of an addon on a cluster
+const (
+	addonRolloutToApply     = "ToApply"
+	addonRolloutProgressing = "Progressing"
+	addonRolloutSucceeded   = "Succeeded"
+	addonRolloutFailed      = "Failed"
+	addonRolloutTimeOut     = "TimeOut"
+)
+
+// GetClusterManagementAddOnRollout reports how far the configs of an addon
+// have rolled out to the clusters of each install-strategy placement. The
+// placement's progression comes from the ClusterManagementAddOn status, the
+// status of each cluster from its ManagedClusterAddOn.
+func GetClusterManagementAddOnRollout(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil || ocmClient.ClusterInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
+		return
+	}
+
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, addonGroup, clusterManagementAddOnsResource).allowed("", name) {
+		respondForbidden(c, clusterManagementAddOnsResource, name)
+		return
+	}
+
+	item, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Lister().Get(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("ClusterManagementAddOn %s not found", name)})
+		return
+	}
+
+	// Drop the clusters whose addon the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, addonGroup, managedClusterAddOnsResource)
+
+	rollout := models.AddonRollout{Name: item.Name, Placements: []models.AddonPlacementRollout{}}
+	now := time.Now()
+	for _, strategy := range item.Spec.InstallStrategy.Placements {
+		placement, err := convertAddonPlacementRolloutToModel(ocmClient, access, item, strategy, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		rollout.Placements = append(rollout.Placements, placement)
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+// convertAddonPlacementRolloutToModel reports the rollout to the clusters the
+// placement's decisions select
+func convertAddonPlacementRolloutToModel(ocmClient *client.OCMClient, access accessChecker, item *addonv1alpha1.ClusterManagementAddOn,
+	strategy addonv1alpha1.PlacementStrategy, now time.Time) (models.AddonPlacementRollout, error) {
+	placement := models.AddonPlacementRollout{
+		Namespace:       strategy.Namespace,
+		Name:            strategy.Name,
+		RolloutStrategy: convertRolloutStrategyToModel(strategy.RolloutStrategy),
+		Clusters:        []models.AddonRolloutCluster{},
+	}
+
+	var configs []addonv1alpha1.InstallConfigReference
+	for _, progression := range item.Status.InstallProgressions {
+		if progression.PlacementRef != strategy.PlacementRef {
+			continue
+		}
+		configs = progression.ConfigReferences
+		placement.Conditions = convertConditionsToModel(progression.Conditions)
+		for _, config := range configs {
+			placement.Configs = append(placement.Configs, models.AddonInstallConfig{
+				Group:               config.Group,
+				Resource:            config.Resource,
+				DesiredConfig:       convertConfigSpecHashToModel(config.DesiredConfig),
+				LastKnownGoodConfig: convertConfigSpecHashToModel(config.LastKnownGoodConfig),
+				LastAppliedConfig:   convertConfigSpecHashToModel(config.LastAppliedConfig),
+			})
+		}
+	}
+
+	var deadline time.Duration
+	if raw := placement.RolloutStrategy.ProgressDeadline; raw != "" && raw != "None" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			deadline = d
+		}
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{clusterv1beta1.PlacementLabel: strategy.Name})
+	decisions, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(strategy.Namespace).List(selector)
+	if err != nil {
+		return placement, err
+	}
+
+	addonLister := ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister()
+	for _, decision := range decisions {
+		groupIndex, _ := strconv.Atoi(decision.Labels[clusterv1beta1.DecisionGroupIndexLabel])
+		for _, d := range decision.Status.Decisions {
+			if !access.allowed(d.ClusterName, item.Name) {
+				continue
+			}
+			cluster := models.AddonRolloutCluster{
+				ClusterName:        d.ClusterName,
+				DecisionGroupIndex: groupIndex,
+				DecisionGroupName:  decision.Labels[clusterv1beta1.DecisionGroupNameLabel],
+				Status:             addonRolloutToApply,
+			}
+			if addon, err := addonLister.ManagedClusterAddOns(d.ClusterName).Get(item.Name); err == nil {
+				cluster.Status, cluster.Since = addonRolloutStatus(addon, configs, deadline, now)
+			}
+			placement.Clusters = append(placement.Clusters, cluster)
+		}
+	}
+
+	sort.Slice(placement.Clusters, func(i, j int) bool {
+		a, b := placement.Clusters[i], placement.Clusters[j]
+		if a.DecisionGroupIndex != b.DecisionGroupIndex {
+			return a.DecisionGroupIndex < b.DecisionGroupIndex
+		}
+		return a.ClusterName < b.ClusterName
+	})
+
+	for _, cluster := range placement.Clusters {
+		placement.Summary.Total++
+		switch cluster.Status {
+		case addonRolloutToApply:
+			placement.Summary.ToApply++
+		case addonRolloutProgressing:
+			placement.Summary.Progressing++
+		case addonRolloutSucceeded:
+			placement.Summary.Succeeded++
+		case addonRolloutFailed:
+			placement.Summary.Failed++
+		case addonRolloutTimeOut:
+			placement.Summary.TimeOut++
+		}
+		if cluster.Status != addonRolloutSucceeded && placement.ActiveDecisionGroup == nil {
+			placement.ActiveDecisionGroup = &models.AddonRolloutDecisionGroup{
+				Index: cluster.DecisionGroupIndex,
+				Name:  cluster.DecisionGroupName,
+			}
+		}
+	}
+
+	return placement, nil
+}
+
+// addonRolloutStatus reports the rollout status of the addon on its cluster,
+// and since when the Progressing condition has been in its state. The addon
+// is ToApply until it is given the placement's desired configs, and Succeeded
+// once it applied them. An addon progressing for longer than the deadline,
+// when there is one, has timed out.
+func addonRolloutStatus(addon *addonv1alpha1.ManagedClusterAddOn, configs []addonv1alpha1.InstallConfigReference, deadline time.Duration, now time.Time) (string, string) {
+	progressing := meta.FindStatusCondition(addon.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionProgressing)
+	since := ""
+	if progressing != nil {
+		since = progressing.LastTransitionTime.Format(time.RFC3339)
+	}
+
+	applied := true
+	for _, config := range configs {
+		if config.DesiredConfig == nil {
+			continue
+		}
+		var ref *addonv1alpha1.ConfigReference
+		for i := range addon.Status.ConfigReferences {
+			if addon.Status.ConfigReferences[i].ConfigGroupResource == config.ConfigGroupResource {
+				ref = &addon.Status.ConfigReferences[i]
+				break
+			}
+		}
+		if ref == nil || ref.DesiredConfig == nil || *ref.DesiredConfig != *config.DesiredConfig {
+			return addonRolloutToApply, since
+		}
+		if ref.LastAppliedConfig == nil || *ref.LastAppliedConfig != *config.DesiredConfig {
+			applied = false
+		}
+	}
+
+	switch {
+	case applied:
+		return addonRolloutSucceeded, since
+	case progressing == nil:
+		return addonRolloutProgressing, since
+	case progressing.Reason == addonv1alpha1.ProgressingReasonFailed, progressing.Reason == addonv1alpha1.ProgressingReasonConfigurationUnsupported:
+		return addonRolloutFailed, since
+	case deadline > 0 && now.Sub(progressing.LastTransitionTime.Time) > deadline:
+		return addonRolloutTimeOut, since
+	default:
+		return addonRolloutProgressing, since
+	}
+}
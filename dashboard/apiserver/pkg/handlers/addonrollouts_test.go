@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newRolloutDecision returns a decision of the prod placement in a decision group
+func newRolloutDecision(name, groupIndex, groupName string, clusters ...string) *clusterv1beta1.PlacementDecision {
+	decision := &clusterv1beta1.PlacementDecision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1beta1.PlacementLabel:          "prod",
+				clusterv1beta1.DecisionGroupIndexLabel: groupIndex,
+				clusterv1beta1.DecisionGroupNameLabel:  groupName,
+			},
+		},
+	}
+	for _, cluster := range clusters {
+		decision.Status.Decisions = append(decision.Status.Decisions, clusterv1beta1.ClusterDecision{ClusterName: cluster})
+	}
+	return decision
+}
+
+// newRolloutAddon returns the observability addon of a cluster that was given
+// the desired config hash and applied the applied one, progressing with the
+// reason since the given time
+func newRolloutAddon(cluster, desired, applied, reason string, since time.Time) *addonv1alpha1.ManagedClusterAddOn {
+	addon := newClusterAddon(cluster, "observability", nil)
+	addon.Status.ConfigReferences = []addonv1alpha1.ConfigReference{addonConfigReference("default", "observability", desired, applied)}
+	addon.Status.Conditions = []metav1.Condition{{
+		Type:               addonv1alpha1.ManagedClusterAddOnConditionProgressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		LastTransitionTime: metav1.NewTime(since),
+	}}
+	return addon
+}
+
+func newAddonRolloutClient(t *testing.T) *client.OCMClient {
+	now := time.Now()
+	desired := addonConfigReference("default", "observability", "new", "")
+	return newFakeOCMClient(t,
+		&addonv1alpha1.ClusterManagementAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+			Spec: addonv1alpha1.ClusterManagementAddOnSpec{
+				InstallStrategy: addonv1alpha1.InstallStrategy{
+					Type: addonv1alpha1.AddonInstallStrategyPlacements,
+					Placements: []addonv1alpha1.PlacementStrategy{
+						{
+							PlacementRef: addonv1alpha1.PlacementRef{Namespace: "default", Name: "prod"},
+							RolloutStrategy: clusterv1alpha1.RolloutStrategy{
+								Type: clusterv1alpha1.ProgressivePerGroup,
+								ProgressivePerGroup: &clusterv1alpha1.RolloutProgressivePerGroup{
+									RolloutConfig: clusterv1alpha1.RolloutConfig{ProgressDeadline: "10m"},
+								},
+							},
+						},
+						{PlacementRef: addonv1alpha1.PlacementRef{Namespace: "default", Name: "empty"}},
+					},
+				},
+			},
+			Status: addonv1alpha1.ClusterManagementAddOnStatus{
+				InstallProgressions: []addonv1alpha1.InstallProgression{
+					{
+						PlacementRef: addonv1alpha1.PlacementRef{Namespace: "default", Name: "prod"},
+						ConfigReferences: []addonv1alpha1.InstallConfigReference{
+							{ConfigGroupResource: desired.ConfigGroupResource, DesiredConfig: desired.DesiredConfig},
+						},
+						Conditions: []metav1.Condition{{Type: "Progressing", Status: metav1.ConditionTrue, Reason: "Upgrading"}},
+					},
+				},
+			},
+		},
+		newRolloutDecision("prod-decision-1", "0", "canary", "cluster1", "cluster2"),
+		newRolloutDecision("prod-decision-2", "1", "rest", "cluster3", "cluster4", "cluster5", "cluster6"),
+		newRolloutAddon("cluster1", "new", "new", addonv1alpha1.ProgressingReasonCompleted, now.Add(-time.Hour)),
+		newRolloutAddon("cluster2", "new", "old", addonv1alpha1.ProgressingReasonFailed, now.Add(-time.Minute)),
+		newRolloutAddon("cluster3", "new", "old", addonv1alpha1.ProgressingReasonProgressing, now.Add(-time.Minute)),
+		newRolloutAddon("cluster4", "new", "old", addonv1alpha1.ProgressingReasonProgressing, now.Add(-time.Hour)),
+		// cluster5 has no addon yet and cluster6 has not been given the new config
+		newRolloutAddon("cluster6", "old", "old", addonv1alpha1.ProgressingReasonCompleted, now.Add(-time.Hour)),
+	)
+}
+
+func TestGetClusterManagementAddOnRollout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runAddonRequest(GetClusterManagementAddOnRollout, newAddonRolloutClient(t), gin.Params{{Key: "name", Value: "observability"}}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var rollout models.AddonRollout
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rollout))
+	require.Len(t, rollout.Placements, 2)
+
+	prod := rollout.Placements[0]
+	assert.Equal(t, "prod", prod.Name)
+	assert.Equal(t, "ProgressivePerGroup", prod.RolloutStrategy.Type)
+	require.Len(t, prod.Configs, 1)
+	assert.Equal(t, "new", prod.Configs[0].DesiredConfig.SpecHash)
+	require.Len(t, prod.Conditions, 1)
+	assert.Equal(t, "Upgrading", prod.Conditions[0].Reason)
+
+	statuses := map[string]string{}
+	for _, cluster := range prod.Clusters {
+		statuses[cluster.ClusterName] = cluster.Status
+	}
+	assert.Equal(t, map[string]string{
+		"cluster1": "Succeeded",
+		"cluster2": "Failed",
+		"cluster3": "Progressing",
+		"cluster4": "TimeOut",
+		"cluster5": "ToApply",
+		"cluster6": "ToApply",
+	}, statuses)
+	assert.Equal(t, "rest", prod.Clusters[2].DecisionGroupName)
+	assert.Equal(t, models.AddonRolloutSummary{Total: 6, ToApply: 2, Progressing: 1, Succeeded: 1, Failed: 1, TimeOut: 1}, prod.Summary)
+
+	// The canary group has a failed cluster, so it has not finished
+	assert.Equal(t, &models.AddonRolloutDecisionGroup{Index: 0, Name: "canary"}, prod.ActiveDecisionGroup)
+
+	// A placement without decisions has nothing to roll out
+	empty := rollout.Placements[1]
+	assert.Empty(t, empty.Clusters)
+	assert.Nil(t, empty.ActiveDecisionGroup)
+	assert.Equal(t, "All", empty.RolloutStrategy.Type)
+}
+
+func TestGetClusterManagementAddOnRolloutFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The caller may read the ClusterManagementAddOn and the addons in cluster3
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		switch {
+		case attrs.Resource == clusterManagementAddOnsResource && attrs.Verb == "get":
+			review.Status.Allowed = true
+		case attrs.Resource == managedClusterAddOnsResource && attrs.Verb == "list" && attrs.Namespace == "cluster3":
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+	ocmClient := newAddonRolloutClient(t)
+	ocmClient.Authorizer = auth.NewAuthorizer(kubeClient, time.Minute)
+
+	w := runAddonRequest(GetClusterManagementAddOnRollout, ocmClient, gin.Params{{Key: "name", Value: "observability"}}, &authv1.UserInfo{Username: "tenant"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var rollout models.AddonRollout
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rollout))
+	require.Len(t, rollout.Placements[0].Clusters, 1)
+	assert.Equal(t, "cluster3", rollout.Placements[0].Clusters[0].ClusterName)
+	assert.Equal(t, &models.AddonRolloutDecisionGroup{Index: 1, Name: "rest"}, rollout.Placements[0].ActiveDecisionGroup)
+
+	// Without access to the ClusterManagementAddOn the rollout is hidden
+	ocmClient.Authorizer = tenantAuthorizer()
+	w = runAddonRequest(GetClusterManagementAddOnRollout, ocmClient, gin.Params{{Key: "name", Value: "observability"}}, &authv1.UserInfo{Username: "tenant"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetClusterManagementAddOnRolloutErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runAddonRequest(GetClusterManagementAddOnRollout, newAddonRolloutClient(t), gin.Params{{Key: "name", Value: "missing"}}, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = runAddonRequest(GetClusterManagementAddOnRollout, &client.OCMClient{}, gin.Params{{Key: "name", Value: "observability"}}, nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
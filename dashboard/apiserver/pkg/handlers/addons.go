@@ -4,88 +4,63 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
+
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 )
 
+// addonConfigRolloutTimeout is how long an addon may stay out of sync with its
+// configs before its config rollout is reported as stuck
+const addonConfigRolloutTimeout = 10 * time.Minute
+
 // GetClusterAddons handles retrieving all addons for a specific cluster
 func GetClusterAddons(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	clusterName := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.AddonClient == nil {
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// List real managed cluster addons for the specific namespace (cluster name)
-	list, err := ocmClient.AddonClient.AddonV1alpha1().ManagedClusterAddOns(clusterName).List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, clusterAddonListFields, clusterAddonListSorts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Convert to our simplified ManagedClusterAddon format
-	addons := make([]models.ManagedClusterAddon, 0, len(list.Items))
-	for _, item := range list.Items {
-		// Extract the basic metadata
-		addon := models.ManagedClusterAddon{
-			ID:                string(item.GetUID()),
-			Name:              item.GetName(),
-			Namespace:         item.GetNamespace(),
-			CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
-		}
-
-		// Extract installNamespace from spec
-		if item.Spec.InstallNamespace != "" {
-			addon.InstallNamespace = item.Spec.InstallNamespace
-		}
-
-		// Extract conditions from status
-		for _, condition := range item.Status.Conditions {
-			addon.Conditions = append(addon.Conditions, models.Condition{
-				Type:               string(condition.Type),
-				Status:             string(condition.Status),
-				Reason:             condition.Reason,
-				Message:            condition.Message,
-				LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-			})
-		}
-
-		// Extract registrations from status
-		for _, registration := range item.Status.Registrations {
-			reg := models.AddonRegistration{
-				SignerName: registration.SignerName,
-			}
-
-			if registration.Subject.User != "" {
-				reg.Subject.User = registration.Subject.User
-			}
-
-			if len(registration.Subject.Groups) > 0 {
-				reg.Subject.Groups = registration.Subject.Groups
-			}
+	// List managed cluster addons for the specific namespace (cluster name) from the informer cache
+	list, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister().ManagedClusterAddOns(clusterName).List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-			addon.Registrations = append(addon.Registrations, reg)
-		}
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, addonGroup, managedClusterAddOnsResource)
 
-		// Extract supportedConfigs from status
-		for _, config := range item.Status.SupportedConfigs {
-			addon.SupportedConfigs = append(addon.SupportedConfigs, models.AddonSupportedConfig{
-				Group:    config.Group,
-				Resource: config.Resource,
-			})
+	// Convert to our simplified ManagedClusterAddon format
+	addons := make([]models.ManagedClusterAddon, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
 		}
-
+		addon := convertManagedClusterAddOnToModel(item)
 		addons = append(addons, addon)
 	}
 
-	c.JSON(http.StatusOK, addons)
+	sort.Slice(addons, func(i, j int) bool {
+		return addons[i].Name < addons[j].Name
+	})
+
+	query.respond(c, addons)
 }
 
 // GetClusterAddon handles retrieving a specific addon for a specific cluster
@@ -94,18 +69,32 @@ func GetClusterAddon(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 	addonName := c.Param("addonName")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.AddonClient == nil {
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Get the real managed cluster addon
-	item, err := ocmClient.AddonClient.AddonV1alpha1().ManagedClusterAddOns(clusterName).Get(ctx, addonName, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, addonGroup, managedClusterAddOnsResource).allowed(clusterName, addonName) {
+		respondForbidden(c, managedClusterAddOnsResource, addonName)
+		return
+	}
+
+	// Get the managed cluster addon from the informer cache
+	item, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister().ManagedClusterAddOns(clusterName).Get(addonName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Addon %s not found for cluster %s", addonName, clusterName)})
 		return
 	}
 
+	addon := convertManagedClusterAddOnToModel(item)
+	resolveAddonDeploymentConfigs(c, ocmClient, ctx, &addon)
+
+	c.JSON(http.StatusOK, addon)
+}
+
+// Helper function to convert a ManagedClusterAddOn resource to our model
+func convertManagedClusterAddOnToModel(item *addonv1alpha1.ManagedClusterAddOn) models.ManagedClusterAddon {
 	// Extract the basic metadata
 	addon := models.ManagedClusterAddon{
 		ID:                string(item.GetUID()),
@@ -155,5 +144,165 @@ func GetClusterAddon(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 		})
 	}
 
-	c.JSON(http.StatusOK, addon)
+	// Extract configReferences from status
+	for _, ref := range item.Status.ConfigReferences {
+		addon.ConfigReferences = append(addon.ConfigReferences, convertAddonConfigReferenceToModel(ref))
+	}
+	addon.ConfigRollout = convertAddonConfigRolloutToModel(item, addon.ConfigReferences, time.Now())
+
+	return addon
+}
+
+// convertAddonConfigReferenceToModel reads the desired and last applied spec
+// hash of a config. A config without a desired hash has nothing to roll out.
+func convertAddonConfigReferenceToModel(ref addonv1alpha1.ConfigReference) models.AddonConfigReference {
+	reference := models.AddonConfigReference{
+		Group:     ref.Group,
+		Resource:  ref.Resource,
+		Namespace: ref.Namespace,
+		Name:      ref.Name,
+		InSync:    true,
+	}
+	if ref.DesiredConfig != nil {
+		reference.Namespace = ref.DesiredConfig.Namespace
+		reference.Name = ref.DesiredConfig.Name
+		reference.DesiredSpecHash = ref.DesiredConfig.SpecHash
+	}
+	if ref.LastAppliedConfig != nil {
+		reference.LastAppliedSpecHash = ref.LastAppliedConfig.SpecHash
+	}
+	if ref.DesiredConfig != nil {
+		reference.InSync = ref.LastAppliedConfig != nil &&
+			ref.LastAppliedConfig.ConfigReferent == ref.DesiredConfig.ConfigReferent &&
+			ref.LastAppliedConfig.SpecHash == ref.DesiredConfig.SpecHash
+	}
+	return reference
+}
+
+// convertAddonConfigRolloutToModel reports whether the addon's configs are
+// applied. An out of sync addon is stuck when its Progressing condition says
+// the rollout failed, or has not changed for addonConfigRolloutTimeout while
+// not waiting on a canary.
+func convertAddonConfigRolloutToModel(item *addonv1alpha1.ManagedClusterAddOn, refs []models.AddonConfigReference, now time.Time) *models.AddonConfigRollout {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	rollout := &models.AddonConfigRollout{InSync: true}
+	for _, ref := range refs {
+		if !ref.InSync {
+			rollout.InSync = false
+		}
+	}
+	if rollout.InSync {
+		return rollout
+	}
+
+	progressing := meta.FindStatusCondition(item.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionProgressing)
+	if progressing == nil {
+		return rollout
+	}
+	rollout.Reason = progressing.Reason
+	rollout.Message = progressing.Message
+	rollout.Since = progressing.LastTransitionTime.Format(time.RFC3339)
+
+	switch progressing.Reason {
+	case addonv1alpha1.ProgressingReasonFailed, addonv1alpha1.ProgressingReasonConfigurationUnsupported:
+		rollout.Stuck = true
+	case addonv1alpha1.ProgressingReasonWaitingForCanary:
+	default:
+		rollout.Stuck = now.Sub(progressing.LastTransitionTime.Time) > addonConfigRolloutTimeout
+	}
+	return rollout
+}
+
+// resolveAddonDeploymentConfigs fills in the AddOnDeploymentConfigs an addon
+// references. Configs the caller may not read or that are gone are left out.
+func resolveAddonDeploymentConfigs(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, addon *models.ManagedClusterAddon) {
+	access := newAccessChecker(c, ocmClient, ctx, addonGroup, addOnDeploymentConfigsResource)
+	lister := ocmClient.AddonInformerFactory.Addon().V1alpha1().AddOnDeploymentConfigs().Lister()
+
+	for i, ref := range addon.ConfigReferences {
+		if ref.Group != addonGroup || ref.Resource != addOnDeploymentConfigsResource {
+			continue
+		}
+		if !access.allowed(ref.Namespace, ref.Name) {
+			continue
+		}
+		config, err := lister.AddOnDeploymentConfigs(ref.Namespace).Get(ref.Name)
+		if err != nil {
+			continue
+		}
+		deploymentConfig := convertAddOnDeploymentConfigToModel(config)
+		addon.ConfigReferences[i].DeploymentConfig = &deploymentConfig
+	}
+}
+
+// Helper function to convert an AddOnDeploymentConfig resource to our model
+func convertAddOnDeploymentConfigToModel(item *addonv1alpha1.AddOnDeploymentConfig) models.AddOnDeploymentConfig {
+	config := models.AddOnDeploymentConfig{
+		Namespace:             item.Namespace,
+		Name:                  item.Name,
+		AgentInstallNamespace: item.Spec.AgentInstallNamespace,
+	}
+
+	for _, variable := range item.Spec.CustomizedVariables {
+		config.CustomizedVariables = append(config.CustomizedVariables, models.AddonCustomizedVariable{
+			Name:  variable.Name,
+			Value: variable.Value,
+		})
+	}
+
+	if item.Spec.NodePlacement != nil {
+		config.NodePlacement = &models.AddonNodePlacement{NodeSelector: item.Spec.NodePlacement.NodeSelector}
+		for _, toleration := range item.Spec.NodePlacement.Tolerations {
+			config.NodePlacement.Tolerations = append(config.NodePlacement.Tolerations, models.AddonToleration{
+				Key:               toleration.Key,
+				Operator:          string(toleration.Operator),
+				Value:             toleration.Value,
+				Effect:            string(toleration.Effect),
+				TolerationSeconds: toleration.TolerationSeconds,
+			})
+		}
+	}
+
+	for _, registry := range item.Spec.Registries {
+		config.Registries = append(config.Registries, models.AddonImageMirror{
+			Mirror: registry.Mirror,
+			Source: registry.Source,
+		})
+	}
+
+	proxy := item.Spec.ProxyConfig
+	if proxy.HTTPProxy != "" || proxy.HTTPSProxy != "" || proxy.NoProxy != "" {
+		config.ProxyConfig = &models.AddonProxyConfig{
+			HTTPProxy:  proxy.HTTPProxy,
+			HTTPSProxy: proxy.HTTPSProxy,
+			NoProxy:    proxy.NoProxy,
+		}
+	}
+
+	return config
+}
+
+// clusterAddonListFields are the fields the addon list of a cluster can be
+// filtered on, the condition fields hold the status of the addon's condition
+var clusterAddonListFields = func() listFields[models.ManagedClusterAddon] {
+	fieldFuncs := namedFields(func(addon models.ManagedClusterAddon) string { return addon.Name })
+	for field, conditionType := range map[string]string{
+		"available":   addonv1alpha1.ManagedClusterAddOnConditionAvailable,
+		"degraded":    addonv1alpha1.ManagedClusterAddOnConditionDegraded,
+		"progressing": addonv1alpha1.ManagedClusterAddOnConditionProgressing,
+	} {
+		fieldFuncs[field] = func(addon models.ManagedClusterAddon) []string {
+			return conditionField(addon.Conditions, conditionType)
+		}
+	}
+	return fieldFuncs
+}()
+
+// clusterAddonListSorts are the keys the addon list of a cluster can be sorted by
+var clusterAddonListSorts = listSorts[models.ManagedClusterAddon]{
+	"name":              func(addon models.ManagedClusterAddon) string { return addon.Name },
+	"creationTimestamp": func(addon models.ManagedClusterAddon) string { return addon.CreationTimestamp },
 }
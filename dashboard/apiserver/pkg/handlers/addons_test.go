@@ -2,14 +2,21 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetClusterAddons(t *testing.T) {
@@ -93,3 +100,200 @@ func TestGetClusterAddon(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClusterAddonsFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "work-manager", Namespace: "cluster1"}},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "application-manager", Namespace: "cluster1"}},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "work-manager", Namespace: "cluster2"}},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "name", Value: "cluster1"}}
+
+	GetClusterAddons(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var addons []models.ManagedClusterAddon
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &addons))
+	assert.Len(t, addons, 2)
+	assert.Equal(t, "application-manager", addons[0].Name)
+	assert.Equal(t, "work-manager", addons[1].Name)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{
+		{Key: "name", Value: "cluster2"},
+		{Key: "addonName", Value: "application-manager"},
+	}
+
+	GetClusterAddon(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// addonConfigReference returns a reference to an AddOnDeploymentConfig with
+// the desired and last applied spec hashes, empty hashes are left unset
+func addonConfigReference(namespace, name, desired, applied string) addonv1alpha1.ConfigReference {
+	ref := addonv1alpha1.ConfigReference{
+		ConfigGroupResource: addonv1alpha1.ConfigGroupResource{Group: addonGroup, Resource: addOnDeploymentConfigsResource},
+	}
+	referent := addonv1alpha1.ConfigReferent{Namespace: namespace, Name: name}
+	if desired != "" {
+		ref.DesiredConfig = &addonv1alpha1.ConfigSpecHash{ConfigReferent: referent, SpecHash: desired}
+	}
+	if applied != "" {
+		ref.LastAppliedConfig = &addonv1alpha1.ConfigSpecHash{ConfigReferent: referent, SpecHash: applied}
+	}
+	return ref
+}
+
+func TestGetClusterAddonResolvesDeploymentConfigs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	seconds := int64(30)
+	ocmClient := newFakeOCMClient(t,
+		&addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability", Namespace: "cluster1"},
+			Status: addonv1alpha1.ManagedClusterAddOnStatus{
+				ConfigReferences: []addonv1alpha1.ConfigReference{
+					addonConfigReference("open-cluster-management", "observability", "new", "old"),
+					addonConfigReference("open-cluster-management", "missing", "abc", "abc"),
+				},
+			},
+		},
+		&addonv1alpha1.AddOnDeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability", Namespace: "open-cluster-management"},
+			Spec: addonv1alpha1.AddOnDeploymentConfigSpec{
+				CustomizedVariables: []addonv1alpha1.CustomizedVariable{{Name: "retention", Value: "24h"}},
+				NodePlacement: &addonv1alpha1.NodePlacement{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					Tolerations: []corev1.Toleration{
+						{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+					},
+				},
+				Registries:  []addonv1alpha1.ImageMirror{{Source: "quay.io/open-cluster-management", Mirror: "mirror.example.com/ocm"}},
+				ProxyConfig: addonv1alpha1.ProxyConfig{HTTPSProxy: "https://proxy.example.com", CABundle: []byte("ca")},
+			},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{
+		{Key: "name", Value: "cluster1"},
+		{Key: "addonName", Value: "observability"},
+	}
+
+	GetClusterAddon(c, ocmClient, context.Background())
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var addon models.ManagedClusterAddon
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &addon))
+	require.Len(t, addon.ConfigReferences, 2)
+	assert.Equal(t, models.AddonConfigReference{
+		Group:               addonGroup,
+		Resource:            addOnDeploymentConfigsResource,
+		Namespace:           "open-cluster-management",
+		Name:                "observability",
+		DesiredSpecHash:     "new",
+		LastAppliedSpecHash: "old",
+		DeploymentConfig: &models.AddOnDeploymentConfig{
+			Namespace:           "open-cluster-management",
+			Name:                "observability",
+			CustomizedVariables: []models.AddonCustomizedVariable{{Name: "retention", Value: "24h"}},
+			NodePlacement: &models.AddonNodePlacement{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations: []models.AddonToleration{
+					{Key: "node-role.kubernetes.io/infra", Operator: "Exists", Effect: "NoExecute", TolerationSeconds: &seconds},
+				},
+			},
+			Registries:  []models.AddonImageMirror{{Source: "quay.io/open-cluster-management", Mirror: "mirror.example.com/ocm"}},
+			ProxyConfig: &models.AddonProxyConfig{HTTPSProxy: "https://proxy.example.com"},
+		},
+	}, addon.ConfigReferences[0])
+
+	// A config that no longer exists is still listed, just not resolved
+	assert.True(t, addon.ConfigReferences[1].InSync)
+	assert.Nil(t, addon.ConfigReferences[1].DeploymentConfig)
+	require.NotNil(t, addon.ConfigRollout)
+	assert.False(t, addon.ConfigRollout.InSync)
+}
+
+func TestConvertAddonConfigRolloutToModel(t *testing.T) {
+	now := time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC)
+
+	progressing := func(reason string, age time.Duration) []metav1.Condition {
+		return []metav1.Condition{{
+			Type:               addonv1alpha1.ManagedClusterAddOnConditionProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(now.Add(-age)),
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		refs       []addonv1alpha1.ConfigReference
+		conditions []metav1.Condition
+		expected   *models.AddonConfigRollout
+	}{
+		{
+			name:     "no configs",
+			expected: nil,
+		},
+		{
+			name:       "in sync",
+			refs:       []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "abc", "abc")},
+			conditions: progressing(addonv1alpha1.ProgressingReasonCompleted, time.Hour),
+			expected:   &models.AddonConfigRollout{InSync: true},
+		},
+		{
+			name:     "no desired config",
+			refs:     []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "", "")},
+			expected: &models.AddonConfigRollout{InSync: true},
+		},
+		{
+			name:       "rolling out",
+			refs:       []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "new", "old")},
+			conditions: progressing(addonv1alpha1.ProgressingReasonProgressing, time.Minute),
+			expected:   &models.AddonConfigRollout{Reason: "Progressing", Since: "2025-05-20T08:59:00Z"},
+		},
+		{
+			name:       "rolling out for too long",
+			refs:       []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "new", "")},
+			conditions: progressing(addonv1alpha1.ProgressingReasonProgressing, time.Hour),
+			expected:   &models.AddonConfigRollout{Stuck: true, Reason: "Progressing", Since: "2025-05-20T08:00:00Z"},
+		},
+		{
+			name:       "failed",
+			refs:       []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "new", "old")},
+			conditions: progressing(addonv1alpha1.ProgressingReasonFailed, time.Minute),
+			expected:   &models.AddonConfigRollout{Stuck: true, Reason: "Failed", Since: "2025-05-20T08:59:00Z"},
+		},
+		{
+			name:       "waiting for canary",
+			refs:       []addonv1alpha1.ConfigReference{addonConfigReference("ns", "config", "new", "old")},
+			conditions: progressing(addonv1alpha1.ProgressingReasonWaitingForCanary, time.Hour),
+			expected:   &models.AddonConfigRollout{Reason: "WaitingForCanary", Since: "2025-05-20T08:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &addonv1alpha1.ManagedClusterAddOn{
+				Status: addonv1alpha1.ManagedClusterAddOnStatus{ConfigReferences: tt.refs, Conditions: tt.conditions},
+			}
+			var refs []models.AddonConfigReference
+			for _, ref := range tt.refs {
+				refs = append(refs, convertAddonConfigReferenceToModel(ref))
+			}
+			assert.Equal(t, tt.expected, convertAddonConfigRolloutToModel(item, refs, now))
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package handlers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+const (
+	// registrationSubjectPrefix prefixes the user and group names a klusterlet
+	// requests in its client certificate
+	registrationSubjectPrefix = "system:open-cluster-management:"
+	// managedClustersGroup is the group every registered klusterlet belongs to
+	managedClustersGroup = registrationSubjectPrefix + "managed-clusters"
+)
+
+// AcceptCluster accepts a cluster on the hub by setting hubAcceptsClient and
+// approving its pending registration CSRs
+func AcceptCluster(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	setHubAcceptsClient(c, ocmClient, ctx, true)
+}
+
+// DenyCluster revokes hub acceptance of a cluster and denies its pending registration CSRs
+func DenyCluster(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	setHubAcceptsClient(c, ocmClient, ctx, false)
+}
+
+// setHubAcceptsClient updates hubAcceptsClient as the caller and then approves
+// or denies the cluster's pending CSRs
+func setHubAcceptsClient(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, accept bool) {
+	name := c.Param("name")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	updated, err := updateHubAcceptsClient(ctx, userClients, name, accept)
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	var skipped []models.SkippedCSR
+	if userClients.KubernetesClient != nil {
+		skipped, err = decidePendingClusterCSRs(ctx, userClients.KubernetesClient, name, accept)
+		if err != nil {
+			respondKubeError(c, err)
+			return
+		}
+	}
+
+	cluster := convertManagedClusterToCluster(*updated)
+	cluster.SkippedCSRs = skipped
+	c.JSON(http.StatusOK, cluster)
+}
+
+// updateHubAcceptsClient sets hubAcceptsClient on a cluster, retrying on conflicts
+func updateHubAcceptsClient(ctx context.Context, userClients *client.OCMClient, name string, accept bool) (*clusterv1.ManagedCluster, error) {
+	var updated *clusterv1.ManagedCluster
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		managedCluster, err := userClients.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if managedCluster.Spec.HubAcceptsClient == accept {
+			updated = managedCluster
+			return nil
+		}
+		managedCluster.Spec.HubAcceptsClient = accept
+		updated, err = userClients.ClusterClient.ClusterV1().ManagedClusters().Update(ctx, managedCluster, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// isPendingCSR reports whether a CSR has been neither approved nor denied
+func isPendingCSR(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certificatesv1.CertificateApproved || condition.Type == certificatesv1.CertificateDenied {
+			return false
+		}
+	}
+	return true
+}
+
+// registrationCSRSelector matches the CSRs a cluster created to register
+// with the hub. Addon agents label their CSRs with the cluster name too, those
+// are left to the addon manager.
+func registrationCSRSelector(clusterName string) labels.Selector {
+	notAddon, _ := labels.NewRequirement(addonv1alpha1.AddonLabelKey, selection.DoesNotExist, nil)
+	return labels.SelectorFromSet(labels.Set{clusterv1.ClusterNameLabelKey: clusterName}).Add(*notAddon)
+}
+
+// isAddonCSR reports whether a CSR was created by an addon agent
+func isAddonCSR(csr *certificatesv1.CertificateSigningRequest) bool {
+	_, ok := csr.Labels[addonv1alpha1.AddonLabelKey]
+	return ok
+}
+
+// validateRegistrationCSR checks that a CSR requests the client certificate a
+// klusterlet of the cluster needs, the same way the registration controller
+// does before it approves a renewal. The cluster-name label is set by whoever
+// creates the CSR, so it is not enough on its own.
+func validateRegistrationCSR(csr *certificatesv1.CertificateSigningRequest, clusterName string) error {
+	if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientSignerName {
+		return fmt.Errorf("CSR %s uses signer %q instead of %q", csr.Name, csr.Spec.SignerName, certificatesv1.KubeAPIServerClientSignerName)
+	}
+
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return fmt.Errorf("CSR %s does not contain a PEM encoded certificate request", csr.Name)
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("CSR %s has an invalid certificate request: %w", csr.Name, err)
+	}
+
+	// The agent user is system:open-cluster-management:<cluster>:<agent>
+	userPrefix := registrationSubjectPrefix + clusterName + ":"
+	agent := strings.TrimPrefix(request.Subject.CommonName, userPrefix)
+	if agent == request.Subject.CommonName || agent == "" || strings.Contains(agent, ":") {
+		return fmt.Errorf("CSR %s requests user %q, expected %s<agent>", csr.Name, request.Subject.CommonName, userPrefix)
+	}
+
+	// Exactly the cluster group and the managed clusters group
+	clusterGroup := registrationSubjectPrefix + clusterName
+	organizations := request.Subject.Organization
+	if len(organizations) != 2 || !slices.Contains(organizations, clusterGroup) || !slices.Contains(organizations, managedClustersGroup) {
+		return fmt.Errorf("CSR %s requests groups %v, expected %s and %s", csr.Name, request.Subject.Organization, clusterGroup, managedClustersGroup)
+	}
+
+	// A klusterlet renewing its certificate must not request another identity
+	if strings.HasPrefix(csr.Spec.Username, registrationSubjectPrefix) && csr.Spec.Username != request.Subject.CommonName {
+		return fmt.Errorf("CSR %s was created by %q for user %q", csr.Name, csr.Spec.Username, request.Subject.CommonName)
+	}
+	return nil
+}
+
+// decidePendingClusterCSRs approves or denies every pending CSR that a cluster
+// created to register with the hub. CSRs that do not request a valid
+// registration certificate are never approved and stay pending, they are
+// returned with the reason.
+func decidePendingClusterCSRs(ctx context.Context, kubeClient kubernetes.Interface, clusterName string, approve bool) ([]models.SkippedCSR, error) {
+	csrs, err := kubeClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{LabelSelector: registrationCSRSelector(clusterName).String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var skipped []models.SkippedCSR
+	for i := range csrs.Items {
+		csr := &csrs.Items[i]
+		if !isPendingCSR(csr) {
+			continue
+		}
+		if approve {
+			if err := validateRegistrationCSR(csr, clusterName); err != nil {
+				log.Printf("Not approving CSR: %v", err)
+				skipped = append(skipped, models.SkippedCSR{Name: csr.Name, Reason: err.Error()})
+				continue
+			}
+		}
+		if err := decideCSR(ctx, kubeClient, csr, approve); err != nil {
+			return nil, err
+		}
+	}
+	return skipped, nil
+}
+
+// decideCSR adds an Approved or Denied condition to a CSR
+func decideCSR(ctx context.Context, kubeClient kubernetes.Interface, csr *certificatesv1.CertificateSigningRequest, approve bool) error {
+	condition := certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateDenied,
+		Status:         corev1.ConditionTrue,
+		Reason:         "DeniedByOCMDashboard",
+		Message:        "Denied from the OCM dashboard",
+		LastUpdateTime: metav1.Now(),
+	}
+	if approve {
+		condition.Type = certificatesv1.CertificateApproved
+		condition.Reason = "ApprovedByOCMDashboard"
+		condition.Message = "Approved from the OCM dashboard"
+	}
+
+	csr.Status.Conditions = append(csr.Status.Conditions, condition)
+	_, err := kubeClient.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{})
+	return err
+}
+
+// PatchClusterLabels adds, updates or removes cluster labels. Labels with a
+// null value are removed.
+func PatchClusterLabels(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	var patch models.ClusterLabelsPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if len(patch.Labels) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "labels is required"})
+		return
+	}
+
+	for key, value := range patch.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid label key %q: %s", key, strings.Join(errs, "; "))})
+			return
+		}
+		if value == nil {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(*value); len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid value for label %q: %s", key, strings.Join(errs, "; "))})
+			return
+		}
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	// A JSON merge patch removes keys whose value is null
+	body, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": patch.Labels,
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := userClients.ClusterClient.ClusterV1().ManagedClusters().Patch(ctx, name, types.MergePatchType, body, metav1.PatchOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManagedClusterToCluster(*updated))
+}
+
+// validTaintEffects are the effects a ManagedCluster taint may have
+var validTaintEffects = map[clusterv1.TaintEffect]bool{
+	clusterv1.TaintEffectNoSelect:       true,
+	clusterv1.TaintEffectPreferNoSelect: true,
+	clusterv1.TaintEffectNoSelectIfNew:  true,
+}
+
+// AddClusterTaint adds a taint to a cluster, replacing any taint with the same key and effect
+func AddClusterTaint(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	var taint models.Taint
+	if err := c.ShouldBindJSON(&taint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid taint key %q: %s", taint.Key, strings.Join(errs, "; "))})
+		return
+	}
+	if taint.Value != "" {
+		if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid taint value %q: %s", taint.Value, strings.Join(errs, "; "))})
+			return
+		}
+	}
+	if !validTaintEffects[clusterv1.TaintEffect(taint.Effect)] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid taint effect %q, must be one of NoSelect, PreferNoSelect or NoSelectIfNew", taint.Effect)})
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	updated, err := updateClusterTaints(ctx, userClients, name, func(taints []clusterv1.Taint) ([]clusterv1.Taint, bool) {
+		newTaint := clusterv1.Taint{
+			Key:       taint.Key,
+			Value:     taint.Value,
+			Effect:    clusterv1.TaintEffect(taint.Effect),
+			TimeAdded: metav1.Now(),
+		}
+		for i := range taints {
+			if taints[i].Key == newTaint.Key && taints[i].Effect == newTaint.Effect {
+				taints[i] = newTaint
+				return taints, true
+			}
+		}
+		return append(taints, newTaint), true
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManagedClusterToCluster(*updated))
+}
+
+// RemoveClusterTaint removes the taints with the given key from a cluster,
+// optionally only those with the effect given by ?effect=. The key comes from a
+// catch-all route parameter so that prefixed keys, which contain a slash, match.
+func RemoveClusterTaint(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	effect := c.Query("effect")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taint key is required"})
+		return
+	}
+
+	updated, err := updateClusterTaints(ctx, userClients, name, func(taints []clusterv1.Taint) ([]clusterv1.Taint, bool) {
+		kept := make([]clusterv1.Taint, 0, len(taints))
+		for _, t := range taints {
+			if t.Key == key && (effect == "" || string(t.Effect) == effect) {
+				continue
+			}
+			kept = append(kept, t)
+		}
+		return kept, len(kept) != len(taints)
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("taint %q not found on cluster %q", key, name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManagedClusterToCluster(*updated))
+}
+
+// updateClusterTaints applies mutate to the cluster's taints and retries on
+// conflicts. It returns nil when mutate reports that nothing changed.
+func updateClusterTaints(ctx context.Context, userClients *client.OCMClient, name string, mutate func([]clusterv1.Taint) ([]clusterv1.Taint, bool)) (*clusterv1.ManagedCluster, error) {
+	var updated *clusterv1.ManagedCluster
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		managedCluster, err := userClients.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		taints, changed := mutate(managedCluster.Spec.Taints)
+		if !changed {
+			updated = nil
+			return nil
+		}
+
+		managedCluster.Spec.Taints = taints
+		updated, err = userClients.ClusterClient.ClusterV1().ManagedClusters().Update(ctx, managedCluster, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// DeleteCluster detaches a cluster from the hub by deleting its ManagedCluster.
+// The response is the cluster as it was before deletion.
+func DeleteCluster(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	managedCluster, err := userClients.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	err = userClients.ClusterClient.ClusterV1().ManagedClusters().Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &managedCluster.UID},
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManagedClusterToCluster(*managedCluster))
+}
@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	fakecluster "open-cluster-management.io/api/client/cluster/clientset/versioned/fake"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newClusterActionClient returns an OCMClient whose clientsets hold the given
+// cluster and CSRs. With no user in the request the handlers use it directly.
+func newClusterActionClient(cluster *clusterv1.ManagedCluster, csrs ...*certificatesv1.CertificateSigningRequest) *client.OCMClient {
+	kubeClient := fakekube.NewSimpleClientset()
+	for _, csr := range csrs {
+		kubeClient.Tracker().Add(csr)
+	}
+	return &client.OCMClient{
+		KubernetesClient: kubeClient,
+		ClusterClient:    fakecluster.NewSimpleClientset(cluster),
+	}
+}
+
+// runClusterAction calls a cluster action handler with the given route params and JSON body
+func runClusterAction(handler func(*gin.Context, *client.OCMClient, context.Context), ocmClient *client.OCMClient, params gin.Params, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = params
+	c.Request, _ = http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler(c, ocmClient, context.Background())
+	return w
+}
+
+// newCertificateRequest returns a PEM encoded certificate request for the given subject
+func newCertificateRequest(commonName string, organizations ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName, Organization: organizations},
+	}, key)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+// newRegistrationCSR returns a CSR as a klusterlet creates it to register the cluster
+func newRegistrationCSR(name, clusterName string, conditions ...certificatesv1.CertificateSigningRequestCondition) *certificatesv1.CertificateSigningRequest {
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{clusterv1.ClusterNameLabelKey: clusterName},
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request: newCertificateRequest("system:open-cluster-management:"+clusterName+":agent1",
+				"system:open-cluster-management:"+clusterName, "system:open-cluster-management:managed-clusters"),
+			SignerName: certificatesv1.KubeAPIServerClientSignerName,
+			Username:   "system:serviceaccount:open-cluster-management:cluster-bootstrap",
+		},
+		Status: certificatesv1.CertificateSigningRequestStatus{Conditions: conditions},
+	}
+}
+
+func csrDecision(t *testing.T, ocmClient *client.OCMClient, name string) certificatesv1.RequestConditionType {
+	t.Helper()
+	csr, err := ocmClient.KubernetesClient.CertificatesV1().CertificateSigningRequests().Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	if len(csr.Status.Conditions) == 0 {
+		return ""
+	}
+	return csr.Status.Conditions[len(csr.Status.Conditions)-1].Type
+}
+
+func TestAcceptCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		newRegistrationCSR("cluster1-pending", "cluster1"),
+		newRegistrationCSR("cluster1-denied", "cluster1", certificatesv1.CertificateSigningRequestCondition{
+			Type:   certificatesv1.CertificateDenied,
+			Status: corev1.ConditionTrue,
+		}),
+		newRegistrationCSR("cluster2-pending", "cluster2"),
+	)
+
+	w := runClusterAction(AcceptCluster, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1/accept", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var cluster models.Cluster
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+	assert.Equal(t, "cluster1", cluster.Name)
+	assert.True(t, cluster.HubAccepted)
+	assert.Empty(t, cluster.SkippedCSRs)
+
+	// Only the cluster's pending CSRs are approved
+	assert.Equal(t, certificatesv1.CertificateApproved, csrDecision(t, ocmClient, "cluster1-pending"))
+	assert.Equal(t, certificatesv1.CertificateDenied, csrDecision(t, ocmClient, "cluster1-denied"))
+	assert.Equal(t, certificatesv1.RequestConditionType(""), csrDecision(t, ocmClient, "cluster2-pending"))
+}
+
+func TestDenyCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+			Spec:       clusterv1.ManagedClusterSpec{HubAcceptsClient: true},
+		},
+		newRegistrationCSR("cluster1-pending", "cluster1"),
+	)
+
+	w := runClusterAction(DenyCluster, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1/deny", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var cluster models.Cluster
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+	assert.False(t, cluster.HubAccepted)
+	assert.Equal(t, certificatesv1.CertificateDenied, csrDecision(t, ocmClient, "cluster1-pending"))
+}
+
+func TestAcceptClusterSkipsInvalidCSRs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		mutate func(csr *certificatesv1.CertificateSigningRequest)
+		// ignored CSRs are not registration CSRs, so they are not reported
+		ignored bool
+	}{
+		{
+			name: "other signer",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.SignerName = certificatesv1.KubeletServingSignerName
+			},
+		},
+		{
+			name: "user of another cluster",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Request = newCertificateRequest("system:open-cluster-management:cluster2:agent1",
+					"system:open-cluster-management:cluster1", "system:open-cluster-management:managed-clusters")
+			},
+		},
+		{
+			name: "user outside the registration prefix",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Request = newCertificateRequest("admin",
+					"system:open-cluster-management:cluster1", "system:open-cluster-management:managed-clusters")
+			},
+		},
+		{
+			name: "extra group",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Request = newCertificateRequest("system:open-cluster-management:cluster1:agent1",
+					"system:open-cluster-management:cluster1", "system:open-cluster-management:managed-clusters", "system:masters")
+			},
+		},
+		{
+			name: "missing cluster group",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Request = newCertificateRequest("system:open-cluster-management:cluster1:agent1",
+					"system:open-cluster-management:managed-clusters")
+			},
+		},
+		{
+			name: "renewal by the agent of another cluster",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Username = "system:open-cluster-management:cluster2:agent1"
+			},
+		},
+		{
+			name: "not a certificate request",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Request = []byte("not a certificate request")
+			},
+		},
+		{
+			name: "addon CSR",
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Labels[addonv1alpha1.AddonLabelKey] = "governance"
+			},
+			ignored: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csr := newRegistrationCSR("cluster1-invalid", "cluster1")
+			tt.mutate(csr)
+			ocmClient := newClusterActionClient(
+				&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+				csr,
+				newRegistrationCSR("cluster1-valid", "cluster1"),
+			)
+
+			w := runClusterAction(AcceptCluster, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1/accept", "")
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, certificatesv1.RequestConditionType(""), csrDecision(t, ocmClient, "cluster1-invalid"))
+			assert.Equal(t, certificatesv1.CertificateApproved, csrDecision(t, ocmClient, "cluster1-valid"))
+
+			var cluster models.Cluster
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+			if tt.ignored {
+				assert.Empty(t, cluster.SkippedCSRs)
+				return
+			}
+			require.Len(t, cluster.SkippedCSRs, 1)
+			assert.Equal(t, "cluster1-invalid", cluster.SkippedCSRs[0].Name)
+			assert.NotEmpty(t, cluster.SkippedCSRs[0].Reason)
+		})
+	}
+}
+
+func TestAcceptClusterNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}})
+
+	w := runClusterAction(AcceptCluster, ocmClient, gin.Params{{Key: "name", Value: "missing"}}, "/api/clusters/missing/accept", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPatchClusterLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedLabels map[string]string
+	}{
+		{
+			name:           "add and remove labels",
+			body:           `{"labels": {"env": "prod", "team": null}}`,
+			expectedStatus: http.StatusOK,
+			expectedLabels: map[string]string{"env": "prod", "region": "us-east"},
+		},
+		{
+			name:           "invalid label key",
+			body:           `{"labels": {"bad key": "x"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid label value",
+			body:           `{"labels": {"env": "not a valid value"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "empty patch",
+			body:           `{"labels": {}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "cluster1",
+					Labels: map[string]string{"env": "dev", "team": "a", "region": "us-east"},
+				},
+			})
+
+			w := runClusterAction(PatchClusterLabels, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1/labels", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedLabels != nil {
+				var cluster models.Cluster
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+				assert.Equal(t, tt.expectedLabels, cluster.Labels)
+			}
+		})
+	}
+}
+
+func TestAddClusterTaint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedTaints []models.Taint
+	}{
+		{
+			name:           "add taint",
+			body:           `{"key": "maintenance", "effect": "NoSelect"}`,
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "gpu", Value: "none", Effect: "PreferNoSelect"},
+				{Key: "maintenance", Effect: "NoSelect"},
+			},
+		},
+		{
+			name:           "replace taint with same key and effect",
+			body:           `{"key": "gpu", "value": "busy", "effect": "PreferNoSelect"}`,
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "gpu", Value: "busy", Effect: "PreferNoSelect"},
+			},
+		},
+		{
+			name:           "invalid effect",
+			body:           `{"key": "maintenance", "effect": "NoSchedule"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing key",
+			body:           `{"effect": "NoSelect"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+				Spec: clusterv1.ManagedClusterSpec{
+					Taints: []clusterv1.Taint{{Key: "gpu", Value: "none", Effect: clusterv1.TaintEffectPreferNoSelect}},
+				},
+			})
+
+			w := runClusterAction(AddClusterTaint, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1/taints", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedTaints != nil {
+				var cluster models.Cluster
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+				assert.Equal(t, tt.expectedTaints, cluster.Taints)
+			}
+		})
+	}
+}
+
+func TestRemoveClusterTaint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedTaints []models.Taint
+	}{
+		{
+			name:           "remove all effects for a key",
+			target:         "/api/clusters/cluster1/taints/gpu",
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "maintenance", Effect: "NoSelect"},
+				{Key: "cluster.open-cluster-management.io/unreachable", Effect: "NoSelect"},
+			},
+		},
+		{
+			name:           "remove a single effect",
+			target:         "/api/clusters/cluster1/taints/gpu?effect=NoSelect",
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "gpu", Effect: "PreferNoSelect"},
+				{Key: "maintenance", Effect: "NoSelect"},
+				{Key: "cluster.open-cluster-management.io/unreachable", Effect: "NoSelect"},
+			},
+		},
+		{
+			name:           "prefixed key",
+			target:         "/api/clusters/cluster1/taints/cluster.open-cluster-management.io/unreachable",
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "gpu", Effect: "PreferNoSelect"},
+				{Key: "gpu", Effect: "NoSelect"},
+				{Key: "maintenance", Effect: "NoSelect"},
+			},
+		},
+		{
+			name:           "escaped prefixed key",
+			target:         "/api/clusters/cluster1/taints/cluster.open-cluster-management.io%2Funreachable?effect=NoSelect",
+			expectedStatus: http.StatusOK,
+			expectedTaints: []models.Taint{
+				{Key: "gpu", Effect: "PreferNoSelect"},
+				{Key: "gpu", Effect: "NoSelect"},
+				{Key: "maintenance", Effect: "NoSelect"},
+			},
+		},
+		{
+			name:           "missing key",
+			target:         "/api/clusters/cluster1/taints/",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown taint",
+			target:         "/api/clusters/cluster1/taints/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+				Spec: clusterv1.ManagedClusterSpec{
+					Taints: []clusterv1.Taint{
+						{Key: "gpu", Effect: clusterv1.TaintEffectPreferNoSelect},
+						{Key: "gpu", Effect: clusterv1.TaintEffectNoSelect},
+						{Key: "maintenance", Effect: clusterv1.TaintEffectNoSelect},
+						{Key: "cluster.open-cluster-management.io/unreachable", Effect: clusterv1.TaintEffectNoSelect},
+					},
+				},
+			})
+
+			// Route the request the way the server does
+			r := gin.New()
+			r.DELETE("/api/clusters/:name/taints/*key", func(c *gin.Context) {
+				RemoveClusterTaint(c, ocmClient, c.Request.Context())
+			})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, tt.target, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedTaints != nil {
+				var cluster models.Cluster
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+				assert.Equal(t, tt.expectedTaints, cluster.Taints)
+			}
+		})
+	}
+}
+
+func TestDeleteCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", UID: "uid-1"}})
+
+	w := runClusterAction(DeleteCluster, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var cluster models.Cluster
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+	assert.Equal(t, "cluster1", cluster.Name)
+
+	_, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().Get(context.Background(), "cluster1", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestClusterActionsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(*gin.Context, *client.OCMClient, context.Context){
+		"accept":       AcceptCluster,
+		"deny":         DenyCluster,
+		"remove taint": RemoveClusterTaint,
+		"delete":       DeleteCluster,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := runClusterAction(handler, nil, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/clusters/cluster1", "")
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
+
+func TestClusterActionsImpersonateWithoutConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// An authenticated user can only act through an impersonating client
+	ocmClient := newClusterActionClient(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "name", Value: "cluster1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/clusters/cluster1/accept", nil)
+	auth.SetUser(c, &authv1.UserInfo{Username: "alice"})
+
+	AcceptCluster(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mc, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().Get(context.Background(), "cluster1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, mc.Spec.HubAcceptsClient)
+}
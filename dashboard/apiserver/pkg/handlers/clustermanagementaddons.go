@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// GetClusterManagementAddOns handles retrieving all ClusterManagementAddOns
+func GetClusterManagementAddOns(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
+		return
+	}
+
+	query, err := parseListQuery(c, clusterManagementAddOnListFields, clusterManagementAddOnListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Lister().List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, addonGroup, clusterManagementAddOnsResource)
+
+	addons := make([]models.ClusterManagementAddOn, 0, len(list))
+	for _, item := range list {
+		if !access.allowed("", item.Name) {
+			continue
+		}
+		addons = append(addons, convertClusterManagementAddOnToModel(item))
+	}
+
+	sort.Slice(addons, func(i, j int) bool {
+		return addons[i].Name < addons[j].Name
+	})
+
+	query.respond(c, addons)
+}
+
+// GetClusterManagementAddOn handles retrieving a specific ClusterManagementAddOn
+func GetClusterManagementAddOn(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
+		return
+	}
+
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, addonGroup, clusterManagementAddOnsResource).allowed("", name) {
+		respondForbidden(c, clusterManagementAddOnsResource, name)
+		return
+	}
+
+	item, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Lister().Get(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("ClusterManagementAddOn %s not found", name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, convertClusterManagementAddOnToModel(item))
+}
+
+// GetAddonHealthMatrix reports the state of every addon on every cluster. The
+// columns are the ClusterManagementAddOns plus any addon installed without
+// one, so a cluster missing an addon shows up as not installed.
+func GetAddonHealthMatrix(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.AddonInformerFactory == nil || ocmClient.ClusterInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
+		return
+	}
+
+	clusters, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	cmas, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Lister().List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	mcas, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister().List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Drop anything the caller is not allowed to read
+	clusterAccess := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource)
+	cmaAccess := newAccessChecker(c, ocmClient, ctx, addonGroup, clusterManagementAddOnsResource)
+	mcaAccess := newAccessChecker(c, ocmClient, ctx, addonGroup, managedClusterAddOnsResource)
+
+	rows := map[string]*models.AddonHealthRow{}
+	for _, cluster := range clusters {
+		if !clusterAccess.allowed("", cluster.Name) {
+			continue
+		}
+		rows[cluster.Name] = &models.AddonHealthRow{ClusterName: cluster.Name, Addons: map[string]models.AddonHealth{}}
+	}
+
+	addonNames := map[string]bool{}
+	for _, cma := range cmas {
+		if cmaAccess.allowed("", cma.Name) {
+			addonNames[cma.Name] = true
+		}
+	}
+	for _, mca := range mcas {
+		row, ok := rows[mca.Namespace]
+		if !ok || !mcaAccess.allowed(mca.Namespace, mca.Name) {
+			continue
+		}
+		addonNames[mca.Name] = true
+		row.Addons[mca.Name] = convertAddonHealthToModel(mca)
+	}
+
+	matrix := models.AddonHealthMatrix{
+		Addons:   make([]string, 0, len(addonNames)),
+		Clusters: make([]models.AddonHealthRow, 0, len(rows)),
+	}
+	for name := range addonNames {
+		matrix.Addons = append(matrix.Addons, name)
+	}
+	sort.Strings(matrix.Addons)
+
+	for _, row := range rows {
+		for _, name := range matrix.Addons {
+			if _, installed := row.Addons[name]; !installed {
+				row.Addons[name] = models.AddonHealth{}
+			}
+		}
+		matrix.Clusters = append(matrix.Clusters, *row)
+	}
+	sort.Slice(matrix.Clusters, func(i, j int) bool {
+		return matrix.Clusters[i].ClusterName < matrix.Clusters[j].ClusterName
+	})
+
+	c.JSON(http.StatusOK, matrix)
+}
+
+// convertAddonHealthToModel reads the health conditions of an installed addon
+func convertAddonHealthToModel(item *addonv1alpha1.ManagedClusterAddOn) models.AddonHealth {
+	health := models.AddonHealth{Installed: true}
+	if condition := meta.FindStatusCondition(item.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionAvailable); condition != nil {
+		health.Available = string(condition.Status)
+	}
+	if condition := meta.FindStatusCondition(item.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionDegraded); condition != nil {
+		health.Degraded = string(condition.Status)
+	}
+	if condition := meta.FindStatusCondition(item.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionProgressing); condition != nil {
+		health.Progressing = string(condition.Status)
+	}
+	return health
+}
+
+// Helper function to convert a ClusterManagementAddOn resource to our model
+func convertClusterManagementAddOnToModel(item *addonv1alpha1.ClusterManagementAddOn) models.ClusterManagementAddOn {
+	addon := models.ClusterManagementAddOn{
+		ID:                string(item.GetUID()),
+		Name:              item.GetName(),
+		DisplayName:       item.Spec.AddOnMeta.DisplayName,
+		Description:       item.Spec.AddOnMeta.Description,
+		CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
+		InstallStrategy: models.AddonInstallStrategy{
+			Type: item.Spec.InstallStrategy.Type,
+		},
+	}
+	if addon.InstallStrategy.Type == "" {
+		addon.InstallStrategy.Type = addonv1alpha1.AddonInstallStrategyManual
+	}
+
+	for _, config := range item.Spec.SupportedConfigs {
+		supported := models.AddonConfigMeta{
+			Group:    config.Group,
+			Resource: config.Resource,
+		}
+		if config.DefaultConfig != nil {
+			supported.DefaultConfig = &models.AddonConfigReferent{
+				Namespace: config.DefaultConfig.Namespace,
+				Name:      config.DefaultConfig.Name,
+			}
+		}
+		addon.SupportedConfigs = append(addon.SupportedConfigs, supported)
+	}
+
+	for _, placement := range item.Spec.InstallStrategy.Placements {
+		strategy := models.AddonPlacementStrategy{
+			Namespace:       placement.Namespace,
+			Name:            placement.Name,
+			RolloutStrategy: convertRolloutStrategyToModel(placement.RolloutStrategy),
+		}
+		for _, config := range placement.Configs {
+			strategy.Configs = append(strategy.Configs, models.AddonConfig{
+				Group:     config.Group,
+				Resource:  config.Resource,
+				Namespace: config.Namespace,
+				Name:      config.Name,
+			})
+		}
+		addon.InstallStrategy.Placements = append(addon.InstallStrategy.Placements, strategy)
+	}
+
+	for _, ref := range item.Status.DefaultConfigReferences {
+		reference := models.AddonDefaultConfigReference{
+			Group:    ref.Group,
+			Resource: ref.Resource,
+		}
+		reference.DesiredConfig = convertConfigSpecHashToModel(ref.DesiredConfig)
+		addon.DefaultConfigReferences = append(addon.DefaultConfigReferences, reference)
+	}
+
+	return addon
+}
+
+// convertConfigSpecHashToModel converts a config spec hash, nil when unset
+func convertConfigSpecHashToModel(hash *addonv1alpha1.ConfigSpecHash) *models.AddonConfigSpecHash {
+	if hash == nil {
+		return nil
+	}
+	return &models.AddonConfigSpecHash{
+		Namespace: hash.Namespace,
+		Name:      hash.Name,
+		SpecHash:  hash.SpecHash,
+	}
+}
+
+// clusterManagementAddOnListFields are the fields the ClusterManagementAddOn
+// list can be filtered on
+var clusterManagementAddOnListFields = func() listFields[models.ClusterManagementAddOn] {
+	fieldFuncs := namedFields(func(addon models.ClusterManagementAddOn) string { return addon.Name })
+	fieldFuncs["installStrategy"] = func(addon models.ClusterManagementAddOn) []string {
+		return []string{addon.InstallStrategy.Type}
+	}
+	return fieldFuncs
+}()
+
+// clusterManagementAddOnListSorts are the keys the ClusterManagementAddOn list can be sorted by
+var clusterManagementAddOnListSorts = listSorts[models.ClusterManagementAddOn]{
+	"name":              func(addon models.ClusterManagementAddOn) string { return addon.Name },
+	"creationTimestamp": func(addon models.ClusterManagementAddOn) string { return addon.CreationTimestamp },
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newClusterAddon returns an addon installed on a cluster with the given conditions set
+func newClusterAddon(cluster, name string, conditions map[string]metav1.ConditionStatus) *addonv1alpha1.ManagedClusterAddOn {
+	addon := &addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster}}
+	for conditionType, status := range conditions {
+		addon.Status.Conditions = append(addon.Status.Conditions, metav1.Condition{Type: conditionType, Status: status})
+	}
+	return addon
+}
+
+func newClusterManagementAddOnClient(t *testing.T) *client.OCMClient {
+	return newFakeOCMClient(t,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		&addonv1alpha1.ClusterManagementAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+			Spec: addonv1alpha1.ClusterManagementAddOnSpec{
+				AddOnMeta: addonv1alpha1.AddOnMeta{DisplayName: "Observability"},
+				SupportedConfigs: []addonv1alpha1.ConfigMeta{
+					{
+						ConfigGroupResource: addonv1alpha1.ConfigGroupResource{Group: "addon.open-cluster-management.io", Resource: "addondeploymentconfigs"},
+						DefaultConfig:       &addonv1alpha1.ConfigReferent{Namespace: "open-cluster-management", Name: "default"},
+					},
+				},
+				InstallStrategy: addonv1alpha1.InstallStrategy{
+					Type: addonv1alpha1.AddonInstallStrategyPlacements,
+					Placements: []addonv1alpha1.PlacementStrategy{
+						{
+							PlacementRef: addonv1alpha1.PlacementRef{Namespace: "default", Name: "prod"},
+							Configs: []addonv1alpha1.AddOnConfig{
+								{
+									ConfigGroupResource: addonv1alpha1.ConfigGroupResource{Group: "addon.open-cluster-management.io", Resource: "addondeploymentconfigs"},
+									ConfigReferent:      addonv1alpha1.ConfigReferent{Namespace: "default", Name: "prod"},
+								},
+							},
+							RolloutStrategy: clusterv1alpha1.RolloutStrategy{Type: clusterv1alpha1.Progressive},
+						},
+					},
+				},
+			},
+			Status: addonv1alpha1.ClusterManagementAddOnStatus{
+				DefaultConfigReferences: []addonv1alpha1.DefaultConfigReference{
+					{
+						ConfigGroupResource: addonv1alpha1.ConfigGroupResource{Group: "addon.open-cluster-management.io", Resource: "addondeploymentconfigs"},
+						DesiredConfig: &addonv1alpha1.ConfigSpecHash{
+							ConfigReferent: addonv1alpha1.ConfigReferent{Namespace: "open-cluster-management", Name: "default"},
+							SpecHash:       "abc",
+						},
+					},
+				},
+			},
+		},
+		&addonv1alpha1.ClusterManagementAddOn{ObjectMeta: metav1.ObjectMeta{Name: "policy"}},
+		newClusterAddon("cluster1", "observability", map[string]metav1.ConditionStatus{"Available": metav1.ConditionTrue, "Degraded": metav1.ConditionFalse}),
+		newClusterAddon("cluster1", "policy", map[string]metav1.ConditionStatus{"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionTrue}),
+		newClusterAddon("cluster2", "policy", map[string]metav1.ConditionStatus{"Available": metav1.ConditionTrue}),
+		// Addons without a ClusterManagementAddOn are still reported
+		newClusterAddon("cluster2", "legacy", nil),
+		// Addons in namespaces that are not clusters are ignored
+		newClusterAddon("default", "policy", nil),
+	)
+}
+
+func runAddonRequest(handler func(*gin.Context, *client.OCMClient, context.Context), ocmClient *client.OCMClient, params gin.Params, user *authv1.UserInfo) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Params = params
+	if user != nil {
+		auth.SetUser(c, user)
+	}
+	handler(c, ocmClient, c.Request.Context())
+	return w
+}
+
+func TestGetClusterManagementAddOns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runAddonRequest(GetClusterManagementAddOns, newClusterManagementAddOnClient(t), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var addons []models.ClusterManagementAddOn
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &addons))
+	require.Len(t, addons, 2)
+	assert.Equal(t, "observability", addons[0].Name)
+	assert.Equal(t, "policy", addons[1].Name)
+	assert.Equal(t, "Manual", addons[1].InstallStrategy.Type)
+}
+
+func TestGetClusterManagementAddOn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterManagementAddOnClient(t)
+
+	w := runAddonRequest(GetClusterManagementAddOn, ocmClient, gin.Params{{Key: "name", Value: "observability"}}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var addon models.ClusterManagementAddOn
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &addon))
+	assert.Equal(t, "Observability", addon.DisplayName)
+	assert.Equal(t, []models.AddonConfigMeta{
+		{
+			Group:         "addon.open-cluster-management.io",
+			Resource:      "addondeploymentconfigs",
+			DefaultConfig: &models.AddonConfigReferent{Namespace: "open-cluster-management", Name: "default"},
+		},
+	}, addon.SupportedConfigs)
+	assert.Equal(t, models.AddonInstallStrategy{
+		Type: "Placements",
+		Placements: []models.AddonPlacementStrategy{
+			{
+				Namespace: "default",
+				Name:      "prod",
+				Configs: []models.AddonConfig{
+					{Group: "addon.open-cluster-management.io", Resource: "addondeploymentconfigs", Namespace: "default", Name: "prod"},
+				},
+				RolloutStrategy: models.RolloutStrategy{Type: "Progressive"},
+			},
+		},
+	}, addon.InstallStrategy)
+	assert.Equal(t, []models.AddonDefaultConfigReference{
+		{
+			Group:         "addon.open-cluster-management.io",
+			Resource:      "addondeploymentconfigs",
+			DesiredConfig: &models.AddonConfigSpecHash{Namespace: "open-cluster-management", Name: "default", SpecHash: "abc"},
+		},
+	}, addon.DefaultConfigReferences)
+
+	w = runAddonRequest(GetClusterManagementAddOn, ocmClient, gin.Params{{Key: "name", Value: "missing"}}, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetAddonHealthMatrix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runAddonRequest(GetAddonHealthMatrix, newClusterManagementAddOnClient(t), nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var matrix models.AddonHealthMatrix
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &matrix))
+	assert.Equal(t, models.AddonHealthMatrix{
+		Addons: []string{"legacy", "observability", "policy"},
+		Clusters: []models.AddonHealthRow{
+			{
+				ClusterName: "cluster1",
+				Addons: map[string]models.AddonHealth{
+					"legacy":        {},
+					"observability": {Installed: true, Available: "True", Degraded: "False"},
+					"policy":        {Installed: true, Available: "False", Progressing: "True"},
+				},
+			},
+			{
+				ClusterName: "cluster2",
+				Addons: map[string]models.AddonHealth{
+					"legacy":        {Installed: true},
+					"observability": {},
+					"policy":        {Installed: true, Available: "True"},
+				},
+			},
+		},
+	}, matrix)
+}
+
+func TestGetAddonHealthMatrixFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The caller may read cluster1, the addons in it and no ClusterManagementAddOns
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		switch {
+		case attrs.Resource == managedClustersResource && attrs.Verb == "get" && attrs.Name == "cluster1":
+			review.Status.Allowed = true
+		case attrs.Resource == managedClusterAddOnsResource && attrs.Verb == "list" && attrs.Namespace == "cluster1":
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+	ocmClient := newClusterManagementAddOnClient(t)
+	ocmClient.Authorizer = auth.NewAuthorizer(kubeClient, time.Minute)
+
+	w := runAddonRequest(GetAddonHealthMatrix, ocmClient, nil, &authv1.UserInfo{Username: "tenant"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var matrix models.AddonHealthMatrix
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &matrix))
+	assert.Equal(t, []string{"observability", "policy"}, matrix.Addons)
+	require.Len(t, matrix.Clusters, 1)
+	assert.Equal(t, "cluster1", matrix.Clusters[0].ClusterName)
+
+	w = runAddonRequest(GetClusterManagementAddOn, ocmClient, gin.Params{{Key: "name", Value: "observability"}}, &authv1.UserInfo{Username: "tenant"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestClusterManagementAddOnsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(*gin.Context, *client.OCMClient, context.Context){
+		"list":   GetClusterManagementAddOns,
+		"get":    GetClusterManagementAddOn,
+		"matrix": GetAddonHealthMatrix,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := runAddonRequest(handler, &client.OCMClient{}, nil, nil)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
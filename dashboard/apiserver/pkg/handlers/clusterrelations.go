@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// GetClusterRelations handles retrieving everything that targets a cluster:
+// the placements selecting it, the cluster sets it belongs to, and the
+// ManifestWorks and addons in its namespace
+func GetClusterRelations(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil || ocmClient.WorkInformerFactory == nil || ocmClient.AddonInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	// Only return the relations if the caller is allowed to read the cluster
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource).allowed("", name) {
+		respondForbidden(c, managedClustersResource, name)
+		return
+	}
+
+	cluster, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().Get(name)
+	if apierrors.IsNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("cluster %s not found", name)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	relations := models.ClusterRelations{ClusterName: name}
+
+	relations.Placements, err = clusterPlacements(c, ocmClient, ctx, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	relations.ClusterSets, err = clusterSetsOf(c, ocmClient, ctx, cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ManifestWorks and addons of a cluster live in the cluster's namespace
+	works, err := ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Lister().ManifestWorks(name).List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	workAccess := newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource)
+	relations.ManifestWorks = make([]models.ManifestWork, 0, len(works))
+	for _, work := range works {
+		if workAccess.allowed(work.Namespace, work.Name) {
+			relations.ManifestWorks = append(relations.ManifestWorks, convertManifestWorkToModel(work))
+		}
+	}
+	sort.Slice(relations.ManifestWorks, func(i, j int) bool {
+		return relations.ManifestWorks[i].Name < relations.ManifestWorks[j].Name
+	})
+
+	addons, err := ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Lister().ManagedClusterAddOns(name).List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	addonAccess := newAccessChecker(c, ocmClient, ctx, addonGroup, managedClusterAddOnsResource)
+	relations.Addons = make([]models.ManagedClusterAddon, 0, len(addons))
+	for _, addon := range addons {
+		if addonAccess.allowed(addon.Namespace, addon.Name) {
+			relations.Addons = append(relations.Addons, convertManagedClusterAddOnToModel(addon))
+		}
+	}
+	sort.Slice(relations.Addons, func(i, j int) bool {
+		return relations.Addons[i].Name < relations.Addons[j].Name
+	})
+
+	c.JSON(http.StatusOK, relations)
+}
+
+// clusterPlacements returns the placements whose decisions select the cluster,
+// looked up through the PlacementDecision cluster index
+func clusterPlacements(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, clusterName string) ([]models.ClusterPlacementRelation, error) {
+	objs, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer().GetIndexer().ByIndex(client.PlacementDecisionsByCluster, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource)
+	placements := []models.ClusterPlacementRelation{}
+	for _, obj := range objs {
+		decision, ok := obj.(*clusterv1beta1.PlacementDecision)
+		if !ok {
+			continue
+		}
+		placementName := decision.Labels[clusterv1beta1.PlacementLabel]
+		if placementName == "" || !access.allowed(decision.Namespace, placementName) {
+			continue
+		}
+
+		relation := models.ClusterPlacementRelation{
+			Namespace:         decision.Namespace,
+			Name:              placementName,
+			DecisionName:      decision.Name,
+			DecisionGroupName: decision.Labels[clusterv1beta1.DecisionGroupNameLabel],
+		}
+		for _, d := range decision.Status.Decisions {
+			if d.ClusterName == clusterName {
+				relation.Reason = d.Reason
+			}
+		}
+		placements = append(placements, relation)
+	}
+
+	sort.Slice(placements, func(i, j int) bool {
+		if placements[i].Namespace != placements[j].Namespace {
+			return placements[i].Namespace < placements[j].Namespace
+		}
+		if placements[i].Name != placements[j].Name {
+			return placements[i].Name < placements[j].Name
+		}
+		return placements[i].DecisionName < placements[j].DecisionName
+	})
+	return placements, nil
+}
+
+// clusterSetsOf returns the names of the cluster sets selecting the cluster
+func clusterSetsOf(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, cluster *clusterv1.ManagedCluster) ([]string, error) {
+	clusterSets, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetsResource)
+	names := []string{}
+	for _, clusterSet := range clusterSets {
+		selector, err := clusterSetSelector(clusterSet)
+		if err != nil || !selector.Matches(labels.Set(cluster.Labels)) {
+			continue
+		}
+		if access.allowed("", clusterSet.Name) {
+			names = append(names, clusterSet.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+func newRelationsClient(t *testing.T) *client.OCMClient {
+	return newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "global"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+				SelectorType:  clusterv1beta2.LabelSelector,
+				LabelSelector: &metav1.LabelSelector{},
+			}},
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev"}}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "prod"}}},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-decision-1", Namespace: "app", Labels: map[string]string{
+				clusterv1beta1.PlacementLabel:         "web",
+				clusterv1beta1.DecisionGroupNameLabel: "canary",
+			}},
+			Status: clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{
+				{ClusterName: "cluster1", Reason: "selected"},
+				{ClusterName: "cluster2"},
+			}},
+		},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-decision-1", Namespace: "app", Labels: map[string]string{clusterv1beta1.PlacementLabel: "api"}},
+			Status:     clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster2"}}},
+		},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-decision-1", Namespace: "data", Labels: map[string]string{clusterv1beta1.PlacementLabel: "db"}},
+			Status:     clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster1"}}},
+		},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-b", Namespace: "cluster1"}},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-a", Namespace: "cluster1"}},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-c", Namespace: "cluster2"}},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "application-manager", Namespace: "cluster1"}},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "governance-policy-framework", Namespace: "cluster2"}},
+	)
+}
+
+func runClusterRelations(ocmClient *client.OCMClient, name string, user *authv1.UserInfo) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "name", Value: name}}
+	if user != nil {
+		auth.SetUser(c, user)
+	}
+	GetClusterRelations(c, ocmClient, context.Background())
+	return w
+}
+
+func TestGetClusterRelations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newRelationsClient(t)
+
+	tests := []struct {
+		name                string
+		clusterName         string
+		expectedStatus      int
+		expectedPlacements  []models.ClusterPlacementRelation
+		expectedClusterSets []string
+		expectedWorks       []string
+		expectedAddons      []string
+	}{
+		{
+			name:           "cluster1",
+			clusterName:    "cluster1",
+			expectedStatus: http.StatusOK,
+			expectedPlacements: []models.ClusterPlacementRelation{
+				{Namespace: "app", Name: "web", DecisionName: "web-decision-1", DecisionGroupName: "canary", Reason: "selected"},
+				{Namespace: "data", Name: "db", DecisionName: "db-decision-1"},
+			},
+			expectedClusterSets: []string{"dev", "global"},
+			expectedWorks:       []string{"work-a", "work-b"},
+			expectedAddons:      []string{"application-manager"},
+		},
+		{
+			name:           "cluster2",
+			clusterName:    "cluster2",
+			expectedStatus: http.StatusOK,
+			expectedPlacements: []models.ClusterPlacementRelation{
+				{Namespace: "app", Name: "api", DecisionName: "api-decision-1"},
+				{Namespace: "app", Name: "web", DecisionName: "web-decision-1", DecisionGroupName: "canary"},
+			},
+			expectedClusterSets: []string{"global", "prod"},
+			expectedWorks:       []string{"work-c"},
+			expectedAddons:      []string{"governance-policy-framework"},
+		},
+		{
+			name:           "unknown cluster",
+			clusterName:    "cluster9",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runClusterRelations(ocmClient, tt.clusterName, nil)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var relations models.ClusterRelations
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &relations))
+			assert.Equal(t, tt.clusterName, relations.ClusterName)
+			assert.Equal(t, tt.expectedPlacements, relations.Placements)
+			assert.Equal(t, tt.expectedClusterSets, relations.ClusterSets)
+
+			works := []string{}
+			for _, work := range relations.ManifestWorks {
+				works = append(works, work.Name)
+			}
+			assert.Equal(t, tt.expectedWorks, works)
+
+			addons := []string{}
+			for _, addon := range relations.Addons {
+				addons = append(addons, addon.Name)
+			}
+			assert.Equal(t, tt.expectedAddons, addons)
+		})
+	}
+}
+
+func TestGetClusterRelationsFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newRelationsClient(t)
+
+	// The tenant may not read clusters at all
+	ocmClient.Authorizer = tenantAuthorizer()
+	w := runClusterRelations(ocmClient, "cluster1", &authv1.UserInfo{Username: "tenant"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The app team may read cluster1, the dev set and the app placements only
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		switch {
+		case attrs.Resource == managedClustersResource && attrs.Name == "cluster1":
+			review.Status.Allowed = true
+		case attrs.Resource == managedClusterSetsResource && attrs.Name == "dev":
+			review.Status.Allowed = true
+		case attrs.Resource == placementsResource && attrs.Namespace == "app":
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+	ocmClient.Authorizer = auth.NewAuthorizer(kubeClient, time.Minute)
+
+	w = runClusterRelations(ocmClient, "cluster1", &authv1.UserInfo{Username: "app-team"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var relations models.ClusterRelations
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &relations))
+	require.Len(t, relations.Placements, 1)
+	assert.Equal(t, "web", relations.Placements[0].Name)
+	assert.Equal(t, []string{"dev"}, relations.ClusterSets)
+	assert.Empty(t, relations.ManifestWorks)
+	assert.Empty(t, relations.Addons)
+}
+
+func TestGetClusterRelationsRequiresClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runClusterRelations(nil, "cluster1", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
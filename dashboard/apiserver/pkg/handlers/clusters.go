@@ -3,10 +3,13 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
@@ -16,27 +19,44 @@ import (
 // GetClusters handles retrieving all clusters
 func GetClusters(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Use the OCM typed client to list ManagedClusters
-	clusterList, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, clusterListFields(c, ocmClient, ctx), clusterListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List ManagedClusters from the informer cache
+	clusterList, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource)
+
 	// Convert to our simplified Cluster format
-	clusters := make([]models.Cluster, 0, len(clusterList.Items))
-	for _, item := range clusterList.Items {
+	clusters := make([]models.Cluster, 0, len(clusterList))
+	for _, item := range clusterList {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
+		}
 		// Create a cluster object from the ManagedCluster
-		cluster := convertManagedClusterToCluster(item)
+		cluster := convertManagedClusterToCluster(*item)
 		clusters = append(clusters, cluster)
 	}
 
-	c.JSON(http.StatusOK, clusters)
+	// The cache has no ordering, keep the response stable
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+
+	query.respond(c, clusters)
 }
 
 // GetCluster handles retrieving a specific cluster by name
@@ -44,13 +64,19 @@ func GetCluster(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Use the OCM typed client to get a specific ManagedCluster
-	managedCluster, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource).allowed("", name) {
+		respondForbidden(c, managedClustersResource, name)
+		return
+	}
+
+	// Get the ManagedCluster from the informer cache
+	managedCluster, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().Get(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -70,6 +96,7 @@ func convertManagedClusterToCluster(managedCluster clusterv1.ManagedCluster) mod
 		Labels:            managedCluster.ObjectMeta.Labels,
 		CreationTimestamp: managedCluster.ObjectMeta.CreationTimestamp.Format(time.RFC3339),
 		Status:            "Unknown",
+		HubAccepted:       managedCluster.Spec.HubAcceptsClient,
 	}
 
 	// Extract Kubernetes version
@@ -128,6 +155,19 @@ func convertManagedClusterToCluster(managedCluster clusterv1.ManagedCluster) mod
 		cluster.Conditions = conditions
 	}
 
+	// Convert taints
+	if len(managedCluster.Spec.Taints) > 0 {
+		taints := make([]models.Taint, 0, len(managedCluster.Spec.Taints))
+		for _, t := range managedCluster.Spec.Taints {
+			taints = append(taints, models.Taint{
+				Key:    t.Key,
+				Value:  t.Value,
+				Effect: string(t.Effect),
+			})
+		}
+		cluster.Taints = taints
+	}
+
 	// Add cluster client configs
 	if len(managedCluster.Spec.ManagedClusterClientConfigs) > 0 {
 		configs := make([]models.ManagedClusterClientConfig, 0, len(managedCluster.Spec.ManagedClusterClientConfigs))
@@ -142,3 +182,43 @@ func convertManagedClusterToCluster(managedCluster clusterv1.ManagedCluster) mod
 
 	return cluster
 }
+
+// clusterListSorts are the keys the cluster list can be sorted by
+var clusterListSorts = listSorts[models.Cluster]{
+	"name":              func(cluster models.Cluster) string { return cluster.Name },
+	"status":            func(cluster models.Cluster) string { return cluster.Status },
+	"creationTimestamp": func(cluster models.Cluster) string { return cluster.CreationTimestamp },
+}
+
+// clusterListFields are the fields the cluster list can be filtered on. The
+// cluster sets of a cluster are the sets the caller may read whose selector
+// matches it, they are only resolved when the clusterset field is used.
+func clusterListFields(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) listFields[models.Cluster] {
+	fieldFuncs := namedFields(func(cluster models.Cluster) string { return cluster.Name })
+	fieldFuncs["status"] = func(cluster models.Cluster) []string { return []string{cluster.Status} }
+	fieldFuncs["version"] = func(cluster models.Cluster) []string { return []string{cluster.Version} }
+	fieldFuncs["hubAccepted"] = func(cluster models.Cluster) []string { return []string{strconv.FormatBool(cluster.HubAccepted)} }
+
+	var selectors map[string]labels.Selector
+	fieldFuncs["clusterset"] = func(cluster models.Cluster) []string {
+		if selectors == nil {
+			selectors = map[string]labels.Selector{}
+			clusterSets, _ := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Lister().List(labels.Everything())
+			access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetsResource)
+			for _, clusterSet := range clusterSets {
+				selector, err := clusterSetSelector(clusterSet)
+				if err == nil && access.allowed("", clusterSet.Name) {
+					selectors[clusterSet.Name] = selector
+				}
+			}
+		}
+		names := []string{}
+		for name, selector := range selectors {
+			if selector.Matches(labels.Set(cluster.Labels)) {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return fieldFuncs
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetClusters(t *testing.T) {
@@ -222,3 +224,67 @@ func TestConvertManagedClusterToCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClustersFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster-b", UID: types.UID("uid-b")}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster-a", UID: types.UID("uid-a")}},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	GetClusters(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var clusters []models.Cluster
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusters))
+	assert.Len(t, clusters, 2)
+	assert.Equal(t, "cluster-a", clusters[0].Name)
+	assert.Equal(t, "cluster-b", clusters[1].Name)
+}
+
+func TestGetClusterFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster-a", UID: types.UID("uid-a")}},
+	)
+
+	tests := []struct {
+		name           string
+		clusterName    string
+		expectedStatus int
+	}{
+		{
+			name:           "cached cluster",
+			clusterName:    "cluster-a",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing cluster",
+			clusterName:    "cluster-x",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "name", Value: tt.clusterName}}
+
+			GetCluster(c, ocmClient, context.Background())
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var cluster models.Cluster
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cluster))
+				assert.Equal(t, "uid-a", cluster.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// CreateClusterSet creates a ManagedClusterSet as the caller
+func CreateClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	var request models.ClusterSet
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if errs := validation.IsDNS1123Subdomain(request.Name); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid cluster set name %q: %s", request.Name, strings.Join(errs, "; "))})
+		return
+	}
+	selector, err := clusterSelectorFromModel(request.Spec.ClusterSelector)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	clusterSet := &clusterv1beta2.ManagedClusterSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   request.Name,
+			Labels: request.Labels,
+		},
+		Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: selector},
+	}
+	created, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Create(ctx, clusterSet, metav1.CreateOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, convertClusterSetToModel(created))
+}
+
+// UpdateClusterSet replaces the labels and cluster selector of a ManagedClusterSet.
+// Labels are kept when the request omits them, an empty object removes them.
+func UpdateClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	var request models.ClusterSet
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if request.Name != "" && request.Name != name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name %q does not match cluster set %q", request.Name, name)})
+		return
+	}
+	selector, err := clusterSelectorFromModel(request.Spec.ClusterSelector)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	var updated *clusterv1beta2.ManagedClusterSet
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		clusterSet, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if request.Labels != nil {
+			clusterSet.Labels = request.Labels
+		}
+		clusterSet.Spec.ClusterSelector = selector
+		updated, err = userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Update(ctx, clusterSet, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertClusterSetToModel(updated))
+}
+
+// DeleteClusterSet deletes a ManagedClusterSet. The response is the set as it
+// was before deletion.
+func DeleteClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	clusterSet, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	err = userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &clusterSet.UID},
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertClusterSetToModel(clusterSet))
+}
+
+// MoveClustersToClusterSet moves clusters into a cluster set by setting their
+// cluster set label. Each cluster is moved on its own, the response reports
+// the outcome for every cluster.
+func MoveClustersToClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	var request models.ClusterSetMoveRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if len(request.Clusters) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "clusters is required"})
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	// Membership of a label selector set cannot be changed through the cluster set label
+	clusterSet, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+	if selectorType := clusterSet.Spec.ClusterSelector.SelectorType; selectorType != "" && selectorType != clusterv1beta2.ExclusiveClusterSetLabel {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cluster set %q selects clusters with a %s, clusters cannot be moved into it", name, selectorType)})
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{clusterv1beta2.ClusterSetLabel: name},
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]models.ClusterSetMoveResult, 0, len(request.Clusters))
+	for _, clusterName := range request.Clusters {
+		result := models.ClusterSetMoveResult{Cluster: clusterName}
+
+		managedCluster, err := userClients.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, clusterName, metav1.GetOptions{})
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.PreviousClusterSet = managedCluster.Labels[clusterv1beta2.ClusterSetLabel]
+
+		if result.PreviousClusterSet != name {
+			_, err = userClients.ClusterClient.ClusterV1().ManagedClusters().Patch(ctx, clusterName, types.MergePatchType, body, metav1.PatchOptions{})
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// clusterSelectorFromModel validates a cluster selector. An empty selector
+// type defaults to ExclusiveClusterSetLabel, the same as the API.
+func clusterSelectorFromModel(selector models.ClusterSelector) (clusterv1beta2.ManagedClusterSelector, error) {
+	switch clusterv1beta2.SelectorType(selector.SelectorType) {
+	case "", clusterv1beta2.ExclusiveClusterSetLabel:
+		if selector.LabelSelector != nil {
+			return clusterv1beta2.ManagedClusterSelector{}, fmt.Errorf("labelSelector is only allowed with selectorType %s", clusterv1beta2.LabelSelector)
+		}
+		return clusterv1beta2.ManagedClusterSelector{SelectorType: clusterv1beta2.ExclusiveClusterSetLabel}, nil
+	case clusterv1beta2.LabelSelector:
+		if selector.LabelSelector == nil {
+			return clusterv1beta2.ManagedClusterSelector{}, fmt.Errorf("labelSelector is required with selectorType %s", clusterv1beta2.LabelSelector)
+		}
+		labelSelector := &metav1.LabelSelector{MatchLabels: selector.LabelSelector.MatchLabels}
+		for _, expr := range selector.LabelSelector.MatchExpressions {
+			labelSelector.MatchExpressions = append(labelSelector.MatchExpressions, metav1.LabelSelectorRequirement{
+				Key:      expr.Key,
+				Operator: metav1.LabelSelectorOperator(expr.Operator),
+				Values:   expr.Values,
+			})
+		}
+		if _, err := metav1.LabelSelectorAsSelector(labelSelector); err != nil {
+			return clusterv1beta2.ManagedClusterSelector{}, fmt.Errorf("invalid labelSelector: %v", err)
+		}
+		return clusterv1beta2.ManagedClusterSelector{
+			SelectorType:  clusterv1beta2.LabelSelector,
+			LabelSelector: labelSelector,
+		}, nil
+	default:
+		return clusterv1beta2.ManagedClusterSelector{}, fmt.Errorf("invalid selectorType %q, must be %s or %s", selector.SelectorType, clusterv1beta2.ExclusiveClusterSetLabel, clusterv1beta2.LabelSelector)
+	}
+}
+
+// CreateClusterSetBinding binds a cluster set to a namespace. The caller must
+// be allowed to bind the set, which is checked before anything is written.
+func CreateClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+
+	var request models.ManagedClusterSetBinding
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	// A binding must be named after the cluster set it binds
+	if request.Name == "" {
+		request.Name = request.Spec.ClusterSet
+	}
+	if !validClusterSetBinding(c, request.Name, request.Spec.ClusterSet) {
+		return
+	}
+	if !authorizeClusterSetBind(c, ocmClient, ctx, request.Spec.ClusterSet) {
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	binding := &clusterv1beta2.ManagedClusterSetBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      request.Name,
+			Namespace: namespace,
+		},
+		Spec: clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: request.Spec.ClusterSet},
+	}
+	created, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Create(ctx, binding, metav1.CreateOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, convertClusterSetBindingToModel(created))
+}
+
+// UpdateClusterSetBinding replaces the spec of a ManagedClusterSetBinding
+func UpdateClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var request models.ManagedClusterSetBinding
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if request.Name != "" && request.Name != name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name %q does not match binding %q", request.Name, name)})
+		return
+	}
+	if !validClusterSetBinding(c, name, request.Spec.ClusterSet) {
+		return
+	}
+	if !authorizeClusterSetBind(c, ocmClient, ctx, request.Spec.ClusterSet) {
+		return
+	}
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	var updated *clusterv1beta2.ManagedClusterSetBinding
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		binding, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		binding.Spec.ClusterSet = request.Spec.ClusterSet
+		updated, err = userClients.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Update(ctx, binding, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertClusterSetBindingToModel(updated))
+}
+
+// DeleteClusterSetBinding removes a cluster set binding from a namespace. The
+// response is the binding as it was before deletion.
+func DeleteClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	binding, err := userClients.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	err = userClients.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &binding.UID},
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertClusterSetBindingToModel(binding))
+}
+
+// validClusterSetBinding checks that a binding names a cluster set and is
+// named after it, as the hub requires
+func validClusterSetBinding(c *gin.Context, name, clusterSet string) bool {
+	if clusterSet == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "spec.clusterSet is required"})
+		return false
+	}
+	if name != clusterSet {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("binding name %q must match cluster set %q", name, clusterSet)})
+		return false
+	}
+	return true
+}
+
+// authorizeClusterSetBind checks that the caller may bind the cluster set to a
+// namespace, the managedclustersets/bind permission the hub also enforces
+func authorizeClusterSetBind(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, clusterSet string) bool {
+	allowed := authorize(c, ocmClient, ctx, authorizationv1.ResourceAttributes{
+		Verb:        "create",
+		Group:       clusterGroup,
+		Resource:    managedClusterSetsResource,
+		Subresource: "bind",
+		Name:        clusterSet,
+	})
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("binding cluster set %q is forbidden for the current user", clusterSet)})
+	}
+	return allowed
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakecluster "open-cluster-management.io/api/client/cluster/clientset/versioned/fake"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newClusterSetActionClient returns an OCMClient whose cluster clientset holds the given objects
+func newClusterSetActionClient(objects ...runtime.Object) *client.OCMClient {
+	return &client.OCMClient{ClusterClient: fakecluster.NewSimpleClientset(objects...)}
+}
+
+func TestCreateClusterSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedType   clusterv1beta2.SelectorType
+	}{
+		{
+			name:           "default selector type",
+			body:           `{"name": "dev"}`,
+			expectedStatus: http.StatusCreated,
+			expectedType:   clusterv1beta2.ExclusiveClusterSetLabel,
+		},
+		{
+			name:           "label selector",
+			body:           `{"name": "prod", "spec": {"clusterSelector": {"selectorType": "LabelSelector", "labelSelector": {"matchExpressions": [{"key": "env", "operator": "In", "values": ["prod"]}]}}}}`,
+			expectedStatus: http.StatusCreated,
+			expectedType:   clusterv1beta2.LabelSelector,
+		},
+		{
+			name:           "label selector without selector",
+			body:           `{"name": "prod", "spec": {"clusterSelector": {"selectorType": "LabelSelector"}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "exclusive label with selector",
+			body:           `{"name": "dev", "spec": {"clusterSelector": {"selectorType": "ExclusiveClusterSetLabel", "labelSelector": {"matchLabels": {"env": "dev"}}}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid operator",
+			body:           `{"name": "prod", "spec": {"clusterSelector": {"selectorType": "LabelSelector", "labelSelector": {"matchExpressions": [{"key": "env", "operator": "Near"}]}}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown selector type",
+			body:           `{"name": "dev", "spec": {"clusterSelector": {"selectorType": "Everything"}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid name",
+			body:           `{"name": "Dev Set"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newClusterSetActionClient()
+			w := runClusterAction(CreateClusterSet, ocmClient, nil, "/api/clustersets", tt.body)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var clusterSet models.ClusterSet
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusterSet))
+			assert.Equal(t, string(tt.expectedType), clusterSet.Spec.ClusterSelector.SelectorType)
+
+			stored, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(context.Background(), clusterSet.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedType, stored.Spec.ClusterSelector.SelectorType)
+		})
+	}
+}
+
+func TestUpdateClusterSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterSetActionClient(&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}})
+	params := gin.Params{{Key: "name", Value: "dev"}}
+
+	w := runClusterAction(UpdateClusterSet, ocmClient, params, "/api/clustersets/dev",
+		`{"labels": {"team": "a"}, "spec": {"clusterSelector": {"selectorType": "LabelSelector", "labelSelector": {"matchLabels": {"env": "dev"}}}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	stored, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(context.Background(), "dev", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", stored.Labels["team"])
+	assert.Equal(t, map[string]string{"env": "dev"}, stored.Spec.ClusterSelector.LabelSelector.MatchLabels)
+
+	// Omitted labels are kept, an empty object removes them
+	w = runClusterAction(UpdateClusterSet, ocmClient, params, "/api/clustersets/dev", `{"spec": {"clusterSelector": {"selectorType": "ExclusiveClusterSetLabel"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err = ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(context.Background(), "dev", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "a"}, stored.Labels)
+
+	w = runClusterAction(UpdateClusterSet, ocmClient, params, "/api/clustersets/dev", `{"labels": {}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err = ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(context.Background(), "dev", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, stored.Labels)
+
+	w = runClusterAction(UpdateClusterSet, ocmClient, params, "/api/clustersets/dev", `{"name": "prod"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = runClusterAction(UpdateClusterSet, ocmClient, gin.Params{{Key: "name", Value: "missing"}}, "/api/clustersets/missing", `{}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteClusterSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterSetActionClient(&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev", UID: "uid-1"}})
+
+	w := runClusterAction(DeleteClusterSet, ocmClient, gin.Params{{Key: "name", Value: "dev"}}, "/api/clustersets/dev", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(context.Background(), "dev", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestMoveClustersToClusterSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterSetActionClient(
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "global"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+				SelectorType:  clusterv1beta2.LabelSelector,
+				LabelSelector: &metav1.LabelSelector{},
+			}},
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "default"}}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+	)
+
+	w := runClusterAction(MoveClustersToClusterSet, ocmClient, gin.Params{{Key: "name", Value: "dev"}}, "/api/clustersets/dev/clusters",
+		`{"clusters": ["cluster1", "cluster2", "missing"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var results []models.ClusterSetMoveResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	require.Len(t, results, 3)
+	assert.Equal(t, "default", results[0].PreviousClusterSet)
+	assert.Empty(t, results[0].Error)
+	assert.Empty(t, results[1].Error)
+	assert.NotEmpty(t, results[2].Error)
+
+	for _, name := range []string{"cluster1", "cluster2"} {
+		mc, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "dev", mc.Labels[clusterv1beta2.ClusterSetLabel])
+	}
+
+	// Label selector sets do not use the cluster set label
+	w = runClusterAction(MoveClustersToClusterSet, ocmClient, gin.Params{{Key: "name", Value: "global"}}, "/api/clustersets/global/clusters",
+		`{"clusters": ["cluster1"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = runClusterAction(MoveClustersToClusterSet, ocmClient, gin.Params{{Key: "name", Value: "dev"}}, "/api/clustersets/dev/clusters", `{"clusters": []}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateClusterSetBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "name defaults to the cluster set",
+			body:           `{"spec": {"clusterSet": "dev"}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "name does not match the cluster set",
+			body:           `{"name": "other", "spec": {"clusterSet": "dev"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing cluster set",
+			body:           `{"name": "dev"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newClusterSetActionClient()
+			w := runClusterAction(CreateClusterSetBinding, ocmClient, gin.Params{{Key: "namespace", Value: "app"}}, "/api/namespaces/app/clustersetbindings", tt.body)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			binding, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings("app").Get(context.Background(), "dev", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "dev", binding.Spec.ClusterSet)
+		})
+	}
+}
+
+func TestCreateClusterSetBindingRequiresBindPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterSetActionClient()
+	// tenantAuthorizer never allows managedclustersets/bind
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "app"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/namespaces/app/clustersetbindings", strings.NewReader(`{"spec": {"clusterSet": "dev"}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	auth.SetUser(c, &authv1.UserInfo{Username: "alice"})
+
+	CreateClusterSetBinding(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	_, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings("app").Get(context.Background(), "dev", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestUpdateAndDeleteClusterSetBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterSetActionClient(&clusterv1beta2.ManagedClusterSetBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "app", UID: "uid-1"},
+		Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "dev"},
+	})
+	params := gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "dev"}}
+
+	w := runClusterAction(UpdateClusterSetBinding, ocmClient, params, "/api/namespaces/app/clustersetbindings/dev", `{"spec": {"clusterSet": "prod"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = runClusterAction(UpdateClusterSetBinding, ocmClient, params, "/api/namespaces/app/clustersetbindings/dev", `{"spec": {"clusterSet": "dev"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = runClusterAction(DeleteClusterSetBinding, ocmClient, params, "/api/namespaces/app/clustersetbindings/dev", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	_, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings("app").Get(context.Background(), "dev", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestClusterSetActionsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(*gin.Context, *client.OCMClient, context.Context){
+		"delete set":     DeleteClusterSet,
+		"delete binding": DeleteClusterSetBinding,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := runClusterAction(handler, nil, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "dev"}}, "/api/clustersets/dev", "")
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
@@ -3,61 +3,55 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
+
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 )
 
 // GetAllClusterSetBindings retrieves all ManagedClusterSetBindings across all namespaces
 func GetAllClusterSetBindings(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Since we don't have a direct way to list resources across all namespaces with the OCM client,
-	// we'll use an empty string to indicate "all namespaces" if the API supports it
-	list, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings("").List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, clusterSetBindingListFields, clusterSetBindingListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List bindings across all namespaces from the informer cache
+	list, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Lister().List(query.selector)
 	if err != nil {
-		// If listing across all namespaces is not supported, we'll handle the error
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to list clustersetbindings across all namespaces: " + err.Error()})
 		return
 	}
 
 	// Convert to our simplified ClusterSetBinding models
-	allBindings := make([]models.ManagedClusterSetBinding, 0, len(list.Items))
-
-	for _, item := range list.Items {
-		binding := models.ManagedClusterSetBinding{
-			ID:                string(item.GetUID()),
-			Name:              item.GetName(),
-			Namespace:         item.GetNamespace(),
-			CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
-			Spec: models.ManagedClusterSetBindingSpec{
-				ClusterSet: item.Spec.ClusterSet,
-			},
-		}
+	allBindings := make([]models.ManagedClusterSetBinding, 0, len(list))
 
-		// Extract status info (conditions)
-		for _, condition := range item.Status.Conditions {
-			binding.Status.Conditions = append(binding.Status.Conditions, models.Condition{
-				Type:               string(condition.Type),
-				Status:             string(condition.Status),
-				LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-				Reason:             condition.Reason,
-				Message:            condition.Message,
-			})
-		}
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetBindingsResource)
 
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
+		}
+		binding := convertClusterSetBindingToModel(item)
 		allBindings = append(allBindings, binding)
 	}
 
-	c.JSON(http.StatusOK, allBindings)
+	sortClusterSetBindings(allBindings)
+
+	query.respond(c, allBindings)
 }
 
 // GetClusterSetBindings retrieves all ManagedClusterSetBindings for a specific namespace
@@ -65,46 +59,40 @@ func GetClusterSetBindings(c *gin.Context, ocmClient *client.OCMClient, ctx cont
 	namespace := c.Param("namespace")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Get the cluster set bindings for the specified namespace
-	list, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, clusterSetBindingListFields, clusterSetBindingListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List the cluster set bindings for the specified namespace from the informer cache
+	list, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Lister().ManagedClusterSetBindings(namespace).List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Convert to our simplified ClusterSetBinding models
-	clusterSetBindings := make([]models.ManagedClusterSetBinding, 0, len(list.Items))
-	for _, item := range list.Items {
-		clusterSetBinding := models.ManagedClusterSetBinding{
-			ID:                string(item.GetUID()),
-			Name:              item.GetName(),
-			Namespace:         item.GetNamespace(),
-			CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
-			Spec: models.ManagedClusterSetBindingSpec{
-				ClusterSet: item.Spec.ClusterSet,
-			},
-		}
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetBindingsResource)
 
-		// Extract status info (conditions)
-		for _, condition := range item.Status.Conditions {
-			clusterSetBinding.Status.Conditions = append(clusterSetBinding.Status.Conditions, models.Condition{
-				Type:               string(condition.Type),
-				Status:             string(condition.Status),
-				LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-				Reason:             condition.Reason,
-				Message:            condition.Message,
-			})
+	// Convert to our simplified ClusterSetBinding models
+	clusterSetBindings := make([]models.ManagedClusterSetBinding, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
 		}
-
+		clusterSetBinding := convertClusterSetBindingToModel(item)
 		clusterSetBindings = append(clusterSetBindings, clusterSetBinding)
 	}
 
-	c.JSON(http.StatusOK, clusterSetBindings)
+	sortClusterSetBindings(clusterSetBindings)
+
+	query.respond(c, clusterSetBindings)
 }
 
 // GetClusterSetBinding retrieves a specific ManagedClusterSetBinding by name in a namespace
@@ -113,20 +101,33 @@ func GetClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx conte
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Get the cluster set binding by name
-	item, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSetBindings(namespace).Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetBindingsResource).allowed(namespace, name) {
+		respondForbidden(c, managedClusterSetBindingsResource, name)
+		return
+	}
+
+	// Get the cluster set binding by name from the informer cache
+	item, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Lister().ManagedClusterSetBindings(namespace).Get(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Convert to our simplified ClusterSetBinding model
-	clusterSetBinding := models.ManagedClusterSetBinding{
+	clusterSetBinding := convertClusterSetBindingToModel(item)
+
+	c.JSON(http.StatusOK, clusterSetBinding)
+}
+
+// Helper function to convert a ManagedClusterSetBinding resource to our model
+func convertClusterSetBindingToModel(item *clusterv1beta2.ManagedClusterSetBinding) models.ManagedClusterSetBinding {
+	binding := models.ManagedClusterSetBinding{
 		ID:                string(item.GetUID()),
 		Name:              item.GetName(),
 		Namespace:         item.GetNamespace(),
@@ -138,7 +139,7 @@ func GetClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx conte
 
 	// Extract status info (conditions)
 	for _, condition := range item.Status.Conditions {
-		clusterSetBinding.Status.Conditions = append(clusterSetBinding.Status.Conditions, models.Condition{
+		binding.Status.Conditions = append(binding.Status.Conditions, models.Condition{
 			Type:               string(condition.Type),
 			Status:             string(condition.Status),
 			LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
@@ -147,5 +148,35 @@ func GetClusterSetBinding(c *gin.Context, ocmClient *client.OCMClient, ctx conte
 		})
 	}
 
-	c.JSON(http.StatusOK, clusterSetBinding)
+	return binding
+}
+
+// sortClusterSetBindings orders bindings by namespace and name
+func sortClusterSetBindings(bindings []models.ManagedClusterSetBinding) {
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Namespace != bindings[j].Namespace {
+			return bindings[i].Namespace < bindings[j].Namespace
+		}
+		return bindings[i].Name < bindings[j].Name
+	})
+}
+
+// clusterSetBindingListFields are the fields the binding lists can be filtered on
+var clusterSetBindingListFields = func() listFields[models.ManagedClusterSetBinding] {
+	fieldFuncs := namespacedFields(
+		func(binding models.ManagedClusterSetBinding) string { return binding.Namespace },
+		func(binding models.ManagedClusterSetBinding) string { return binding.Name },
+	)
+	fieldFuncs["clusterset"] = func(binding models.ManagedClusterSetBinding) []string {
+		return []string{binding.Spec.ClusterSet}
+	}
+	return fieldFuncs
+}()
+
+// clusterSetBindingListSorts are the keys the binding lists can be sorted by
+var clusterSetBindingListSorts = listSorts[models.ManagedClusterSetBinding]{
+	"namespace":         func(binding models.ManagedClusterSetBinding) string { return binding.Namespace },
+	"name":              func(binding models.ManagedClusterSetBinding) string { return binding.Name },
+	"clusterset":        func(binding models.ManagedClusterSetBinding) string { return binding.Spec.ClusterSet },
+	"creationTimestamp": func(binding models.ManagedClusterSetBinding) string { return binding.CreationTimestamp },
 }
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetAllClusterSetBindings(t *testing.T) {
@@ -109,3 +113,42 @@ func TestGetClusterSetBinding(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClusterSetBindingsFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "ns2"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "default"},
+		},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "global", Namespace: "ns1"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "global"},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	GetAllClusterSetBindings(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var bindings []models.ManagedClusterSetBinding
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &bindings))
+	assert.Len(t, bindings, 2)
+	assert.Equal(t, "ns1", bindings[0].Namespace)
+	assert.Equal(t, "global", bindings[0].Spec.ClusterSet)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "ns2"}}
+
+	GetClusterSetBindings(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &bindings))
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, "default", bindings[0].Spec.ClusterSet)
+}
@@ -2,66 +2,70 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 )
 
 // GetClusterSets handles retrieving all cluster sets
 func GetClusterSets(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Normal processing - list real managed cluster sets using OCM client
-	list, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, clusterSetListFields, clusterSetListSorts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Convert to our simplified ClusterSet format
-	clusterSets := make([]models.ClusterSet, 0, len(list.Items))
-	for _, item := range list.Items {
-		// Extract the basic metadata
-		clusterSet := models.ClusterSet{
-			ID:                string(item.GetUID()),
-			Name:              item.GetName(),
-			Labels:            item.GetLabels(),
-			CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
-		}
+	// List managed cluster sets from the informer cache
+	list, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Lister().List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Extract spec info
-		clusterSet.Spec.ClusterSelector.SelectorType = string(item.Spec.ClusterSelector.SelectorType)
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetsResource)
 
-		if item.Spec.ClusterSelector.LabelSelector != nil {
-			clusterSet.Spec.ClusterSelector.LabelSelector = &models.LabelSelector{
-				MatchLabels: item.Spec.ClusterSelector.LabelSelector.MatchLabels,
-			}
-		}
+	members, err := newClusterSetMemberResolver(c, ocmClient, ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Extract status info
-		for _, condition := range item.Status.Conditions {
-			clusterSet.Status.Conditions = append(clusterSet.Status.Conditions, models.Condition{
-				Type:               string(condition.Type),
-				Status:             string(condition.Status),
-				LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-				Reason:             condition.Reason,
-				Message:            condition.Message,
-			})
+	// Convert to our simplified ClusterSet format
+	clusterSets := make([]models.ClusterSet, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
 		}
-
+		clusterSet := convertClusterSetToModel(item)
+		clusterSet.Membership = members.resolve(item)
 		clusterSets = append(clusterSets, clusterSet)
 	}
 
-	c.JSON(http.StatusOK, clusterSets)
+	sort.Slice(clusterSets, func(i, j int) bool {
+		return clusterSets[i].Name < clusterSets[j].Name
+	})
+
+	query.respond(c, clusterSets)
 }
 
 // GetClusterSet handles retrieving a specific cluster set
@@ -69,19 +73,142 @@ func GetClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Cont
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Get the cluster set by name using OCM client
-	item, err := ocmClient.ClusterClient.ClusterV1beta2().ManagedClusterSets().Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetsResource).allowed("", name) {
+		respondForbidden(c, managedClusterSetsResource, name)
+		return
+	}
+
+	// Get the cluster set by name from the informer cache
+	item, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Lister().Get(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := newClusterSetMemberResolver(c, ocmClient, ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Convert to our simplified ClusterSet format
+	clusterSet := convertClusterSetToModel(item)
+	clusterSet.Membership = members.resolve(item)
+
+	c.JSON(http.StatusOK, clusterSet)
+}
+
+// clusterSetMemberResolver computes cluster set membership from the clusters
+// and bindings the caller is allowed to read
+type clusterSetMemberResolver struct {
+	clusters []*clusterv1.ManagedCluster
+	bindings []*clusterv1beta2.ManagedClusterSetBinding
+	expand   bool
+}
+
+// newClusterSetMemberResolver loads the clusters and bindings from the informer
+// cache. Full cluster objects are embedded when the request has ?expand=clusters.
+func newClusterSetMemberResolver(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) (*clusterSetMemberResolver, error) {
+	clusterList, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	bindingList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &clusterSetMemberResolver{}
+	for _, expand := range strings.Split(c.Query("expand"), ",") {
+		if strings.TrimSpace(expand) == "clusters" {
+			resolver.expand = true
+		}
+	}
+
+	clusterAccess := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource)
+	for _, cluster := range clusterList {
+		if clusterAccess.allowed(cluster.Namespace, cluster.Name) {
+			resolver.clusters = append(resolver.clusters, cluster)
+		}
+	}
+	bindingAccess := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClusterSetBindingsResource)
+	for _, binding := range bindingList {
+		if bindingAccess.allowed(binding.Namespace, binding.Name) {
+			resolver.bindings = append(resolver.bindings, binding)
+		}
+	}
+
+	return resolver, nil
+}
+
+// resolve returns the membership of a cluster set. Exclusive sets select
+// clusters by the cluster set label, label selector sets (such as global)
+// by their selector. It returns nil when the selector is invalid.
+func (r *clusterSetMemberResolver) resolve(clusterSet *clusterv1beta2.ManagedClusterSet) *models.ClusterSetMembership {
+	selector, err := clusterSetSelector(clusterSet)
+	if err != nil {
+		log.Printf("Invalid cluster selector on cluster set %s: %v", clusterSet.Name, err)
+		return nil
+	}
+
+	membership := &models.ClusterSetMembership{
+		ClusterNames:    []string{},
+		BoundNamespaces: []string{},
+	}
+	for _, item := range r.clusters {
+		if !selector.Matches(labels.Set(item.Labels)) {
+			continue
+		}
+		cluster := convertManagedClusterToCluster(*item)
+		membership.ClusterNames = append(membership.ClusterNames, item.Name)
+		if cluster.Status == "Online" {
+			membership.OnlineCount++
+		} else {
+			membership.OfflineCount++
+		}
+		if r.expand {
+			membership.Clusters = append(membership.Clusters, cluster)
+		}
+	}
+	membership.ClusterCount = len(membership.ClusterNames)
+
+	for _, binding := range r.bindings {
+		if binding.Spec.ClusterSet == clusterSet.Name {
+			membership.BoundNamespaces = append(membership.BoundNamespaces, binding.Namespace)
+		}
+	}
+
+	// The cache has no ordering, keep the response stable
+	sort.Strings(membership.ClusterNames)
+	sort.Strings(membership.BoundNamespaces)
+	sort.Slice(membership.Clusters, func(i, j int) bool {
+		return membership.Clusters[i].Name < membership.Clusters[j].Name
+	})
+
+	return membership
+}
+
+// clusterSetSelector returns the label selector that picks the members of a cluster set
+func clusterSetSelector(clusterSet *clusterv1beta2.ManagedClusterSet) (labels.Selector, error) {
+	switch clusterSet.Spec.ClusterSelector.SelectorType {
+	case "", clusterv1beta2.ExclusiveClusterSetLabel:
+		return labels.SelectorFromSet(labels.Set{clusterv1beta2.ClusterSetLabel: clusterSet.Name}), nil
+	case clusterv1beta2.LabelSelector:
+		return metav1.LabelSelectorAsSelector(clusterSet.Spec.ClusterSelector.LabelSelector)
+	default:
+		return nil, fmt.Errorf("unsupported selector type %q", clusterSet.Spec.ClusterSelector.SelectorType)
+	}
+}
+
+// Helper function to convert a ManagedClusterSet resource to our model
+func convertClusterSetToModel(item *clusterv1beta2.ManagedClusterSet) models.ClusterSet {
+	// Extract the basic metadata
 	clusterSet := models.ClusterSet{
 		ID:                string(item.GetUID()),
 		Name:              item.GetName(),
@@ -96,6 +223,13 @@ func GetClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Cont
 		clusterSet.Spec.ClusterSelector.LabelSelector = &models.LabelSelector{
 			MatchLabels: item.Spec.ClusterSelector.LabelSelector.MatchLabels,
 		}
+		for _, expr := range item.Spec.ClusterSelector.LabelSelector.MatchExpressions {
+			clusterSet.Spec.ClusterSelector.LabelSelector.MatchExpressions = append(clusterSet.Spec.ClusterSelector.LabelSelector.MatchExpressions, models.MatchExpression{
+				Key:      expr.Key,
+				Operator: string(expr.Operator),
+				Values:   expr.Values,
+			})
+		}
 	}
 
 	// Extract status info
@@ -109,5 +243,20 @@ func GetClusterSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Cont
 		})
 	}
 
-	c.JSON(http.StatusOK, clusterSet)
+	return clusterSet
+}
+
+// clusterSetListFields are the fields the cluster set list can be filtered on
+var clusterSetListFields = func() listFields[models.ClusterSet] {
+	fieldFuncs := namedFields(func(clusterSet models.ClusterSet) string { return clusterSet.Name })
+	fieldFuncs["selectorType"] = func(clusterSet models.ClusterSet) []string {
+		return []string{clusterSet.Spec.ClusterSelector.SelectorType}
+	}
+	return fieldFuncs
+}()
+
+// clusterSetListSorts are the keys the cluster set list can be sorted by
+var clusterSetListSorts = listSorts[models.ClusterSet]{
+	"name":              func(clusterSet models.ClusterSet) string { return clusterSet.Name },
+	"creationTimestamp": func(clusterSet models.ClusterSet) string { return clusterSet.CreationTimestamp },
 }
@@ -2,14 +2,20 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetClusterSets(t *testing.T) {
@@ -72,3 +78,133 @@ func TestGetClusterSet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClusterSetsFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "global"}},
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{
+				ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+					SelectorType: clusterv1beta2.ExclusiveClusterSetLabel,
+				},
+			},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	GetClusterSets(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var clusterSets []models.ClusterSet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusterSets))
+	assert.Len(t, clusterSets, 2)
+	assert.Equal(t, "default", clusterSets[0].Name)
+	assert.Equal(t, "ExclusiveClusterSetLabel", clusterSets[0].Spec.ClusterSelector.SelectorType)
+	assert.Equal(t, "global", clusterSets[1].Name)
+}
+
+func TestGetClusterSetMembership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	available := func(status metav1.ConditionStatus) clusterv1.ManagedClusterStatus {
+		return clusterv1.ManagedClusterStatus{Conditions: []metav1.Condition{
+			{Type: clusterv1.ManagedClusterConditionAvailable, Status: status},
+		}}
+	}
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "global"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+				SelectorType:  clusterv1beta2.LabelSelector,
+				LabelSelector: &metav1.LabelSelector{},
+			}},
+		},
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+				SelectorType:  clusterv1beta2.LabelSelector,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			}},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev"}},
+			Status:     available(metav1.ConditionTrue),
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster2", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev", "env": "prod"}},
+			Status:     available(metav1.ConditionFalse),
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster3"}},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "app"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "dev"},
+		},
+	)
+
+	tests := []struct {
+		name             string
+		clusterSet       string
+		query            string
+		expectedClusters []string
+		expectedOnline   int
+		expectedOffline  int
+		expectedBound    []string
+		expectedEmbedded int
+	}{
+		{
+			name:             "global selects every cluster",
+			clusterSet:       "global",
+			expectedClusters: []string{"cluster1", "cluster2", "cluster3"},
+			expectedOnline:   1,
+			expectedOffline:  2,
+			expectedBound:    []string{},
+		},
+		{
+			name:             "exclusive cluster set label",
+			clusterSet:       "dev",
+			query:            "?expand=clusters",
+			expectedClusters: []string{"cluster1", "cluster2"},
+			expectedOnline:   1,
+			expectedOffline:  1,
+			expectedBound:    []string{"app"},
+			expectedEmbedded: 2,
+		},
+		{
+			name:             "label selector",
+			clusterSet:       "prod",
+			expectedClusters: []string{"cluster2"},
+			expectedOffline:  1,
+			expectedBound:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "name", Value: tt.clusterSet}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/clustersets/"+tt.clusterSet+tt.query, nil)
+
+			GetClusterSet(c, ocmClient, context.Background())
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var clusterSet models.ClusterSet
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusterSet))
+			require.NotNil(t, clusterSet.Membership)
+			assert.Equal(t, tt.expectedClusters, clusterSet.Membership.ClusterNames)
+			assert.Equal(t, len(tt.expectedClusters), clusterSet.Membership.ClusterCount)
+			assert.Equal(t, tt.expectedOnline, clusterSet.Membership.OnlineCount)
+			assert.Equal(t, tt.expectedOffline, clusterSet.Membership.OfflineCount)
+			assert.Equal(t, tt.expectedBound, clusterSet.Membership.BoundNamespaces)
+			assert.Len(t, clusterSet.Membership.Clusters, tt.expectedEmbedded)
+		})
+	}
+}
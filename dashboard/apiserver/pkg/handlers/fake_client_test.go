@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	fakeaddon "open-cluster-management.io/api/client/addon/clientset/versioned/fake"
+	addoninformers "open-cluster-management.io/api/client/addon/informers/externalversions"
+	fakecluster "open-cluster-management.io/api/client/cluster/clientset/versioned/fake"
+	clusterinformers "open-cluster-management.io/api/client/cluster/informers/externalversions"
+	fakework "open-cluster-management.io/api/client/work/clientset/versioned/fake"
+	workinformers "open-cluster-management.io/api/client/work/informers/externalversions"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+)
+
+// newFakeOCMClient builds an OCMClient backed by fake clientsets whose informer
+// caches are pre-populated with the given objects
+func newFakeOCMClient(t *testing.T, objects ...runtime.Object) *client.OCMClient {
+	t.Helper()
+
+	kubeClient := fakekube.NewSimpleClientset()
+	clusterClient := fakecluster.NewSimpleClientset()
+	addonClient := fakeaddon.NewSimpleClientset()
+	workClient := fakework.NewSimpleClientset()
+
+	ocmClient := &client.OCMClient{
+		KubernetesClient:       kubeClient,
+		ClusterClient:          clusterClient,
+		AddonClient:            addonClient,
+		WorkClient:             workClient,
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+		AddonInformerFactory:   addoninformers.NewSharedInformerFactory(addonClient, 0),
+		WorkInformerFactory:    workinformers.NewSharedInformerFactory(workClient, 0),
+		KubeInformerFactory:    client.NewKubeInformerFactory(kubeClient),
+	}
+
+	if err := ocmClient.AddIndexers(); err != nil {
+		t.Fatalf("failed to add indexers: %v", err)
+	}
+
+	for _, obj := range objects {
+		var err error
+		switch obj.(type) {
+		case *clusterv1.ManagedCluster:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Informer().GetStore().Add(obj)
+		case *clusterv1beta2.ManagedClusterSet:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Informer().GetStore().Add(obj)
+		case *clusterv1beta2.ManagedClusterSetBinding:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Informer().GetStore().Add(obj)
+		case *clusterv1beta1.Placement:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer().GetStore().Add(obj)
+		case *clusterv1beta1.PlacementDecision:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer().GetStore().Add(obj)
+		case *clusterv1alpha1.AddOnPlacementScore:
+			err = ocmClient.ClusterInformerFactory.Cluster().V1alpha1().AddOnPlacementScores().Informer().GetStore().Add(obj)
+		case *addonv1alpha1.ManagedClusterAddOn:
+			err = ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Informer().GetStore().Add(obj)
+		case *addonv1alpha1.ClusterManagementAddOn:
+			err = ocmClient.AddonInformerFactory.Addon().V1alpha1().ClusterManagementAddOns().Informer().GetStore().Add(obj)
+		case *addonv1alpha1.AddOnDeploymentConfig:
+			err = ocmClient.AddonInformerFactory.Addon().V1alpha1().AddOnDeploymentConfigs().Informer().GetStore().Add(obj)
+		case *workv1.ManifestWork:
+			err = ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Informer().GetStore().Add(obj)
+		case *workv1alpha1.ManifestWorkReplicaSet:
+			err = ocmClient.WorkInformerFactory.Work().V1alpha1().ManifestWorkReplicaSets().Informer().GetStore().Add(obj)
+		case *certificatesv1.CertificateSigningRequest:
+			err = ocmClient.KubeInformerFactory.Certificates().V1().CertificateSigningRequests().Informer().GetStore().Add(obj)
+		default:
+			t.Fatalf("unsupported object type %T", obj)
+		}
+		if err != nil {
+			t.Fatalf("failed to add %T to informer store: %v", obj, err)
+		}
+	}
+
+	return ocmClient
+}
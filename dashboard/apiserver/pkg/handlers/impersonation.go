@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+)
+
+// userClient returns clients that impersonate the caller so that writes are
+// authorized against the caller's own RBAC. It writes an error response and
+// returns false when the clients cannot be created.
+func userClient(c *gin.Context, ocmClient *client.OCMClient) (*client.OCMClient, bool) {
+	if ocmClient == nil || ocmClient.ClusterClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return nil, false
+	}
+
+	impersonated, err := ocmClient.ForUser(auth.UserFromContext(c))
+	if err != nil {
+		log.Printf("Failed to create impersonating client: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create client for user: " + err.Error()})
+		return nil, false
+	}
+	return impersonated, true
+}
+
+// respondKubeError writes an error from the Kubernetes API with its original
+// status code, so that forbidden and not found errors reach the client as such
+func respondKubeError(c *gin.Context, err error) {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		c.JSON(int(status.Status().Code), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// inventoryDimension is a cluster property the inventory can be grouped by
+type inventoryDimension struct {
+	name string
+	kind string
+	key  string
+}
+
+// inventoryTotals accumulates the counts and resources of a set of clusters
+type inventoryTotals struct {
+	clusters          int
+	available         int
+	capacityCPU       resource.Quantity
+	capacityMemory    resource.Quantity
+	allocatableCPU    resource.Quantity
+	allocatableMemory resource.Quantity
+}
+
+// GetInventory aggregates the clusters the caller may read. groupBy takes a
+// comma separated list of dimensions and groups clusters by the combination
+// of their values, facets counts clusters by the values of each dimension on
+// its own. A dimension is label:<key>, claim:<name>, version or status.
+// labelSelector limits the clusters that are aggregated.
+func GetInventory(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	groupBy, err := parseInventoryDimensions(c.Query("groupBy"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	facets, err := parseInventoryDimensions(c.Query("facets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	selector := labels.Everything()
+	if raw := c.Query("labelSelector"); raw != "" {
+		if selector, err = labels.Parse(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid labelSelector %q: %v", raw, err)})
+			return
+		}
+	}
+
+	clusterList, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().List(selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource)
+
+	var total inventoryTotals
+	groups := map[string]*inventoryTotals{}
+	groupValues := map[string]map[string]string{}
+	buckets := make([]map[string]*inventoryTotals, len(facets))
+	for i := range buckets {
+		buckets[i] = map[string]*inventoryTotals{}
+	}
+
+	for _, item := range clusterList {
+		if !access.allowed("", item.Name) {
+			continue
+		}
+		cluster := convertManagedClusterToCluster(*item)
+		total.add(item, cluster)
+
+		if len(groupBy) > 0 {
+			values := map[string]string{}
+			keys := make([]string, 0, len(groupBy))
+			for _, dimension := range groupBy {
+				value := dimension.value(cluster)
+				values[dimension.name] = value
+				keys = append(keys, value)
+			}
+			key := strings.Join(keys, "\x00")
+			if groups[key] == nil {
+				groups[key] = &inventoryTotals{}
+				groupValues[key] = values
+			}
+			groups[key].add(item, cluster)
+		}
+
+		for i, dimension := range facets {
+			value := dimension.value(cluster)
+			if buckets[i][value] == nil {
+				buckets[i][value] = &inventoryTotals{}
+			}
+			buckets[i][value].add(item, cluster)
+		}
+	}
+
+	inventory := models.Inventory{Summary: total.summary()}
+
+	if len(groupBy) > 0 {
+		inventory.Groups = []models.InventoryGroup{}
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sortInventoryKeys(keys, groups)
+		for _, dimension := range groupBy {
+			inventory.GroupBy = append(inventory.GroupBy, dimension.name)
+		}
+		for _, key := range keys {
+			inventory.Groups = append(inventory.Groups, models.InventoryGroup{
+				Values:           groupValues[key],
+				InventorySummary: groups[key].summary(),
+			})
+		}
+	}
+
+	for i, dimension := range facets {
+		facet := models.InventoryFacet{Dimension: dimension.name, Buckets: []models.InventoryBucket{}}
+		values := make([]string, 0, len(buckets[i]))
+		for value := range buckets[i] {
+			values = append(values, value)
+		}
+		sortInventoryKeys(values, buckets[i])
+		for _, value := range values {
+			facet.Buckets = append(facet.Buckets, models.InventoryBucket{
+				Value:            value,
+				InventorySummary: buckets[i][value].summary(),
+			})
+		}
+		inventory.Facets = append(inventory.Facets, facet)
+	}
+
+	c.JSON(http.StatusOK, inventory)
+}
+
+// parseInventoryDimensions reads a comma separated list of dimensions
+func parseInventoryDimensions(raw string) ([]inventoryDimension, error) {
+	var dimensions []inventoryDimension
+	if raw == "" {
+		return dimensions, nil
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		kind, key, keyed := strings.Cut(name, ":")
+		switch {
+		case (kind == "label" || kind == "claim") && key != "":
+		case (kind == "version" || kind == "status") && !keyed:
+		default:
+			return nil, fmt.Errorf("invalid dimension %q, must be label:<key>, claim:<name>, version or status", name)
+		}
+		dimensions = append(dimensions, inventoryDimension{name: name, kind: kind, key: key})
+	}
+	return dimensions, nil
+}
+
+// value returns the cluster's value for the dimension, empty when it has none
+func (d inventoryDimension) value(cluster models.Cluster) string {
+	switch d.kind {
+	case "label":
+		return cluster.Labels[d.key]
+	case "claim":
+		for _, claim := range cluster.ClusterClaims {
+			if claim.Name == d.key {
+				return claim.Value
+			}
+		}
+	case "version":
+		return cluster.Version
+	case "status":
+		return cluster.Status
+	}
+	return ""
+}
+
+// add counts the cluster and adds its CPU and memory to the totals
+func (t *inventoryTotals) add(item *clusterv1.ManagedCluster, cluster models.Cluster) {
+	t.clusters++
+	if cluster.Status == "Online" {
+		t.available++
+	}
+	if q, ok := item.Status.Capacity[clusterv1.ResourceCPU]; ok {
+		t.capacityCPU.Add(q)
+	}
+	if q, ok := item.Status.Capacity[clusterv1.ResourceMemory]; ok {
+		t.capacityMemory.Add(q)
+	}
+	if q, ok := item.Status.Allocatable[clusterv1.ResourceCPU]; ok {
+		t.allocatableCPU.Add(q)
+	}
+	if q, ok := item.Status.Allocatable[clusterv1.ResourceMemory]; ok {
+		t.allocatableMemory.Add(q)
+	}
+}
+
+// summary converts the totals to the API model
+func (t *inventoryTotals) summary() models.InventorySummary {
+	return models.InventorySummary{
+		Clusters:  t.clusters,
+		Available: t.available,
+		Capacity: models.InventoryResources{
+			CPU:           t.capacityCPU.String(),
+			CPUMillicores: t.capacityCPU.MilliValue(),
+			Memory:        t.capacityMemory.String(),
+			MemoryBytes:   t.capacityMemory.Value(),
+		},
+		Allocatable: models.InventoryResources{
+			CPU:           t.allocatableCPU.String(),
+			CPUMillicores: t.allocatableCPU.MilliValue(),
+			Memory:        t.allocatableMemory.String(),
+			MemoryBytes:   t.allocatableMemory.Value(),
+		},
+	}
+}
+
+// sortInventoryKeys orders groups by cluster count, largest first, then by key
+func sortInventoryKeys(keys []string, totals map[string]*inventoryTotals) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := totals[keys[i]], totals[keys[j]]
+		if a.clusters != b.clusters {
+			return a.clusters > b.clusters
+		}
+		return keys[i] < keys[j]
+	})
+}
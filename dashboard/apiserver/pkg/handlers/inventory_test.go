@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newInventoryCluster returns a cluster with a platform claim, a Kubernetes
+// version and cpu and memory capacity, allocatable is half the capacity
+func newInventoryCluster(name, env, platform, version string, available bool, cpu, memory string) *clusterv1.ManagedCluster {
+	status := metav1.ConditionFalse
+	if available {
+		status = metav1.ConditionTrue
+	}
+	cpuQuantity, memoryQuantity := resource.MustParse(cpu), resource.MustParse(memory)
+	halfCPU := resource.NewMilliQuantity(cpuQuantity.MilliValue()/2, resource.DecimalSI)
+	halfMemory := resource.NewQuantity(memoryQuantity.Value()/2, resource.BinarySI)
+	return &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": env}},
+		Status: clusterv1.ManagedClusterStatus{
+			Conditions: []metav1.Condition{{Type: clusterv1.ManagedClusterConditionAvailable, Status: status}},
+			Version:    clusterv1.ManagedClusterVersion{Kubernetes: version},
+			ClusterClaims: []clusterv1.ManagedClusterClaim{
+				{Name: "platform.open-cluster-management.io", Value: platform},
+			},
+			Capacity: clusterv1.ResourceList{
+				clusterv1.ResourceCPU:    cpuQuantity,
+				clusterv1.ResourceMemory: memoryQuantity,
+			},
+			Allocatable: clusterv1.ResourceList{
+				clusterv1.ResourceCPU:    *halfCPU,
+				clusterv1.ResourceMemory: *halfMemory,
+			},
+		},
+	}
+}
+
+func newInventoryClient(t *testing.T) *client.OCMClient {
+	return newFakeOCMClient(t,
+		newInventoryCluster("cluster1", "prod", "AWS", "v1.30.1", true, "8", "32Gi"),
+		newInventoryCluster("cluster2", "prod", "AWS", "v1.29.4", false, "4", "16Gi"),
+		newInventoryCluster("cluster3", "dev", "GCP", "v1.30.1", true, "2500m", "8Gi"),
+		// A cluster that has not reported any status yet
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster4", Labels: map[string]string{"env": "dev"}}},
+	)
+}
+
+func runGetInventory(ocmClient *client.OCMClient, query string, user *authv1.UserInfo) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/inventory"+query, nil)
+	if user != nil {
+		auth.SetUser(c, user)
+	}
+	GetInventory(c, ocmClient, c.Request.Context())
+	return w
+}
+
+func TestGetInventorySummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runGetInventory(newInventoryClient(t), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var inventory models.Inventory
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inventory))
+	assert.Equal(t, models.InventorySummary{
+		Clusters:  4,
+		Available: 2,
+		Capacity: models.InventoryResources{
+			CPU:           "14500m",
+			CPUMillicores: 14500,
+			Memory:        "56Gi",
+			MemoryBytes:   56 << 30,
+		},
+		Allocatable: models.InventoryResources{
+			CPU:           "7250m",
+			CPUMillicores: 7250,
+			Memory:        "28Gi",
+			MemoryBytes:   28 << 30,
+		},
+	}, inventory.Summary)
+	assert.Empty(t, inventory.Groups)
+	assert.Empty(t, inventory.Facets)
+}
+
+func TestGetInventoryGroupsAndFacets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runGetInventory(newInventoryClient(t), "?groupBy=claim:platform.open-cluster-management.io,status&facets=version,label:env", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var inventory models.Inventory
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inventory))
+	assert.Equal(t, []string{"claim:platform.open-cluster-management.io", "status"}, inventory.GroupBy)
+
+	groups := []map[string]string{}
+	for _, group := range inventory.Groups {
+		groups = append(groups, group.Values)
+		assert.Equal(t, 1, group.Clusters)
+	}
+	assert.Equal(t, []map[string]string{
+		{"claim:platform.open-cluster-management.io": "", "status": "Unknown"},
+		{"claim:platform.open-cluster-management.io": "AWS", "status": "Offline"},
+		{"claim:platform.open-cluster-management.io": "AWS", "status": "Online"},
+		{"claim:platform.open-cluster-management.io": "GCP", "status": "Online"},
+	}, groups)
+	assert.Equal(t, int64(8000), inventory.Groups[2].Capacity.CPUMillicores)
+
+	require.Len(t, inventory.Facets, 2)
+	versions := inventory.Facets[0]
+	assert.Equal(t, "version", versions.Dimension)
+	require.Len(t, versions.Buckets, 3)
+	// Buckets are ordered by cluster count
+	assert.Equal(t, "v1.30.1", versions.Buckets[0].Value)
+	assert.Equal(t, 2, versions.Buckets[0].Clusters)
+	assert.Equal(t, 2, versions.Buckets[0].Available)
+	assert.Equal(t, "10500m", versions.Buckets[0].Capacity.CPU)
+	assert.Equal(t, "40Gi", versions.Buckets[0].Capacity.Memory)
+
+	envs := inventory.Facets[1]
+	assert.Equal(t, "label:env", envs.Dimension)
+	require.Len(t, envs.Buckets, 2)
+	assert.Equal(t, "dev", envs.Buckets[0].Value)
+	assert.Equal(t, "prod", envs.Buckets[1].Value)
+	assert.Equal(t, int64(24<<30), envs.Buckets[1].Allocatable.MemoryBytes)
+}
+
+func TestGetInventoryFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedClusters int
+	}{
+		{name: "label selector", query: "?labelSelector=env%3Dprod", expectedStatus: http.StatusOK, expectedClusters: 2},
+		{name: "invalid label selector", query: "?labelSelector=env%3D%3D%3D", expectedStatus: http.StatusBadRequest},
+		{name: "unknown dimension", query: "?groupBy=region", expectedStatus: http.StatusBadRequest},
+		{name: "label without key", query: "?facets=label:", expectedStatus: http.StatusBadRequest},
+		{name: "status with key", query: "?facets=status:Online", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runGetInventory(newInventoryClient(t), tt.query, nil)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var inventory models.Inventory
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inventory))
+			assert.Equal(t, tt.expectedClusters, inventory.Summary.Clusters)
+		})
+	}
+}
+
+func TestGetInventoryFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The tenant may not read any cluster
+	ocmClient := newInventoryClient(t)
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	w := runGetInventory(ocmClient, "?facets=status", &authv1.UserInfo{Username: "tenant"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var inventory models.Inventory
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inventory))
+	assert.Equal(t, 0, inventory.Summary.Clusters)
+	assert.Equal(t, "0", inventory.Summary.Capacity.CPU)
+	require.Len(t, inventory.Facets, 1)
+	assert.Empty(t, inventory.Facets[0].Buckets)
+}
+
+func TestGetInventoryRequiresClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runGetInventory(nil, "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
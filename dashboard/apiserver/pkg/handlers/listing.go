@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// listFields are the fields a list can be filtered on with fieldSelector. An
+// item matches field=value when any of its values for the field is value.
+type listFields[T any] map[string]func(T) []string
+
+// listSorts are the keys a list can be sorted by
+type listSorts[T any] map[string]func(T) string
+
+// identityFields identify an item within a list, in order. Every list has a
+// name field, namespaced lists a namespace field and the workload search group
+// and kind fields too.
+var identityFields = []string{"group", "kind", "namespace", "name"}
+
+// listQuery holds the query parameters every list endpoint accepts:
+// labelSelector, fieldSelector, sort (a key, "-" prefixed for descending),
+// envelope, limit and continue. The label selector is applied when listing,
+// the rest to the converted items.
+//
+// Lists are served from the informer caches and limit and continue are
+// deliberately not passed to the API server: its pages could not be filtered
+// or sorted on the converted fields, its continue tokens expire with etcd
+// compaction, and every page would be a request to the API server instead of
+// a cache read. The dashboard pages the items itself and its continue tokens
+// are its own.
+type listQuery[T any] struct {
+	selector     labels.Selector
+	requirements fields.Requirements
+	fields       listFields[T]
+	sortKey      func(T) string
+	descending   bool
+	limit        int
+	after        *continueToken
+	envelope     bool
+	fingerprint  string
+}
+
+// continueToken holds the sort key and identity of the last item of a page.
+// The next page starts after that position, so items added or removed in the
+// meantime do not shift it.
+type continueToken struct {
+	SortKey     string `json:"k,omitempty"`
+	ID          string `json:"id"`
+	Fingerprint string `json:"f"`
+}
+
+// parseListQuery reads the list query parameters, rejecting fields and sort
+// keys the list does not support
+func parseListQuery[T any](c *gin.Context, fieldFuncs listFields[T], sorts listSorts[T]) (*listQuery[T], error) {
+	query := &listQuery[T]{selector: labels.Everything(), fields: fieldFuncs}
+
+	if raw := c.Query("labelSelector"); raw != "" {
+		selector, err := labels.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labelSelector %q: %w", raw, err)
+		}
+		query.selector = selector
+	}
+
+	if raw := c.Query("fieldSelector"); raw != "" {
+		selector, err := fields.ParseSelector(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fieldSelector %q: %w", raw, err)
+		}
+		for _, requirement := range selector.Requirements() {
+			if _, ok := fieldFuncs[requirement.Field]; !ok {
+				return nil, fmt.Errorf("unsupported field %q in fieldSelector, must be one of %s",
+					requirement.Field, strings.Join(slices.Sorted(maps.Keys(fieldFuncs)), ", "))
+			}
+		}
+		query.requirements = selector.Requirements()
+	}
+
+	if raw := c.Query("sort"); raw != "" {
+		key := strings.TrimPrefix(raw, "-")
+		sortKey, ok := sorts[key]
+		if !ok {
+			return nil, fmt.Errorf("unsupported sort %q, must be one of %s", key, strings.Join(slices.Sorted(maps.Keys(sorts)), ", "))
+		}
+		query.sortKey = sortKey
+		query.descending = key != raw
+	}
+
+	if raw := c.Query("envelope"); raw != "" {
+		envelope, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid envelope %q, must be true or false", raw)
+		}
+		query.envelope = envelope
+	}
+
+	// Pages are only returned in the envelope, which carries the continue token
+	if !query.envelope && (c.Query("limit") != "" || c.Query("continue") != "") {
+		return nil, fmt.Errorf("limit and continue require envelope=true")
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit %q, must be a positive integer", raw)
+		}
+		query.limit = limit
+	}
+
+	query.fingerprint = listFingerprint(c)
+	if raw := c.Query("continue"); raw != "" {
+		after, err := decodeContinue(raw, query.fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		query.after = after
+	}
+
+	return query, nil
+}
+
+// selectorWith adds the caller's label selector to the labels the handler
+// itself selects on
+func (q *listQuery[T]) selectorWith(set labels.Set) labels.Selector {
+	selector := labels.SelectorFromSet(set)
+	if requirements, selectable := q.selector.Requirements(); selectable {
+		selector = selector.Add(requirements...)
+	}
+	return selector
+}
+
+// matches reports whether the item passes the field selector
+func (q *listQuery[T]) matches(item T) bool {
+	for _, requirement := range q.requirements {
+		found := slices.Contains(q.fields[requirement.Field](item), requirement.Value)
+		if found == (requirement.Operator == selection.NotEquals) {
+			return false
+		}
+	}
+	return true
+}
+
+// identity returns the identity fields of an item joined so that comparing
+// two identities compares their fields in order
+func (q *listQuery[T]) identity(item T) string {
+	parts := make([]string, 0, len(identityFields))
+	for _, field := range identityFields {
+		if fieldFunc, ok := q.fields[field]; ok {
+			parts = append(parts, strings.Join(fieldFunc(item), ","))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// compare orders two positions by sort key, in the requested direction, and
+// then by identity
+func (q *listQuery[T]) compare(keyA, idA, keyB, idB string) int {
+	if c := strings.Compare(keyA, keyB); c != 0 {
+		if q.descending {
+			return -c
+		}
+		return c
+	}
+	return strings.Compare(idA, idB)
+}
+
+// respond filters, sorts and pages the items, which are already in the
+// list's default order. With envelope=true they are returned in a
+// ListResponse, otherwise as a plain array.
+func (q *listQuery[T]) respond(c *gin.Context, items []T) {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if q.matches(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	// Pages need a total order, ties on the sort key and lists without a
+	// sort are ordered by identity
+	sortKey := q.sortKey
+	if sortKey == nil {
+		sortKey = func(T) string { return "" }
+	}
+	if q.sortKey != nil || q.envelope {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return q.compare(sortKey(matched[i]), q.identity(matched[i]), sortKey(matched[j]), q.identity(matched[j])) < 0
+		})
+	}
+
+	if !q.envelope {
+		c.JSON(http.StatusOK, matched)
+		return
+	}
+
+	response := models.ListResponse[T]{Total: len(matched)}
+	start := 0
+	if q.after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return q.compare(sortKey(matched[i]), q.identity(matched[i]), q.after.SortKey, q.after.ID) > 0
+		})
+	}
+	end := len(matched)
+	if q.limit > 0 && start+q.limit < end {
+		end = start + q.limit
+		last := matched[end-1]
+		response.Continue = encodeContinue(continueToken{SortKey: sortKey(last), ID: q.identity(last), Fingerprint: q.fingerprint})
+	}
+	response.Items = append(make([]T, 0, end-start), matched[start:end]...)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// listFingerprint identifies the list and the query that continue tokens
+// belong to, the page size may change between pages
+func listFingerprint(c *gin.Context) string {
+	path := ""
+	if c.Request != nil {
+		path = c.Request.URL.Path
+	}
+	hash := fnv.New64a()
+	for _, part := range []string{path, c.Query("labelSelector"), c.Query("fieldSelector"), c.Query("sort")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return strconv.FormatUint(hash.Sum64(), 36)
+}
+
+// encodeContinue returns the token for the page after the given position
+func encodeContinue(token continueToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeContinue reads the position from a token, which must have been
+// issued for the same list and query
+func decodeContinue(raw, fingerprint string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	token := &continueToken{}
+	if err := json.Unmarshal(data, token); err != nil || token.ID == "" {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	if token.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("continue token was issued for a different query")
+	}
+	return token, nil
+}
+
+// namedFields are the fields of items identified by a name alone
+func namedFields[T any](name func(T) string) listFields[T] {
+	return listFields[T]{
+		"name": func(item T) []string { return []string{name(item)} },
+	}
+}
+
+// namespacedFields are the fields of items identified by namespace and name
+func namespacedFields[T any](namespace, name func(T) string) listFields[T] {
+	fieldFuncs := namedFields(name)
+	fieldFuncs["namespace"] = func(item T) []string { return []string{namespace(item)} }
+	return fieldFuncs
+}
+
+// conditionField returns the status of a condition as a field value, Unknown
+// when the condition is not reported
+func conditionField(conditions []models.Condition, conditionType string) []string {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return []string{condition.Status}
+		}
+	}
+	return []string{string(metav1.ConditionUnknown)}
+}
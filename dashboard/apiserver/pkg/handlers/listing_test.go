@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+var testListClusters = []models.Cluster{
+	{Name: "cluster-a", Status: "Online", CreationTimestamp: "2026-01-03T00:00:00Z"},
+	{Name: "cluster-b", Status: "Offline", CreationTimestamp: "2026-01-01T00:00:00Z"},
+	{Name: "cluster-c", Status: "Online", CreationTimestamp: "2026-01-02T00:00:00Z"},
+}
+
+var testListSorts = listSorts[models.Cluster]{
+	"name":              func(cluster models.Cluster) string { return cluster.Name },
+	"creationTimestamp": func(cluster models.Cluster) string { return cluster.CreationTimestamp },
+}
+
+// runListQuery parses the query for path and responds with the test clusters
+func runListQuery(path string) *httptest.ResponseRecorder {
+	return runListQueryOn(path, testListClusters)
+}
+
+// runListQueryOn parses the query for path and responds with the given clusters
+func runListQueryOn(path string, clusters []models.Cluster) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, path, nil)
+
+	fieldFuncs := namedFields(func(cluster models.Cluster) string { return cluster.Name })
+	fieldFuncs["status"] = func(cluster models.Cluster) []string { return []string{cluster.Status} }
+	query, err := parseListQuery(c, fieldFuncs, testListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return w
+	}
+	query.respond(c, clusters)
+	return w
+}
+
+func clusterNames(clusters []models.Cluster) []string {
+	names := []string{}
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+func TestListQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{
+			name:           "no query keeps the default order",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-a", "cluster-b", "cluster-c"},
+		},
+		{
+			name:           "field equals",
+			query:          "?fieldSelector=status%3DOnline",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-a", "cluster-c"},
+		},
+		{
+			name:           "field not equals",
+			query:          "?fieldSelector=status!%3DOnline",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-b"},
+		},
+		{
+			name:           "several fields",
+			query:          "?fieldSelector=status%3DOnline,name!%3Dcluster-a",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-c"},
+		},
+		{
+			name:           "sort ascending",
+			query:          "?sort=creationTimestamp",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-b", "cluster-c", "cluster-a"},
+		},
+		{
+			name:           "sort descending",
+			query:          "?sort=-name",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"cluster-c", "cluster-b", "cluster-a"},
+		},
+		{
+			name:           "unsupported field",
+			query:          "?fieldSelector=version%3D1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid field selector",
+			query:          "?fieldSelector=status%3D%3D%3D",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported sort",
+			query:          "?sort=status",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid label selector",
+			query:          "?labelSelector=app%3D%3D%3D",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "zero limit",
+			query:          "?envelope=true&limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid continue token",
+			query:          "?envelope=true&continue=not-a-token",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit without envelope",
+			query:          "?limit=2",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid envelope",
+			query:          "?envelope=yes",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runListQuery("/api/clusters" + tt.query)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var clusters []models.Cluster
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusters))
+			assert.Equal(t, tt.expectedNames, clusterNames(clusters))
+		})
+	}
+}
+
+func TestListQueryPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runListQuery("/api/clusters?envelope=true&sort=-name&limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page models.ListResponse[models.Cluster]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"cluster-c", "cluster-b"}, clusterNames(page.Items))
+	assert.Equal(t, 3, page.Total)
+	require.NotEmpty(t, page.Continue)
+	token := page.Continue
+
+	// The last page carries no continue token
+	w = runListQuery("/api/clusters?envelope=true&sort=-name&limit=2&continue=" + token)
+	require.Equal(t, http.StatusOK, w.Code)
+	page = models.ListResponse[models.Cluster]{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"cluster-a"}, clusterNames(page.Items))
+	assert.Equal(t, 3, page.Total)
+	assert.Empty(t, page.Continue)
+
+	// A token only continues the list and query it was issued for
+	w = runListQuery("/api/clusters?envelope=true&sort=name&limit=2&continue=" + token)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = runListQuery("/api/placements?envelope=true&sort=-name&limit=2&continue=" + token)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListQueryPagesAfterChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runListQuery("/api/clusters?envelope=true&sort=creationTimestamp&limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var page models.ListResponse[models.Cluster]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"cluster-b", "cluster-c"}, clusterNames(page.Items))
+	require.NotEmpty(t, page.Continue)
+
+	// The first cluster is deleted and an older one created before the next
+	// page, which still starts after the last cluster seen
+	changed := []models.Cluster{
+		{Name: "cluster-0", CreationTimestamp: "2025-12-31T00:00:00Z"},
+		testListClusters[0], testListClusters[2],
+		{Name: "cluster-d", CreationTimestamp: "2026-01-02T00:00:00Z"},
+	}
+	w = runListQueryOn("/api/clusters?envelope=true&sort=creationTimestamp&limit=2&continue="+page.Continue, changed)
+	require.Equal(t, http.StatusOK, w.Code)
+	page = models.ListResponse[models.Cluster]{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"cluster-d", "cluster-a"}, clusterNames(page.Items))
+	assert.Equal(t, 4, page.Total)
+	assert.Empty(t, page.Continue)
+}
+
+func TestListQueryPagesEmptyList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runListQuery("/api/clusters?envelope=true&fieldSelector=status%3DUnknown&limit=10")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items": [], "total": 0}`, w.Body.String())
+}
+
+func TestListQueryEnvelopeWithoutLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The envelope alone returns every match, ordered by identity
+	w := runListQuery("/api/clusters?envelope=true")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page models.ListResponse[models.Cluster]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"cluster-a", "cluster-b", "cluster-c"}, clusterNames(page.Items))
+	assert.Equal(t, 3, page.Total)
+	assert.Empty(t, page.Continue)
+}
+
+func TestListQuerySelectorWith(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/placementdecisions?labelSelector=tier%3Dgold", nil)
+
+	query, err := parseListQuery(c, listFields[models.Cluster]{}, testListSorts)
+	require.NoError(t, err)
+
+	selector := query.selectorWith(labels.Set{"placement": "p1"})
+	assert.True(t, selector.Matches(labels.Set{"placement": "p1", "tier": "gold"}))
+	assert.False(t, selector.Matches(labels.Set{"placement": "p1"}))
+	assert.False(t, selector.Matches(labels.Set{"placement": "p2", "tier": "gold"}))
+}
+
+func TestGetClustersFieldSelector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{"env": "prod"}}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2", Labels: map[string]string{"env": "dev"}}},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster3", Labels: map[string]string{"env": "prod"}},
+			Spec:       clusterv1.ManagedClusterSpec{HubAcceptsClient: true},
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster4", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "edge"}}},
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "edge"}},
+		&clusterv1beta2.ManagedClusterSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "production"},
+			Spec: clusterv1beta2.ManagedClusterSetSpec{ClusterSelector: clusterv1beta2.ManagedClusterSelector{
+				SelectorType:  clusterv1beta2.LabelSelector,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			}},
+		},
+	)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedNames []string
+	}{
+		{
+			name:          "label selector",
+			query:         "?labelSelector=env%3Dprod",
+			expectedNames: []string{"cluster1", "cluster3"},
+		},
+		{
+			name:          "label and field selector",
+			query:         "?labelSelector=env%3Dprod&fieldSelector=hubAccepted%3Dtrue",
+			expectedNames: []string{"cluster3"},
+		},
+		{
+			name:          "exclusive cluster set",
+			query:         "?fieldSelector=clusterset%3Dedge",
+			expectedNames: []string{"cluster4"},
+		},
+		{
+			name:          "label selector cluster set",
+			query:         "?fieldSelector=clusterset%3Dproduction",
+			expectedNames: []string{"cluster1", "cluster3"},
+		},
+		{
+			name:          "sorted descending",
+			query:         "?sort=-name",
+			expectedNames: []string{"cluster4", "cluster3", "cluster2", "cluster1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/clusters"+tt.query, nil)
+			GetClusters(c, ocmClient, c.Request.Context())
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var clusters []models.Cluster
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusters))
+			assert.Equal(t, tt.expectedNames, clusterNames(clusters))
+		})
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
+
+	workv1 "open-cluster-management.io/api/work/v1"
 )
 
 // GetManifestWorks retrieves all ManifestWorks for a specific namespace
@@ -18,89 +21,42 @@ func GetManifestWorks(c *gin.Context, ocmClient *client.OCMClient, ctx context.C
 	namespace := c.Param("namespace")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Get the manifest works for the specified namespace
-	list, err := ocmClient.WorkClient.WorkV1().ManifestWorks(namespace).List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, manifestWorkListFields, manifestWorkListSorts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Convert to our simplified ManifestWork models
-	manifestWorks := make([]models.ManifestWork, 0, len(list.Items))
-	for _, item := range list.Items {
-		manifestWork := models.ManifestWork{
-			ID:                string(item.GetUID()),
-			Name:              item.GetName(),
-			Namespace:         item.GetNamespace(),
-			Labels:            item.GetLabels(),
-			CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
-		}
-
-		// Process manifests
-		if len(item.Spec.Workload.Manifests) > 0 {
-			manifestWork.Manifests = make([]models.Manifest, len(item.Spec.Workload.Manifests))
-			for i, manifest := range item.Spec.Workload.Manifests {
-				// Convert raw bytes to map[string]interface{}
-				var rawObj map[string]interface{}
-				if err := json.Unmarshal(manifest.Raw, &rawObj); err == nil {
-					manifestWork.Manifests[i] = models.Manifest{
-						RawExtension: rawObj,
-					}
-				}
-			}
-		}
-
-		// Extract conditions
-		for _, condition := range item.Status.Conditions {
-			manifestWork.Conditions = append(manifestWork.Conditions, models.Condition{
-				Type:               string(condition.Type),
-				Status:             string(condition.Status),
-				LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-				Reason:             condition.Reason,
-				Message:            condition.Message,
-			})
-		}
+	// List the manifest works for the specified namespace from the informer cache
+	list, err := ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Lister().ManifestWorks(namespace).List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Process resource status
-		if len(item.Status.ResourceStatus.Manifests) > 0 {
-			manifestWork.ResourceStatus.Manifests = make([]models.ManifestCondition, len(item.Status.ResourceStatus.Manifests))
-			for i, manifestStatus := range item.Status.ResourceStatus.Manifests {
-				manifestCondition := models.ManifestCondition{
-					ResourceMeta: models.ManifestResourceMeta{
-						Ordinal:   manifestStatus.ResourceMeta.Ordinal,
-						Group:     manifestStatus.ResourceMeta.Group,
-						Version:   manifestStatus.ResourceMeta.Version,
-						Kind:      manifestStatus.ResourceMeta.Kind,
-						Resource:  manifestStatus.ResourceMeta.Resource,
-						Name:      manifestStatus.ResourceMeta.Name,
-						Namespace: manifestStatus.ResourceMeta.Namespace,
-					},
-				}
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource)
 
-				// Process conditions for this manifest
-				for _, condition := range manifestStatus.Conditions {
-					manifestCondition.Conditions = append(manifestCondition.Conditions, models.Condition{
-						Type:               string(condition.Type),
-						Status:             string(condition.Status),
-						LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
-						Reason:             condition.Reason,
-						Message:            condition.Message,
-					})
-				}
-
-				manifestWork.ResourceStatus.Manifests[i] = manifestCondition
-			}
+	// Convert to our simplified ManifestWork models
+	manifestWorks := make([]models.ManifestWork, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
 		}
-
+		manifestWork := convertManifestWorkToModel(item)
 		manifestWorks = append(manifestWorks, manifestWork)
 	}
 
-	c.JSON(http.StatusOK, manifestWorks)
+	sort.Slice(manifestWorks, func(i, j int) bool {
+		return manifestWorks[i].Name < manifestWorks[j].Name
+	})
+
+	query.respond(c, manifestWorks)
 }
 
 // GetManifestWork retrieves a specific ManifestWork by name in a namespace
@@ -109,19 +65,32 @@ func GetManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil {
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
 		return
 	}
 
-	// Get the manifest work by name
-	item, err := ocmClient.WorkClient.WorkV1().ManifestWorks(namespace).Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource).allowed(namespace, name) {
+		respondForbidden(c, manifestWorksResource, name)
+		return
+	}
+
+	// Get the manifest work by name from the informer cache
+	item, err := ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Lister().ManifestWorks(namespace).Get(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Convert to our simplified ManifestWork model
+	manifestWork := convertManifestWorkToModel(item)
+
+	c.JSON(http.StatusOK, manifestWork)
+}
+
+// Helper function to convert a ManifestWork resource to our model
+func convertManifestWorkToModel(item *workv1.ManifestWork) models.ManifestWork {
 	manifestWork := models.ManifestWork{
 		ID:                string(item.GetUID()),
 		Name:              item.GetName(),
@@ -144,6 +113,19 @@ func GetManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 		}
 	}
 
+	manifestWork.DeleteOption = convertDeleteOptionToModel(item.Spec.DeleteOption)
+	for _, config := range item.Spec.ManifestConfigs {
+		manifestWork.ManifestConfigs = append(manifestWork.ManifestConfigs, convertManifestConfigToModel(config))
+	}
+	if executor := item.Spec.Executor; executor != nil {
+		manifestWork.Executor = &models.ManifestWorkExecutor{
+			Subject: models.ManifestWorkExecutorSubject{Type: string(executor.Subject.Type)},
+		}
+		if sa := executor.Subject.ServiceAccount; sa != nil {
+			manifestWork.Executor.Subject.ServiceAccount = &models.ServiceAccountReference{Namespace: sa.Namespace, Name: sa.Name}
+		}
+	}
+
 	// Extract conditions
 	for _, condition := range item.Status.Conditions {
 		manifestWork.Conditions = append(manifestWork.Conditions, models.Condition{
@@ -171,6 +153,20 @@ func GetManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 				},
 			}
 
+			// Process the values returned by the feedback rules
+			for _, value := range manifestStatus.StatusFeedbacks.Values {
+				manifestCondition.StatusFeedbacks = append(manifestCondition.StatusFeedbacks, models.FeedbackValue{
+					Name: value.Name,
+					FieldValue: models.FieldValue{
+						Type:    string(value.Value.Type),
+						Integer: value.Value.Integer,
+						String:  value.Value.String,
+						Boolean: value.Value.Boolean,
+						JsonRaw: value.Value.JsonRaw,
+					},
+				})
+			}
+
 			// Process conditions for this manifest
 			for _, condition := range manifestStatus.Conditions {
 				manifestCondition.Conditions = append(manifestCondition.Conditions, models.Condition{
@@ -186,5 +182,107 @@ func GetManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Co
 		}
 	}
 
-	c.JSON(http.StatusOK, manifestWork)
+	manifestWork.StatusSummary = summarizeManifestStatus(item)
+
+	return manifestWork
+}
+
+// summarizeManifestStatus counts the manifests of a work that are applied,
+// available and degraded. Manifests without a status yet only count in Total.
+func summarizeManifestStatus(item *workv1.ManifestWork) models.ManifestStatusSummary {
+	summary := models.ManifestStatusSummary{Total: len(item.Spec.Workload.Manifests)}
+	for _, manifestStatus := range item.Status.ResourceStatus.Manifests {
+		if meta.IsStatusConditionTrue(manifestStatus.Conditions, workv1.ManifestApplied) {
+			summary.Applied++
+		}
+		if meta.IsStatusConditionTrue(manifestStatus.Conditions, workv1.ManifestAvailable) {
+			summary.Available++
+		}
+		if meta.IsStatusConditionTrue(manifestStatus.Conditions, workv1.ManifestDegraded) {
+			summary.Degraded++
+		}
+	}
+	return summary
+}
+
+// convertDeleteOptionToModel converts the delete option of a work, nil when unset
+func convertDeleteOptionToModel(option *workv1.DeleteOption) *models.DeleteOption {
+	if option == nil {
+		return nil
+	}
+	result := &models.DeleteOption{PropagationPolicy: string(option.PropagationPolicy)}
+	if option.SelectivelyOrphan != nil {
+		result.SelectivelyOrphan = &models.SelectivelyOrphan{}
+		for _, rule := range option.SelectivelyOrphan.OrphaningRules {
+			result.SelectivelyOrphan.OrphaningRules = append(result.SelectivelyOrphan.OrphaningRules, models.OrphaningRule{
+				Group:     rule.Group,
+				Resource:  rule.Resource,
+				Name:      rule.Name,
+				Namespace: rule.Namespace,
+			})
+		}
+	}
+	return result
+}
+
+// convertManifestConfigToModel converts the feedback rules and update strategy of one manifest
+func convertManifestConfigToModel(config workv1.ManifestConfigOption) models.ManifestConfigOption {
+	result := models.ManifestConfigOption{
+		ResourceIdentifier: models.ResourceIdentifier{
+			Group:     config.ResourceIdentifier.Group,
+			Resource:  config.ResourceIdentifier.Resource,
+			Name:      config.ResourceIdentifier.Name,
+			Namespace: config.ResourceIdentifier.Namespace,
+		},
+	}
+
+	for _, rule := range config.FeedbackRules {
+		feedbackRule := models.FeedbackRule{Type: string(rule.Type)}
+		for _, path := range rule.JsonPaths {
+			feedbackRule.JsonPaths = append(feedbackRule.JsonPaths, models.JsonPath{Name: path.Name, Version: path.Version, Path: path.Path})
+		}
+		result.FeedbackRules = append(result.FeedbackRules, feedbackRule)
+	}
+
+	if strategy := config.UpdateStrategy; strategy != nil {
+		result.UpdateStrategy = &models.UpdateStrategy{Type: string(strategy.Type)}
+		if ssa := strategy.ServerSideApply; ssa != nil {
+			result.UpdateStrategy.ServerSideApply = &models.ServerSideApplyConfig{
+				Force:        ssa.Force,
+				FieldManager: ssa.FieldManager,
+			}
+			for _, field := range ssa.IgnoreFields {
+				result.UpdateStrategy.ServerSideApply.IgnoreFields = append(result.UpdateStrategy.ServerSideApply.IgnoreFields, models.IgnoreField{
+					Condition: string(field.Condition),
+					JSONPaths: field.JSONPaths,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// manifestWorkListFields are the fields the ManifestWork list can be filtered
+// on, the condition fields hold the status of the work's condition
+var manifestWorkListFields = func() listFields[models.ManifestWork] {
+	fieldFuncs := namespacedFields(
+		func(work models.ManifestWork) string { return work.Namespace },
+		func(work models.ManifestWork) string { return work.Name },
+	)
+	for field, conditionType := range map[string]string{
+		"applied":     workv1.WorkApplied,
+		"available":   workv1.WorkAvailable,
+		"degraded":    workv1.WorkDegraded,
+		"progressing": workv1.WorkProgressing,
+	} {
+		fieldFuncs[field] = func(work models.ManifestWork) []string { return conditionField(work.Conditions, conditionType) }
+	}
+	return fieldFuncs
+}()
+
+// manifestWorkListSorts are the keys the ManifestWork list can be sorted by
+var manifestWorkListSorts = listSorts[models.ManifestWork]{
+	"name":              func(work models.ManifestWork) string { return work.Name },
+	"creationTimestamp": func(work models.ManifestWork) string { return work.CreationTimestamp },
 }
@@ -2,14 +2,20 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetManifestWorks(t *testing.T) {
@@ -80,3 +86,127 @@ func TestGetManifestWork(t *testing.T) {
 		})
 	}
 }
+
+func TestGetManifestWorksFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-b", Namespace: "cluster1"}},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-a", Namespace: "cluster1"}},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "work-c", Namespace: "cluster2"}},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "cluster1"}}
+
+	GetManifestWorks(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var works []models.ManifestWork
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &works))
+	assert.Len(t, works, 2)
+	assert.Equal(t, "work-a", works[0].Name)
+	assert.Equal(t, "work-b", works[1].Name)
+}
+
+func TestConvertManifestWorkToModel(t *testing.T) {
+	readyReplicas := int64(3)
+	image := "nginx:1.27"
+	workCondition := func(conditionType string, status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status}
+	}
+
+	item := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "cluster1"},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{
+				{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "namespace": "default"}}`)}},
+				{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web", "namespace": "default"}}`)}},
+				{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web", "namespace": "default"}}`)}},
+			}},
+			DeleteOption: &workv1.DeleteOption{
+				PropagationPolicy: workv1.DeletePropagationPolicyTypeSelectivelyOrphan,
+				SelectivelyOrphan: &workv1.SelectivelyOrphan{OrphaningRules: []workv1.OrphaningRule{{Resource: "configmaps", Name: "web", Namespace: "default"}}},
+			},
+			ManifestConfigs: []workv1.ManifestConfigOption{{
+				ResourceIdentifier: workv1.ResourceIdentifier{Group: "apps", Resource: "deployments", Name: "web", Namespace: "default"},
+				FeedbackRules: []workv1.FeedbackRule{
+					{Type: workv1.WellKnownStatusType},
+					{Type: workv1.JSONPathsType, JsonPaths: []workv1.JsonPath{{Name: "image", Path: ".spec.template.spec.containers[0].image"}}},
+				},
+				UpdateStrategy: &workv1.UpdateStrategy{
+					Type: workv1.UpdateStrategyTypeServerSideApply,
+					ServerSideApply: &workv1.ServerSideApplyConfig{
+						Force:        true,
+						FieldManager: "dashboard",
+						IgnoreFields: []workv1.IgnoreField{{Condition: workv1.IgnoreFieldsConditionOnSpokeChange, JSONPaths: []string{".spec.replicas"}}},
+					},
+				},
+			}},
+			Executor: &workv1.ManifestWorkExecutor{Subject: workv1.ManifestWorkExecutorSubject{
+				Type:           workv1.ExecutorSubjectTypeServiceAccount,
+				ServiceAccount: &workv1.ManifestWorkSubjectServiceAccount{Namespace: "default", Name: "deployer"},
+			}},
+		},
+		Status: workv1.ManifestWorkStatus{ResourceStatus: workv1.ManifestResourceStatus{Manifests: []workv1.ManifestCondition{
+			{
+				ResourceMeta: workv1.ManifestResourceMeta{Ordinal: 0, Group: "apps", Kind: "Deployment", Name: "web"},
+				StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+					{Name: "ReadyReplicas", Value: workv1.FieldValue{Type: workv1.Integer, Integer: &readyReplicas}},
+					{Name: "image", Value: workv1.FieldValue{Type: workv1.String, String: &image}},
+				}},
+				Conditions: []metav1.Condition{workCondition(workv1.ManifestApplied, metav1.ConditionTrue), workCondition(workv1.ManifestAvailable, metav1.ConditionTrue)},
+			},
+			{
+				ResourceMeta: workv1.ManifestResourceMeta{Ordinal: 1, Kind: "Service", Name: "web"},
+				Conditions:   []metav1.Condition{workCondition(workv1.ManifestApplied, metav1.ConditionTrue), workCondition(workv1.ManifestAvailable, metav1.ConditionFalse), workCondition(workv1.ManifestDegraded, metav1.ConditionTrue)},
+			},
+		}}},
+	}
+
+	manifestWork := convertManifestWorkToModel(item)
+
+	assert.Equal(t, models.ManifestStatusSummary{Total: 3, Applied: 2, Available: 1, Degraded: 1}, manifestWork.StatusSummary)
+
+	require.Len(t, manifestWork.ResourceStatus.Manifests, 2)
+	assert.Equal(t, []models.FeedbackValue{
+		{Name: "ReadyReplicas", FieldValue: models.FieldValue{Type: "Integer", Integer: &readyReplicas}},
+		{Name: "image", FieldValue: models.FieldValue{Type: "String", String: &image}},
+	}, manifestWork.ResourceStatus.Manifests[0].StatusFeedbacks)
+	assert.Empty(t, manifestWork.ResourceStatus.Manifests[1].StatusFeedbacks)
+
+	assert.Equal(t, &models.DeleteOption{
+		PropagationPolicy: "SelectivelyOrphan",
+		SelectivelyOrphan: &models.SelectivelyOrphan{OrphaningRules: []models.OrphaningRule{{Resource: "configmaps", Name: "web", Namespace: "default"}}},
+	}, manifestWork.DeleteOption)
+
+	assert.Equal(t, []models.ManifestConfigOption{{
+		ResourceIdentifier: models.ResourceIdentifier{Group: "apps", Resource: "deployments", Name: "web", Namespace: "default"},
+		FeedbackRules: []models.FeedbackRule{
+			{Type: "WellKnownStatus"},
+			{Type: "JSONPaths", JsonPaths: []models.JsonPath{{Name: "image", Path: ".spec.template.spec.containers[0].image"}}},
+		},
+		UpdateStrategy: &models.UpdateStrategy{
+			Type: "ServerSideApply",
+			ServerSideApply: &models.ServerSideApplyConfig{
+				Force:        true,
+				FieldManager: "dashboard",
+				IgnoreFields: []models.IgnoreField{{Condition: "OnSpokeChange", JSONPaths: []string{".spec.replicas"}}},
+			},
+		},
+	}}, manifestWork.ManifestConfigs)
+
+	assert.Equal(t, &models.ManifestWorkExecutor{Subject: models.ManifestWorkExecutorSubject{
+		Type:           "ServiceAccount",
+		ServiceAccount: &models.ServiceAccountReference{Namespace: "default", Name: "deployer"},
+	}}, manifestWork.Executor)
+
+	// A work without options or status has an empty summary
+	empty := convertManifestWorkToModel(&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "cluster1"}})
+	assert.Nil(t, empty.DeleteOption)
+	assert.Nil(t, empty.Executor)
+	assert.Empty(t, empty.ManifestConfigs)
+	assert.Equal(t, models.ManifestStatusSummary{}, empty.StatusSummary)
+}
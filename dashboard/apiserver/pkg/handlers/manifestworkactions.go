@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// CreateManifestWork creates a ManifestWork in a cluster namespace as the caller
+func CreateManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+
+	var request models.ManifestWorkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if errs := validation.IsDNS1123Subdomain(request.Name); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid manifest work name %q: %s", request.Name, strings.Join(errs, "; "))})
+		return
+	}
+	spec, err := manifestWorkSpecFromModel(request.Spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userClients, ok := userWorkClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	manifestWork := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      request.Name,
+			Namespace: namespace,
+			Labels:    request.Labels,
+		},
+		Spec: spec,
+	}
+	created, err := userClients.WorkClient.WorkV1().ManifestWorks(namespace).Create(ctx, manifestWork, metav1.CreateOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, convertManifestWorkToModel(created))
+}
+
+// UpdateManifestWork replaces the labels and spec of a ManifestWork. The
+// executor is kept, it cannot be set through the dashboard. Labels are kept
+// when the request omits them, and the labels that tie a work to the
+// ManifestWorkReplicaSet or addon that owns it are always kept.
+func UpdateManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var request models.ManifestWorkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if request.Name != "" && request.Name != name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name %q does not match manifest work %q", request.Name, name)})
+		return
+	}
+	spec, err := manifestWorkSpecFromModel(request.Spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userClients, ok := userWorkClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	var updated *workv1.ManifestWork
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		manifestWork, err := userClients.WorkClient.WorkV1().ManifestWorks(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		spec.Executor = manifestWork.Spec.Executor
+		if request.Labels != nil {
+			manifestWork.Labels = withOwnerLabels(request.Labels, manifestWork.Labels)
+		}
+		manifestWork.Spec = spec
+		updated, err = userClients.WorkClient.WorkV1().ManifestWorks(namespace).Update(ctx, manifestWork, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManifestWorkToModel(updated))
+}
+
+// manifestWorkOwnerLabels are set by the controllers that own a ManifestWork,
+// removing them would orphan the work
+var manifestWorkOwnerLabels = []string{
+	manifestWorkReplicaSetLabel,
+	addonv1alpha1.AddonLabelKey,
+}
+
+// withOwnerLabels returns the requested labels with the owner labels of the
+// current ones carried over
+func withOwnerLabels(requested, current map[string]string) map[string]string {
+	labels := make(map[string]string, len(requested))
+	for key, value := range requested {
+		labels[key] = value
+	}
+	for _, key := range manifestWorkOwnerLabels {
+		if value, ok := current[key]; ok {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// DeleteManifestWork deletes a ManifestWork. The response is the work as it
+// was before deletion.
+func DeleteManifestWork(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	userClients, ok := userWorkClient(c, ocmClient)
+	if !ok {
+		return
+	}
+
+	manifestWork, err := userClients.WorkClient.WorkV1().ManifestWorks(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	err = userClients.WorkClient.WorkV1().ManifestWorks(namespace).Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &manifestWork.UID},
+	})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, convertManifestWorkToModel(manifestWork))
+}
+
+// userWorkClient is userClient for handlers that write through the work clientset
+func userWorkClient(c *gin.Context, ocmClient *client.OCMClient) (*client.OCMClient, bool) {
+	if ocmClient == nil || ocmClient.WorkClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return nil, false
+	}
+	return userClient(c, ocmClient)
+}
+
+// manifestWorkSpecFromModel validates a ManifestWork spec and converts it to
+// the API type. Every manifest must be a well-formed Kubernetes object.
+func manifestWorkSpecFromModel(spec models.ManifestWorkSpec) (workv1.ManifestWorkSpec, error) {
+	var result workv1.ManifestWorkSpec
+
+	if len(spec.Workload) == 0 {
+		return result, fmt.Errorf("spec.workload must contain at least one manifest")
+	}
+	for i, manifest := range spec.Workload {
+		converted, err := manifestFromModel(manifest)
+		if err != nil {
+			return result, fmt.Errorf("spec.workload[%d]: %w", i, err)
+		}
+		result.Workload.Manifests = append(result.Workload.Manifests, converted)
+	}
+
+	if spec.DeleteOption != nil {
+		deleteOption, err := deleteOptionFromModel(*spec.DeleteOption)
+		if err != nil {
+			return result, fmt.Errorf("spec.deleteOption: %w", err)
+		}
+		result.DeleteOption = deleteOption
+	}
+
+	for i, config := range spec.ManifestConfigs {
+		converted, err := manifestConfigFromModel(config)
+		if err != nil {
+			return result, fmt.Errorf("spec.manifestConfigs[%d]: %w", i, err)
+		}
+		result.ManifestConfigs = append(result.ManifestConfigs, converted)
+	}
+
+	return result, nil
+}
+
+// manifestFromModel checks that a manifest has an apiVersion, kind and name
+// and encodes it for the ManifestWork
+func manifestFromModel(manifest models.Manifest) (workv1.Manifest, error) {
+	if len(manifest.RawExtension) == 0 {
+		return workv1.Manifest{}, fmt.Errorf("rawExtension is required")
+	}
+
+	obj := &unstructured.Unstructured{Object: manifest.RawExtension}
+	apiVersion, _, err := unstructured.NestedString(obj.Object, "apiVersion")
+	if err != nil || apiVersion == "" {
+		return workv1.Manifest{}, fmt.Errorf("apiVersion is required")
+	}
+	if _, err := schema.ParseGroupVersion(apiVersion); err != nil {
+		return workv1.Manifest{}, fmt.Errorf("invalid apiVersion %q: %w", apiVersion, err)
+	}
+	kind, _, err := unstructured.NestedString(obj.Object, "kind")
+	if err != nil || kind == "" {
+		return workv1.Manifest{}, fmt.Errorf("kind is required")
+	}
+	if _, _, err := unstructured.NestedMap(obj.Object, "metadata"); err != nil {
+		return workv1.Manifest{}, fmt.Errorf("metadata must be an object")
+	}
+	name, _, err := unstructured.NestedString(obj.Object, "metadata", "name")
+	if err != nil || name == "" {
+		return workv1.Manifest{}, fmt.Errorf("metadata.name is required")
+	}
+	namespace, _, err := unstructured.NestedString(obj.Object, "metadata", "namespace")
+	if err != nil {
+		return workv1.Manifest{}, fmt.Errorf("metadata.namespace must be a string")
+	}
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return workv1.Manifest{}, fmt.Errorf("invalid metadata.namespace %q: %s", namespace, strings.Join(errs, "; "))
+		}
+	}
+
+	raw, err := json.Marshal(obj.Object)
+	if err != nil {
+		return workv1.Manifest{}, err
+	}
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}, nil
+}
+
+// deleteOptionFromModel validates the propagation policy and orphaning rules
+func deleteOptionFromModel(option models.DeleteOption) (*workv1.DeleteOption, error) {
+	result := &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyType(option.PropagationPolicy)}
+
+	switch result.PropagationPolicy {
+	case workv1.DeletePropagationPolicyTypeForeground, workv1.DeletePropagationPolicyTypeOrphan:
+		if option.SelectivelyOrphan != nil {
+			return nil, fmt.Errorf("selectivelyOrphans requires propagationPolicy %s", workv1.DeletePropagationPolicyTypeSelectivelyOrphan)
+		}
+	case workv1.DeletePropagationPolicyTypeSelectivelyOrphan:
+		if option.SelectivelyOrphan == nil {
+			return result, nil
+		}
+		result.SelectivelyOrphan = &workv1.SelectivelyOrphan{}
+		for i, rule := range option.SelectivelyOrphan.OrphaningRules {
+			identifier, err := resourceIdentifierFromModel(models.ResourceIdentifier(rule))
+			if err != nil {
+				return nil, fmt.Errorf("selectivelyOrphans.orphaningRules[%d]: %w", i, err)
+			}
+			result.SelectivelyOrphan.OrphaningRules = append(result.SelectivelyOrphan.OrphaningRules, workv1.OrphaningRule(identifier))
+		}
+	default:
+		return nil, fmt.Errorf("invalid propagationPolicy %q, must be %s, %s or %s", option.PropagationPolicy,
+			workv1.DeletePropagationPolicyTypeForeground, workv1.DeletePropagationPolicyTypeOrphan, workv1.DeletePropagationPolicyTypeSelectivelyOrphan)
+	}
+
+	return result, nil
+}
+
+// manifestConfigFromModel validates the feedback rules and update strategy of
+// one manifest
+func manifestConfigFromModel(config models.ManifestConfigOption) (workv1.ManifestConfigOption, error) {
+	identifier, err := resourceIdentifierFromModel(config.ResourceIdentifier)
+	if err != nil {
+		return workv1.ManifestConfigOption{}, fmt.Errorf("resourceIdentifier: %w", err)
+	}
+	result := workv1.ManifestConfigOption{ResourceIdentifier: identifier}
+
+	for i, rule := range config.FeedbackRules {
+		converted := workv1.FeedbackRule{Type: workv1.FeedBackType(rule.Type)}
+		switch converted.Type {
+		case workv1.WellKnownStatusType:
+			if len(rule.JsonPaths) > 0 {
+				return workv1.ManifestConfigOption{}, fmt.Errorf("feedbackRules[%d]: jsonPaths requires type %s", i, workv1.JSONPathsType)
+			}
+		case workv1.JSONPathsType:
+			if len(rule.JsonPaths) == 0 {
+				return workv1.ManifestConfigOption{}, fmt.Errorf("feedbackRules[%d]: jsonPaths is required", i)
+			}
+			for j, path := range rule.JsonPaths {
+				if path.Name == "" || path.Path == "" {
+					return workv1.ManifestConfigOption{}, fmt.Errorf("feedbackRules[%d].jsonPaths[%d]: name and path are required", i, j)
+				}
+				converted.JsonPaths = append(converted.JsonPaths, workv1.JsonPath{Name: path.Name, Version: path.Version, Path: path.Path})
+			}
+		default:
+			return workv1.ManifestConfigOption{}, fmt.Errorf("feedbackRules[%d]: invalid type %q, must be %s or %s", i, rule.Type, workv1.WellKnownStatusType, workv1.JSONPathsType)
+		}
+		result.FeedbackRules = append(result.FeedbackRules, converted)
+	}
+
+	if config.UpdateStrategy != nil {
+		strategy, err := updateStrategyFromModel(*config.UpdateStrategy)
+		if err != nil {
+			return workv1.ManifestConfigOption{}, fmt.Errorf("updateStrategy: %w", err)
+		}
+		result.UpdateStrategy = strategy
+	}
+
+	return result, nil
+}
+
+// updateStrategyFromModel validates an update strategy. An empty type
+// defaults to Update, the same as the API.
+func updateStrategyFromModel(strategy models.UpdateStrategy) (*workv1.UpdateStrategy, error) {
+	result := &workv1.UpdateStrategy{Type: workv1.UpdateStrategyType(strategy.Type)}
+
+	switch result.Type {
+	case "":
+		result.Type = workv1.UpdateStrategyTypeUpdate
+	case workv1.UpdateStrategyTypeUpdate, workv1.UpdateStrategyTypeCreateOnly, workv1.UpdateStrategyTypeReadOnly, workv1.UpdateStrategyTypeServerSideApply:
+	default:
+		return nil, fmt.Errorf("invalid type %q, must be %s, %s, %s or %s", strategy.Type, workv1.UpdateStrategyTypeUpdate,
+			workv1.UpdateStrategyTypeCreateOnly, workv1.UpdateStrategyTypeServerSideApply, workv1.UpdateStrategyTypeReadOnly)
+	}
+
+	if strategy.ServerSideApply == nil {
+		return result, nil
+	}
+	if result.Type != workv1.UpdateStrategyTypeServerSideApply {
+		return nil, fmt.Errorf("serverSideApply requires type %s", workv1.UpdateStrategyTypeServerSideApply)
+	}
+	result.ServerSideApply = &workv1.ServerSideApplyConfig{
+		Force:        strategy.ServerSideApply.Force,
+		FieldManager: strategy.ServerSideApply.FieldManager,
+	}
+	for i, field := range strategy.ServerSideApply.IgnoreFields {
+		condition := workv1.IgnoreFieldsCondition(field.Condition)
+		if condition != workv1.IgnoreFieldsConditionOnSpokeChange && condition != workv1.IgnoreFieldsConditionOnSpokePresent {
+			return nil, fmt.Errorf("serverSideApply.ignoreFields[%d]: invalid condition %q, must be %s or %s", i, field.Condition,
+				workv1.IgnoreFieldsConditionOnSpokeChange, workv1.IgnoreFieldsConditionOnSpokePresent)
+		}
+		if len(field.JSONPaths) == 0 {
+			return nil, fmt.Errorf("serverSideApply.ignoreFields[%d]: jsonPaths is required", i)
+		}
+		result.ServerSideApply.IgnoreFields = append(result.ServerSideApply.IgnoreFields, workv1.IgnoreField{Condition: condition, JSONPaths: field.JSONPaths})
+	}
+
+	return result, nil
+}
+
+// resourceIdentifierFromModel checks that a resource identifier names a resource
+func resourceIdentifierFromModel(identifier models.ResourceIdentifier) (workv1.ResourceIdentifier, error) {
+	if identifier.Resource == "" || identifier.Name == "" {
+		return workv1.ResourceIdentifier{}, fmt.Errorf("resource and name are required")
+	}
+	return workv1.ResourceIdentifier{
+		Group:     identifier.Group,
+		Resource:  identifier.Resource,
+		Name:      identifier.Name,
+		Namespace: identifier.Namespace,
+	}, nil
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	fakework "open-cluster-management.io/api/client/work/clientset/versioned/fake"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newManifestWorkActionClient returns an OCMClient whose work clientset holds the given objects
+func newManifestWorkActionClient(objects ...runtime.Object) *client.OCMClient {
+	return &client.OCMClient{
+		ClusterClient: newClusterSetActionClient().ClusterClient,
+		WorkClient:    fakework.NewSimpleClientset(objects...),
+	}
+}
+
+const configMapManifest = `{"rawExtension": {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web", "namespace": "default"}, "data": {"key": "value"}}}`
+
+func TestCreateManifestWork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "workload only",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `]}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "all options",
+			body: `{"name": "web", "labels": {"app": "web"}, "spec": {
+				"workload": [` + configMapManifest + `],
+				"deleteOption": {"propagationPolicy": "SelectivelyOrphan", "selectivelyOrphans": {"orphaningRules": [{"resource": "configmaps", "name": "web", "namespace": "default"}]}},
+				"manifestConfigs": [{
+					"resourceIdentifier": {"resource": "configmaps", "name": "web", "namespace": "default"},
+					"feedbackRules": [{"type": "JSONPaths", "jsonPaths": [{"name": "key", "path": ".data.key"}]}],
+					"updateStrategy": {"type": "ServerSideApply", "serverSideApply": {"force": true, "fieldManager": "dashboard", "ignoreFields": [{"condition": "OnSpokeChange", "jsonPaths": [".data"]}]}}
+				}]
+			}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid name",
+			body:           `{"name": "Web App", "spec": {"workload": [` + configMapManifest + `]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "empty workload",
+			body:           `{"name": "web", "spec": {}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest without kind",
+			body:           `{"name": "web", "spec": {"workload": [{"rawExtension": {"apiVersion": "v1", "metadata": {"name": "web"}}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest with invalid apiVersion",
+			body:           `{"name": "web", "spec": {"workload": [{"rawExtension": {"apiVersion": "apps/v1/beta", "kind": "Deployment", "metadata": {"name": "web"}}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest without name",
+			body:           `{"name": "web", "spec": {"workload": [{"rawExtension": {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {}}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest with metadata that is not an object",
+			body:           `{"name": "web", "spec": {"workload": [{"rawExtension": {"apiVersion": "v1", "kind": "ConfigMap", "metadata": "web"}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid propagation policy",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `], "deleteOption": {"propagationPolicy": "Background"}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "orphaning rules without selective orphaning",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `], "deleteOption": {"propagationPolicy": "Orphan", "selectivelyOrphans": {}}}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "json paths feedback without paths",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `], "manifestConfigs": [{"resourceIdentifier": {"resource": "configmaps", "name": "web"}, "feedbackRules": [{"type": "JSONPaths"}]}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "server side apply config with update strategy",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `], "manifestConfigs": [{"resourceIdentifier": {"resource": "configmaps", "name": "web"}, "updateStrategy": {"type": "Update", "serverSideApply": {"force": true}}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest config without resource",
+			body:           `{"name": "web", "spec": {"workload": [` + configMapManifest + `], "manifestConfigs": [{"resourceIdentifier": {"name": "web"}}]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := newManifestWorkActionClient()
+			w := runClusterAction(CreateManifestWork, ocmClient, gin.Params{{Key: "namespace", Value: "cluster1"}}, "/api/namespaces/cluster1/manifestworks", tt.body)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var manifestWork models.ManifestWork
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifestWork))
+			assert.Equal(t, "cluster1", manifestWork.Namespace)
+			require.Len(t, manifestWork.Manifests, 1)
+			assert.Equal(t, "ConfigMap", manifestWork.Manifests[0].RawExtension["kind"])
+
+			stored, err := ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Len(t, stored.Spec.Workload.Manifests, 1)
+		})
+	}
+}
+
+func TestCreateManifestWorkConvertsSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newManifestWorkActionClient()
+	body := `{"name": "web", "spec": {
+		"workload": [` + configMapManifest + `],
+		"deleteOption": {"propagationPolicy": "SelectivelyOrphan", "selectivelyOrphans": {"orphaningRules": [{"resource": "configmaps", "name": "web", "namespace": "default"}]}},
+		"manifestConfigs": [{
+			"resourceIdentifier": {"group": "apps", "resource": "deployments", "name": "web", "namespace": "default"},
+			"feedbackRules": [{"type": "WellKnownStatus"}],
+			"updateStrategy": {"type": "ServerSideApply", "serverSideApply": {"force": true, "fieldManager": "dashboard", "ignoreFields": [{"condition": "OnSpokePresent", "jsonPaths": [".spec.replicas"]}]}}
+		}, {
+			"resourceIdentifier": {"resource": "configmaps", "name": "web", "namespace": "default"},
+			"updateStrategy": {}
+		}]
+	}}`
+	w := runClusterAction(CreateManifestWork, ocmClient, gin.Params{{Key: "namespace", Value: "cluster1"}}, "/api/namespaces/cluster1/manifestworks", body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	stored, err := ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	var manifest map[string]interface{}
+	require.NoError(t, json.Unmarshal(stored.Spec.Workload.Manifests[0].Raw, &manifest))
+	assert.Equal(t, map[string]interface{}{"key": "value"}, manifest["data"])
+
+	assert.Equal(t, &workv1.DeleteOption{
+		PropagationPolicy: workv1.DeletePropagationPolicyTypeSelectivelyOrphan,
+		SelectivelyOrphan: &workv1.SelectivelyOrphan{OrphaningRules: []workv1.OrphaningRule{
+			{Resource: "configmaps", Name: "web", Namespace: "default"},
+		}},
+	}, stored.Spec.DeleteOption)
+
+	require.Len(t, stored.Spec.ManifestConfigs, 2)
+	assert.Equal(t, workv1.ManifestConfigOption{
+		ResourceIdentifier: workv1.ResourceIdentifier{Group: "apps", Resource: "deployments", Name: "web", Namespace: "default"},
+		FeedbackRules:      []workv1.FeedbackRule{{Type: workv1.WellKnownStatusType}},
+		UpdateStrategy: &workv1.UpdateStrategy{
+			Type: workv1.UpdateStrategyTypeServerSideApply,
+			ServerSideApply: &workv1.ServerSideApplyConfig{
+				Force:        true,
+				FieldManager: "dashboard",
+				IgnoreFields: []workv1.IgnoreField{{Condition: workv1.IgnoreFieldsConditionOnSpokePresent, JSONPaths: []string{".spec.replicas"}}},
+			},
+		},
+	}, stored.Spec.ManifestConfigs[0])
+	assert.Equal(t, workv1.UpdateStrategyTypeUpdate, stored.Spec.ManifestConfigs[1].UpdateStrategy.Type)
+}
+
+func TestCreateManifestWorkReturnsKubeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The hub rejects the write when the caller lacks RBAC in the namespace
+	ocmClient := newManifestWorkActionClient()
+	ocmClient.WorkClient.(*fakework.Clientset).PrependReactor("create", "manifestworks", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: workGroup, Resource: manifestWorksResource}, "web", nil)
+	})
+
+	w := runClusterAction(CreateManifestWork, ocmClient, gin.Params{{Key: "namespace", Value: "cluster1"}}, "/api/namespaces/cluster1/manifestworks",
+		`{"name": "web", "spec": {"workload": [`+configMapManifest+`]}}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestUpdateManifestWork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	executor := &workv1.ManifestWorkExecutor{Subject: workv1.ManifestWorkExecutorSubject{
+		Type:           workv1.ExecutorSubjectTypeServiceAccount,
+		ServiceAccount: &workv1.ManifestWorkSubjectServiceAccount{Namespace: "default", Name: "deployer"},
+	}}
+	ocmClient := newManifestWorkActionClient(&workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "cluster1", Labels: map[string]string{
+			"app":                       "web",
+			manifestWorkReplicaSetLabel: "default.web",
+		}},
+		Spec: workv1.ManifestWorkSpec{Executor: executor},
+	})
+	params := gin.Params{{Key: "namespace", Value: "cluster1"}, {Key: "name", Value: "web"}}
+
+	w := runClusterAction(UpdateManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web",
+		`{"labels": {"app": "web", "tier": "frontend"}, "spec": {"workload": [`+configMapManifest+`], "deleteOption": {"propagationPolicy": "Orphan"}}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	stored, err := ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", stored.Labels["tier"])
+	assert.Len(t, stored.Spec.Workload.Manifests, 1)
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeOrphan, stored.Spec.DeleteOption.PropagationPolicy)
+	assert.Equal(t, executor, stored.Spec.Executor)
+
+	// Omitted labels are kept, and replaced labels keep the owner label
+	w = runClusterAction(UpdateManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web",
+		`{"spec": {"workload": [`+configMapManifest+`]}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err = ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend", manifestWorkReplicaSetLabel: "default.web"}, stored.Labels)
+
+	w = runClusterAction(UpdateManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web",
+		`{"labels": {}, "spec": {"workload": [`+configMapManifest+`]}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err = ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{manifestWorkReplicaSetLabel: "default.web"}, stored.Labels)
+
+	w = runClusterAction(UpdateManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web",
+		`{"name": "api", "spec": {"workload": [`+configMapManifest+`]}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = runClusterAction(UpdateManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web", `{"spec": {"workload": [{"rawExtension": {"kind": "ConfigMap"}}]}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = runClusterAction(UpdateManifestWork, ocmClient, gin.Params{{Key: "namespace", Value: "cluster1"}, {Key: "name", Value: "missing"}}, "/api/namespaces/cluster1/manifestworks/missing",
+		`{"spec": {"workload": [`+configMapManifest+`]}}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteManifestWork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newManifestWorkActionClient(&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "cluster1", UID: "uid-1"}})
+	params := gin.Params{{Key: "namespace", Value: "cluster1"}, {Key: "name", Value: "web"}}
+
+	w := runClusterAction(DeleteManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := ocmClient.WorkClient.WorkV1().ManifestWorks("cluster1").Get(context.Background(), "web", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	w = runClusterAction(DeleteManifestWork, ocmClient, params, "/api/namespaces/cluster1/manifestworks/web", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestManifestWorkActionsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(*gin.Context, *client.OCMClient, context.Context){
+		"create": CreateManifestWork,
+		"update": UpdateManifestWork,
+		"delete": DeleteManifestWork,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := runClusterAction(handler, nil, gin.Params{{Key: "namespace", Value: "cluster1"}, {Key: "name", Value: "web"}}, "/api/namespaces/cluster1/manifestworks/web",
+				`{"name": "web", "spec": {"workload": [`+configMapManifest+`]}}`)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+const (
+	// manifestWorkReplicaSetLabel is set by the hub on every ManifestWork a
+	// replica set creates, the value is "<namespace>.<name>" of the replica set
+	manifestWorkReplicaSetLabel = "work.open-cluster-management.io/manifestworkreplicaset"
+
+	// manifestWorkReplicaSetPlacementLabel names the placement that selected
+	// the cluster of a ManifestWork created by a replica set
+	manifestWorkReplicaSetPlacementLabel = "work.open-cluster-management.io/placementname"
+)
+
+// GetManifestWorkReplicaSets retrieves all ManifestWorkReplicaSets across all namespaces
+func GetManifestWorkReplicaSets(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	query, err := parseListQuery(c, manifestWorkReplicaSetListFields, manifestWorkReplicaSetListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ocmClient.WorkInformerFactory.Work().V1alpha1().ManifestWorkReplicaSets().Lister().List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondManifestWorkReplicaSets(c, ocmClient, ctx, query, list)
+}
+
+// GetManifestWorkReplicaSetsByNamespace retrieves the ManifestWorkReplicaSets in a namespace
+func GetManifestWorkReplicaSetsByNamespace(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	query, err := parseListQuery(c, manifestWorkReplicaSetListFields, manifestWorkReplicaSetListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ocmClient.WorkInformerFactory.Work().V1alpha1().ManifestWorkReplicaSets().Lister().ManifestWorkReplicaSets(namespace).List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondManifestWorkReplicaSets(c, ocmClient, ctx, query, list)
+}
+
+// GetManifestWorkReplicaSet retrieves a specific ManifestWorkReplicaSet with
+// the ManifestWork it created on every cluster
+func GetManifestWorkReplicaSet(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorkReplicaSetsResource).allowed(namespace, name) {
+		respondForbidden(c, manifestWorkReplicaSetsResource, name)
+		return
+	}
+
+	item, err := ocmClient.WorkInformerFactory.Work().V1alpha1().ManifestWorkReplicaSets().Lister().ManifestWorkReplicaSets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("manifestworkreplicaset %s/%s not found", namespace, name)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	replicaSet, err := convertManifestWorkReplicaSetToModel(item, ocmClient, newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, replicaSet)
+}
+
+// respondManifestWorkReplicaSets converts the replica sets the caller may read,
+// ordered by namespace and name unless the query sorts them
+func respondManifestWorkReplicaSets(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context,
+	query *listQuery[models.ManifestWorkReplicaSet], list []*workv1alpha1.ManifestWorkReplicaSet) {
+	access := newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorkReplicaSetsResource)
+	workAccess := newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource)
+
+	replicaSets := make([]models.ManifestWorkReplicaSet, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
+		}
+		replicaSet, err := convertManifestWorkReplicaSetToModel(item, ocmClient, workAccess)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		replicaSets = append(replicaSets, replicaSet)
+	}
+
+	sort.Slice(replicaSets, func(i, j int) bool {
+		if replicaSets[i].Namespace != replicaSets[j].Namespace {
+			return replicaSets[i].Namespace < replicaSets[j].Namespace
+		}
+		return replicaSets[i].Name < replicaSets[j].Name
+	})
+
+	query.respond(c, replicaSets)
+}
+
+// convertManifestWorkReplicaSetToModel converts a replica set and joins the
+// ManifestWorks it created, found by the replica set label. Only works the
+// caller may read are included in the per-cluster breakdown.
+func convertManifestWorkReplicaSetToModel(item *workv1alpha1.ManifestWorkReplicaSet, ocmClient *client.OCMClient, workAccess accessChecker) (models.ManifestWorkReplicaSet, error) {
+	replicaSet := models.ManifestWorkReplicaSet{
+		ID:                string(item.GetUID()),
+		Name:              item.GetName(),
+		Namespace:         item.GetNamespace(),
+		Labels:            item.GetLabels(),
+		PlacementRefs:     make([]models.LocalPlacementReference, 0, len(item.Spec.PlacementRefs)),
+		Clusters:          []models.ManifestWorkReplicaSetCluster{},
+		CreationTimestamp: item.GetCreationTimestamp().Format(time.RFC3339),
+		Summary:           convertReplicaSetSummaryToModel(item.Status.Summary),
+		Conditions:        convertConditionsToModel(item.Status.Conditions),
+	}
+
+	for _, manifest := range item.Spec.ManifestWorkTemplate.Workload.Manifests {
+		var rawObj map[string]interface{}
+		if err := json.Unmarshal(manifest.Raw, &rawObj); err == nil {
+			replicaSet.Manifests = append(replicaSet.Manifests, models.Manifest{RawExtension: rawObj})
+		}
+	}
+
+	for _, ref := range item.Spec.PlacementRefs {
+		replicaSet.PlacementRefs = append(replicaSet.PlacementRefs, models.LocalPlacementReference{
+			Name:            ref.Name,
+			RolloutStrategy: convertRolloutStrategyToModel(ref.RolloutStrategy),
+		})
+	}
+
+	for _, placement := range item.Status.PlacementsSummary {
+		replicaSet.PlacementSummaries = append(replicaSet.PlacementSummaries, models.ManifestWorkPlacementSummary{
+			Name:                    placement.Name,
+			AvailableDecisionGroups: placement.AvailableDecisionGroups,
+			Summary:                 convertReplicaSetSummaryToModel(placement.Summary),
+		})
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{manifestWorkReplicaSetLabel: item.Namespace + "." + item.Name})
+	works, err := ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Lister().List(selector)
+	if err != nil {
+		return replicaSet, err
+	}
+	for _, work := range works {
+		if !workAccess.allowed(work.Namespace, work.Name) {
+			continue
+		}
+		conditions := work.Status.Conditions
+		replicaSet.Clusters = append(replicaSet.Clusters, models.ManifestWorkReplicaSetCluster{
+			ClusterName:   work.Namespace,
+			Placement:     work.Labels[manifestWorkReplicaSetPlacementLabel],
+			ManifestWork:  work.Name,
+			Applied:       meta.IsStatusConditionTrue(conditions, workv1.WorkApplied),
+			Available:     meta.IsStatusConditionTrue(conditions, workv1.WorkAvailable),
+			Degraded:      meta.IsStatusConditionTrue(conditions, workv1.WorkDegraded),
+			Progressing:   meta.IsStatusConditionTrue(conditions, workv1.WorkProgressing),
+			StatusSummary: summarizeManifestStatus(work),
+			Conditions:    convertConditionsToModel(conditions),
+		})
+	}
+	sort.Slice(replicaSet.Clusters, func(i, j int) bool {
+		return replicaSet.Clusters[i].ClusterName < replicaSet.Clusters[j].ClusterName
+	})
+
+	return replicaSet, nil
+}
+
+// convertReplicaSetSummaryToModel converts the work counts of a replica set
+func convertReplicaSetSummaryToModel(summary workv1alpha1.ManifestWorkReplicaSetSummary) models.ManifestWorkReplicaSetSummary {
+	return models.ManifestWorkReplicaSetSummary{
+		Total:       summary.Total,
+		Progressing: summary.Progressing,
+		Available:   summary.Available,
+		Degraded:    summary.Degraded,
+		Applied:     summary.Applied,
+	}
+}
+
+// convertRolloutStrategyToModel flattens the settings of the strategy's type
+func convertRolloutStrategyToModel(strategy clusterv1alpha1.RolloutStrategy) models.RolloutStrategy {
+	result := models.RolloutStrategy{Type: string(strategy.Type)}
+	if result.Type == "" {
+		result.Type = string(clusterv1alpha1.All)
+	}
+
+	var config *clusterv1alpha1.RolloutConfig
+	var groups []clusterv1alpha1.MandatoryDecisionGroup
+	switch {
+	case strategy.Type == clusterv1alpha1.Progressive && strategy.Progressive != nil:
+		config = &strategy.Progressive.RolloutConfig
+		groups = strategy.Progressive.MandatoryDecisionGroups.MandatoryDecisionGroups
+		result.MaxConcurrency = intOrStringToModel(strategy.Progressive.MaxConcurrency)
+	case strategy.Type == clusterv1alpha1.ProgressivePerGroup && strategy.ProgressivePerGroup != nil:
+		config = &strategy.ProgressivePerGroup.RolloutConfig
+		groups = strategy.ProgressivePerGroup.MandatoryDecisionGroups.MandatoryDecisionGroups
+	case strategy.All != nil:
+		config = &strategy.All.RolloutConfig
+	}
+
+	if config != nil {
+		if config.MinSuccessTime.Duration != 0 {
+			result.MinSuccessTime = config.MinSuccessTime.Duration.String()
+		}
+		result.ProgressDeadline = config.ProgressDeadline
+		result.MaxFailures = intOrStringToModel(config.MaxFailures)
+	}
+	for _, group := range groups {
+		result.MandatoryDecisionGroups = append(result.MandatoryDecisionGroups, models.MandatoryDecisionGroup{
+			GroupName:  group.GroupName,
+			GroupIndex: group.GroupIndex,
+		})
+	}
+
+	return result
+}
+
+// intOrStringToModel formats an int-or-string, empty for the zero value
+func intOrStringToModel(value intstr.IntOrString) string {
+	if value == (intstr.IntOrString{}) {
+		return ""
+	}
+	return value.String()
+}
+
+// convertConditionsToModel converts API conditions to our model
+func convertConditionsToModel(conditions []metav1.Condition) []models.Condition {
+	var result []models.Condition
+	for _, condition := range conditions {
+		result = append(result, models.Condition{
+			Type:               condition.Type,
+			Status:             string(condition.Status),
+			LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return result
+}
+
+// manifestWorkReplicaSetListFields are the fields the replica set lists can be
+// filtered on, placement matches any of the referenced placements
+var manifestWorkReplicaSetListFields = func() listFields[models.ManifestWorkReplicaSet] {
+	fieldFuncs := namespacedFields(
+		func(replicaSet models.ManifestWorkReplicaSet) string { return replicaSet.Namespace },
+		func(replicaSet models.ManifestWorkReplicaSet) string { return replicaSet.Name },
+	)
+	fieldFuncs["placement"] = func(replicaSet models.ManifestWorkReplicaSet) []string {
+		placements := make([]string, 0, len(replicaSet.PlacementRefs))
+		for _, ref := range replicaSet.PlacementRefs {
+			placements = append(placements, ref.Name)
+		}
+		return placements
+	}
+	return fieldFuncs
+}()
+
+// manifestWorkReplicaSetListSorts are the keys the replica set lists can be sorted by
+var manifestWorkReplicaSetListSorts = listSorts[models.ManifestWorkReplicaSet]{
+	"namespace":         func(replicaSet models.ManifestWorkReplicaSet) string { return replicaSet.Namespace },
+	"name":              func(replicaSet models.ManifestWorkReplicaSet) string { return replicaSet.Name },
+	"creationTimestamp": func(replicaSet models.ManifestWorkReplicaSet) string { return replicaSet.CreationTimestamp },
+}
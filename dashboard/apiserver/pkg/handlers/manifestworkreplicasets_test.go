@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+func newReplicaSetWork(cluster, placement string, conditions ...metav1.Condition) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-web", Namespace: cluster, Labels: map[string]string{
+			manifestWorkReplicaSetLabel:          "app.web",
+			manifestWorkReplicaSetPlacementLabel: placement,
+		}},
+		Spec: workv1.ManifestWorkSpec{Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{
+			{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web", "namespace": "default"}}`)}},
+		}}},
+		Status: workv1.ManifestWorkStatus{Conditions: conditions},
+	}
+}
+
+func newReplicaSetClient(t *testing.T) *client.OCMClient {
+	return newFakeOCMClient(t,
+		&workv1alpha1.ManifestWorkReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec: workv1alpha1.ManifestWorkReplicaSetSpec{
+				ManifestWorkTemplate: workv1.ManifestWorkSpec{Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{
+					{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web", "namespace": "default"}}`)}},
+				}}},
+				PlacementRefs: []workv1alpha1.LocalPlacementReference{
+					{
+						Name: "canary",
+						RolloutStrategy: clusterv1alpha1.RolloutStrategy{
+							Type: clusterv1alpha1.Progressive,
+							Progressive: &clusterv1alpha1.RolloutProgressive{
+								RolloutConfig: clusterv1alpha1.RolloutConfig{
+									MinSuccessTime:   metav1.Duration{Duration: 5 * time.Minute},
+									ProgressDeadline: "10m",
+									MaxFailures:      intstr.FromString("10%"),
+								},
+								MandatoryDecisionGroups: clusterv1alpha1.MandatoryDecisionGroups{MandatoryDecisionGroups: []clusterv1alpha1.MandatoryDecisionGroup{{GroupName: "canary"}}},
+								MaxConcurrency:          intstr.FromInt(2),
+							},
+						},
+					},
+					{Name: "all"},
+				},
+			},
+			Status: workv1alpha1.ManifestWorkReplicaSetStatus{
+				Conditions: []metav1.Condition{{Type: workv1alpha1.ManifestWorkReplicaSetConditionPlacementRolledOut, Status: metav1.ConditionFalse, Reason: workv1alpha1.ReasonProgressing}},
+				Summary:    workv1alpha1.ManifestWorkReplicaSetSummary{Total: 3, Applied: 2, Available: 1, Degraded: 1, Progressing: 1},
+				PlacementsSummary: []workv1alpha1.PlacementSummary{
+					{Name: "canary", AvailableDecisionGroups: "1 (1 / 1 clusters applied)", Summary: workv1alpha1.ManifestWorkReplicaSetSummary{Total: 1, Applied: 1, Available: 1}},
+				},
+			},
+		},
+		&workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"}},
+		&workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"}},
+		newReplicaSetWork("cluster2", "all",
+			metav1.Condition{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+			metav1.Condition{Type: workv1.WorkDegraded, Status: metav1.ConditionTrue},
+		),
+		newReplicaSetWork("cluster1", "canary",
+			metav1.Condition{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+			metav1.Condition{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue},
+		),
+		newReplicaSetWork("cluster3", "all", metav1.Condition{Type: workv1.WorkProgressing, Status: metav1.ConditionTrue}),
+		// Works of other replica sets or created directly are not joined
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "cluster1"}},
+	)
+}
+
+func runReplicaSetHandler(handler func(*gin.Context, *client.OCMClient, context.Context), ocmClient *client.OCMClient, params gin.Params, user *authv1.UserInfo) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = params
+	if user != nil {
+		auth.SetUser(c, user)
+	}
+	handler(c, ocmClient, context.Background())
+	return w
+}
+
+func TestGetManifestWorkReplicaSets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newReplicaSetClient(t)
+
+	tests := []struct {
+		name          string
+		handler       func(*gin.Context, *client.OCMClient, context.Context)
+		params        gin.Params
+		expectedNames []string
+	}{
+		{
+			name:          "all namespaces",
+			handler:       GetManifestWorkReplicaSets,
+			expectedNames: []string{"app/api", "app/web", "data/db"},
+		},
+		{
+			name:          "by namespace",
+			handler:       GetManifestWorkReplicaSetsByNamespace,
+			params:        gin.Params{{Key: "namespace", Value: "app"}},
+			expectedNames: []string{"app/api", "app/web"},
+		},
+		{
+			name:          "empty namespace",
+			handler:       GetManifestWorkReplicaSetsByNamespace,
+			params:        gin.Params{{Key: "namespace", Value: "other"}},
+			expectedNames: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runReplicaSetHandler(tt.handler, ocmClient, tt.params, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var replicaSets []models.ManifestWorkReplicaSet
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replicaSets))
+			names := []string{}
+			for _, replicaSet := range replicaSets {
+				names = append(names, replicaSet.Namespace+"/"+replicaSet.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestGetManifestWorkReplicaSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newReplicaSetClient(t)
+
+	w := runReplicaSetHandler(GetManifestWorkReplicaSet, ocmClient, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var replicaSet models.ManifestWorkReplicaSet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replicaSet))
+	assert.Equal(t, "web", replicaSet.Name)
+	require.Len(t, replicaSet.Manifests, 1)
+	assert.Equal(t, "ConfigMap", replicaSet.Manifests[0].RawExtension["kind"])
+
+	assert.Equal(t, []models.LocalPlacementReference{
+		{
+			Name: "canary",
+			RolloutStrategy: models.RolloutStrategy{
+				Type:                    "Progressive",
+				MinSuccessTime:          "5m0s",
+				ProgressDeadline:        "10m",
+				MaxFailures:             "10%",
+				MaxConcurrency:          "2",
+				MandatoryDecisionGroups: []models.MandatoryDecisionGroup{{GroupName: "canary"}},
+			},
+		},
+		{Name: "all", RolloutStrategy: models.RolloutStrategy{Type: "All"}},
+	}, replicaSet.PlacementRefs)
+
+	assert.Equal(t, models.ManifestWorkReplicaSetSummary{Total: 3, Applied: 2, Available: 1, Degraded: 1, Progressing: 1}, replicaSet.Summary)
+	require.Len(t, replicaSet.PlacementSummaries, 1)
+	assert.Equal(t, "canary", replicaSet.PlacementSummaries[0].Name)
+	assert.Equal(t, 1, replicaSet.PlacementSummaries[0].Summary.Available)
+	require.Len(t, replicaSet.Conditions, 1)
+	assert.Equal(t, "Progressing", replicaSet.Conditions[0].Reason)
+
+	require.Len(t, replicaSet.Clusters, 3)
+	assert.Equal(t, models.ManifestWorkReplicaSetCluster{
+		ClusterName:   "cluster1",
+		Placement:     "canary",
+		ManifestWork:  "app-web",
+		Applied:       true,
+		Available:     true,
+		StatusSummary: models.ManifestStatusSummary{Total: 1},
+		Conditions:    replicaSet.Clusters[0].Conditions,
+	}, replicaSet.Clusters[0])
+	assert.Len(t, replicaSet.Clusters[0].Conditions, 2)
+	assert.Equal(t, "cluster2", replicaSet.Clusters[1].ClusterName)
+	assert.True(t, replicaSet.Clusters[1].Degraded)
+	assert.False(t, replicaSet.Clusters[1].Available)
+	assert.Equal(t, "cluster3", replicaSet.Clusters[2].ClusterName)
+	assert.True(t, replicaSet.Clusters[2].Progressing)
+
+	// A replica set without works has an empty breakdown
+	w = runReplicaSetHandler(GetManifestWorkReplicaSet, ocmClient, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "api"}}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replicaSet))
+	assert.Empty(t, replicaSet.Clusters)
+	assert.Empty(t, replicaSet.PlacementRefs)
+
+	w = runReplicaSetHandler(GetManifestWorkReplicaSet, ocmClient, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "missing"}}, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestManifestWorkReplicaSetsFilterByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newReplicaSetClient(t)
+	ocmClient.Authorizer = tenantAuthorizer()
+	tenant := &authv1.UserInfo{Username: "tenant"}
+
+	w := runReplicaSetHandler(GetManifestWorkReplicaSets, ocmClient, nil, tenant)
+	require.Equal(t, http.StatusOK, w.Code)
+	var replicaSets []models.ManifestWorkReplicaSet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replicaSets))
+	assert.Empty(t, replicaSets)
+
+	w = runReplicaSetHandler(GetManifestWorkReplicaSet, ocmClient, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}, tenant)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestManifestWorkReplicaSetsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(*gin.Context, *client.OCMClient, context.Context){
+		"list":         GetManifestWorkReplicaSets,
+		"by namespace": GetManifestWorkReplicaSetsByNamespace,
+		"get":          GetManifestWorkReplicaSet,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := runReplicaSetHandler(handler, nil, gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}, nil)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/interpreter"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+// Limits of CEL selector evaluation. The cost limit matches the per-call
+// limit of Kubernetes validation rules; comprehensions check for the deadline
+// every celInterruptCheckFrequency iterations.
+const (
+	celCostLimit               = 1000000
+	celInterruptCheckFrequency = 100
+)
+
+// celLimitError is returned when a CEL expression exceeds its cost limit or
+// the evaluation deadline. It rejects the Placement, not a cluster.
+type celLimitError struct {
+	expression string
+	err        error
+}
+
+func (e *celLimitError) Error() string {
+	return fmt.Sprintf("CEL expression %q exceeded its evaluation limit: %v", e.expression, e.err)
+}
+
+// isCELLimitError reports whether err is, or wraps, a celLimitError
+func isCELLimitError(err error) bool {
+	var limitErr *celLimitError
+	return errors.As(err, &limitErr)
+}
+
+// addOnScoreFunc returns the scores an add-on reported for a cluster, as
+// maps with a name and a value
+type addOnScoreFunc func(clusterName, resourceName string) []interface{}
+
+// clusterCELSelector evaluates the CEL expressions of a Placement predicate.
+// Expressions see the cluster as the managedCluster variable and can use the
+// same helper functions as the placement controller:
+//
+//	managedCluster.scores("<addon placement score>")
+//	"<version>".versionIsGreaterThan("<version>") and versionIsLessThan
+//	"<quantity>".quantityIsGreaterThan("<quantity>") and quantityIsLessThan
+//	"<json>".parseJSON()
+type clusterCELSelector struct {
+	ctx         context.Context
+	expressions []string
+	programs    []cel.Program
+}
+
+// newClusterCELSelector compiles the expressions. A compile error or an
+// expression that does not return a bool is returned as an error. Evaluations
+// stop when ctx is done.
+func newClusterCELSelector(ctx context.Context, expressions []string, scores addOnScoreFunc) (*clusterCELSelector, error) {
+	env, err := newClusterCELEnv(scores)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := &clusterCELSelector{ctx: ctx, expressions: expressions}
+	for _, expression := range expressions {
+		ast, issues := env.Compile(expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid CEL expression %q: %v", expression, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("CEL expression %q must return a bool, not %s", expression, ast.OutputType())
+		}
+		program, err := env.Program(ast,
+			cel.CostLimit(celCostLimit),
+			cel.InterruptCheckFrequency(celInterruptCheckFrequency),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CEL expression %q: %v", expression, err)
+		}
+		selector.programs = append(selector.programs, program)
+	}
+	return selector, nil
+}
+
+// matches reports whether every expression is true for the cluster. When one
+// is not, the returned reason says which and why. An expression exceeding its
+// cost limit or the deadline returns a celLimitError.
+func (s *clusterCELSelector) matches(cluster *clusterv1.ManagedCluster) (bool, string, error) {
+	if len(s.programs) == 0 {
+		return true, "", nil
+	}
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+	if err != nil {
+		return false, fmt.Sprintf("cluster could not be converted for CEL: %v", err), nil
+	}
+
+	for i, program := range s.programs {
+		if err := s.ctx.Err(); err != nil {
+			return false, "", &celLimitError{expression: s.expressions[i], err: err}
+		}
+		result, _, err := program.ContextEval(s.ctx, map[string]interface{}{"managedCluster": object})
+		var cancelled interpreter.EvalCancelledError
+		if errors.As(err, &cancelled) || (err != nil && s.ctx.Err() != nil) {
+			return false, "", &celLimitError{expression: s.expressions[i], err: err}
+		}
+		if err != nil {
+			return false, fmt.Sprintf("CEL expression %q failed: %v", s.expressions[i], err), nil
+		}
+		if matched, ok := result.Value().(bool); !ok || !matched {
+			return false, fmt.Sprintf("CEL expression %q is false", s.expressions[i]), nil
+		}
+	}
+	return true, "", nil
+}
+
+// newClusterCELEnv creates the CEL environment for cluster selectors
+func newClusterCELEnv(scores addOnScoreFunc) (*cel.Env, error) {
+	clusterType := cel.MapType(cel.StringType, cel.DynType)
+
+	return cel.NewEnv(
+		cel.Variable("managedCluster", clusterType),
+		ext.Strings(),
+		cel.Function("scores",
+			cel.MemberOverload("managedcluster_scores_string", []*cel.Type{clusterType, cel.StringType}, cel.ListType(cel.DynType),
+				cel.BinaryBinding(func(cluster, resourceName ref.Val) ref.Val {
+					name, err := celClusterName(cluster)
+					if err != nil {
+						return types.NewErr("%v", err)
+					}
+					return types.DefaultTypeAdapter.NativeToValue(scores(name, resourceName.Value().(string)))
+				}),
+			),
+		),
+		cel.Function("versionIsGreaterThan",
+			cel.MemberOverload("string_version_is_greater_than_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return compareCELValues(lhs, rhs, compareVersions, 1)
+				}),
+			),
+		),
+		cel.Function("versionIsLessThan",
+			cel.MemberOverload("string_version_is_less_than_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return compareCELValues(lhs, rhs, compareVersions, -1)
+				}),
+			),
+		),
+		cel.Function("quantityIsGreaterThan",
+			cel.MemberOverload("string_quantity_is_greater_than_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return compareCELValues(lhs, rhs, compareQuantities, 1)
+				}),
+			),
+		),
+		cel.Function("quantityIsLessThan",
+			cel.MemberOverload("string_quantity_is_less_than_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return compareCELValues(lhs, rhs, compareQuantities, -1)
+				}),
+			),
+		),
+		cel.Function("parseJSON",
+			cel.MemberOverload("string_parse_json", []*cel.Type{cel.StringType}, cel.DynType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					var parsed interface{}
+					if err := json.Unmarshal([]byte(value.Value().(string)), &parsed); err != nil {
+						return types.NewErr("failed to parse JSON: %v", err)
+					}
+					return types.DefaultTypeAdapter.NativeToValue(parsed)
+				}),
+			),
+		),
+	)
+}
+
+// celClusterName returns metadata.name of the managedCluster variable
+func celClusterName(cluster ref.Val) (string, error) {
+	object, ok := cluster.Value().(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("scores must be called on managedCluster")
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("managedCluster has no name")
+	}
+	return name, nil
+}
+
+// compareCELValues compares two string arguments and reports whether the
+// comparison returned want
+func compareCELValues(lhs, rhs ref.Val, compare func(a, b string) (int, error), want int) ref.Val {
+	a, ok := lhs.Value().(string)
+	if !ok {
+		return types.NewErr("expected a string, got %s", lhs.Type().TypeName())
+	}
+	b, ok := rhs.Value().(string)
+	if !ok {
+		return types.NewErr("expected a string, got %s", rhs.Type().TypeName())
+	}
+	result, err := compare(a, b)
+	if err != nil {
+		return types.NewErr("%v", err)
+	}
+	return types.Bool(result == want)
+}
+
+// compareVersions compares two semantic versions, a leading v is allowed
+func compareVersions(a, b string) (int, error) {
+	va, err := version.ParseGeneric(a)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(b)
+}
+
+// compareQuantities compares two resource quantities such as 4Gi or 500m
+func compareQuantities(a, b string) (int, error) {
+	qa, err := resource.ParseQuantity(a)
+	if err != nil {
+		return 0, err
+	}
+	qb, err := resource.ParseQuantity(b)
+	if err != nil {
+		return 0, err
+	}
+	return qa.Cmp(qb), nil
+}
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/gin-gonic/gin"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
@@ -17,26 +17,40 @@ import (
 // GetAllPlacementDecisions handles retrieving all placement decisions across namespaces
 func GetAllPlacementDecisions(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Use the OCM cluster client to list placement decisions
-	pdList, err := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions("").List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, placementDecisionListFields, placementDecisionListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List placement decisions from the informer cache
+	pdList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementDecisionsResource)
+
 	// Convert to our simplified PlacementDecision format
-	placementDecisions := make([]models.PlacementDecision, 0, len(pdList.Items))
-	for _, pd := range pdList.Items {
-		placementDecision := convertPlacementDecisionToModel(&pd)
+	placementDecisions := make([]models.PlacementDecision, 0, len(pdList))
+	for _, pd := range pdList {
+		if !access.allowed(pd.Namespace, pd.Name) {
+			continue
+		}
+		placementDecision := convertPlacementDecisionToModel(pd)
 		placementDecisions = append(placementDecisions, placementDecision)
 	}
 
-	c.JSON(http.StatusOK, placementDecisions)
+	sortPlacementDecisions(placementDecisions)
+
+	query.respond(c, placementDecisions)
 }
 
 // GetPlacementDecisionsByNamespace handles retrieving placement decisions in a specific namespace
@@ -44,26 +58,40 @@ func GetPlacementDecisionsByNamespace(c *gin.Context, ocmClient *client.OCMClien
 	namespace := c.Param("namespace")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Use the OCM cluster client to list placement decisions in the namespace
-	pdList, err := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions(namespace).List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, placementDecisionListFields, placementDecisionListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List placement decisions in the namespace from the informer cache
+	pdList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(namespace).List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementDecisionsResource)
+
 	// Convert to our simplified PlacementDecision format
-	placementDecisions := make([]models.PlacementDecision, 0, len(pdList.Items))
-	for _, pd := range pdList.Items {
-		placementDecision := convertPlacementDecisionToModel(&pd)
+	placementDecisions := make([]models.PlacementDecision, 0, len(pdList))
+	for _, pd := range pdList {
+		if !access.allowed(pd.Namespace, pd.Name) {
+			continue
+		}
+		placementDecision := convertPlacementDecisionToModel(pd)
 		placementDecisions = append(placementDecisions, placementDecision)
 	}
 
-	c.JSON(http.StatusOK, placementDecisions)
+	sortPlacementDecisions(placementDecisions)
+
+	query.respond(c, placementDecisions)
 }
 
 // GetPlacementDecision handles retrieving a specific placement decision
@@ -72,13 +100,19 @@ func GetPlacementDecision(c *gin.Context, ocmClient *client.OCMClient, ctx conte
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Get the specific placement decision using the OCM cluster client
-	pd, err := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions(namespace).Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, placementDecisionsResource).allowed(namespace, name) {
+		respondForbidden(c, placementDecisionsResource, name)
+		return
+	}
+
+	// Get the specific placement decision from the informer cache
+	pd, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(namespace).Get(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,31 +130,53 @@ func GetPlacementDecisionsByPlacement(c *gin.Context, ocmClient *client.OCMClien
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
+	query, err := parseListQuery(c, placementDecisionListFields, placementDecisionListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// List placement decisions for this placement
 	// We need to use a label selector to find decisions related to this placement
-	listOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("cluster.open-cluster-management.io/placement=%s", name),
-	}
+	selector := query.selectorWith(labels.Set{clusterv1beta1.PlacementLabel: name})
 
-	pdList, err := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions(namespace).List(ctx, listOptions)
+	pdList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(namespace).List(selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementDecisionsResource)
+
 	// Convert to our simplified PlacementDecision format
-	placementDecisions := make([]models.PlacementDecision, 0, len(pdList.Items))
-	for _, pd := range pdList.Items {
-		placementDecision := convertPlacementDecisionToModel(&pd)
+	placementDecisions := make([]models.PlacementDecision, 0, len(pdList))
+	for _, pd := range pdList {
+		if !access.allowed(pd.Namespace, pd.Name) {
+			continue
+		}
+		placementDecision := convertPlacementDecisionToModel(pd)
 		placementDecisions = append(placementDecisions, placementDecision)
 	}
 
-	c.JSON(http.StatusOK, placementDecisions)
+	sortPlacementDecisions(placementDecisions)
+
+	query.respond(c, placementDecisions)
+}
+
+// sortPlacementDecisions orders placement decisions by namespace and name
+func sortPlacementDecisions(placementDecisions []models.PlacementDecision) {
+	sort.Slice(placementDecisions, func(i, j int) bool {
+		if placementDecisions[i].Namespace != placementDecisions[j].Namespace {
+			return placementDecisions[i].Namespace < placementDecisions[j].Namespace
+		}
+		return placementDecisions[i].Name < placementDecisions[j].Name
+	})
 }
 
 // Helper function to convert a PlacementDecision resource to our model
@@ -145,3 +201,26 @@ func convertPlacementDecisionToModel(pd *clusterv1beta1.PlacementDecision) model
 
 	return placementDecision
 }
+
+// placementDecisionListFields are the fields the decision lists can be
+// filtered on, cluster matches any of the decided clusters
+var placementDecisionListFields = func() listFields[models.PlacementDecision] {
+	fieldFuncs := namespacedFields(
+		func(decision models.PlacementDecision) string { return decision.Namespace },
+		func(decision models.PlacementDecision) string { return decision.Name },
+	)
+	fieldFuncs["cluster"] = func(decision models.PlacementDecision) []string {
+		clusters := make([]string, 0, len(decision.Decisions))
+		for _, d := range decision.Decisions {
+			clusters = append(clusters, d.ClusterName)
+		}
+		return clusters
+	}
+	return fieldFuncs
+}()
+
+// placementDecisionListSorts are the keys the decision lists can be sorted by
+var placementDecisionListSorts = listSorts[models.PlacementDecision]{
+	"namespace": func(decision models.PlacementDecision) string { return decision.Namespace },
+	"name":      func(decision models.PlacementDecision) string { return decision.Name },
+}
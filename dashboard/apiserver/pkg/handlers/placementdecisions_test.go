@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
 func TestGetPlacementDecisionsByNamespace(t *testing.T) {
@@ -80,3 +84,57 @@ func TestGetPlacementDecision(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPlacementDecisionsByPlacementFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placement-a-decision-2",
+				Namespace: "ns1",
+				Labels:    map[string]string{clusterv1beta1.PlacementLabel: "placement-a"},
+			},
+		},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placement-a-decision-1",
+				Namespace: "ns1",
+				Labels:    map[string]string{clusterv1beta1.PlacementLabel: "placement-a"},
+			},
+		},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placement-a-decision-1",
+				Namespace: "ns2",
+				Labels:    map[string]string{clusterv1beta1.PlacementLabel: "placement-a"},
+			},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{
+		{Key: "namespace", Value: "ns1"},
+		{Key: "name", Value: "placement-a"},
+	}
+
+	GetPlacementDecisionsByPlacement(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var decisions []models.PlacementDecision
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &decisions))
+	assert.Len(t, decisions, 2)
+	assert.Equal(t, "placement-a-decision-1", decisions[0].Name)
+	assert.Equal(t, "placement-a-decision-2", decisions[1].Name)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	GetAllPlacementDecisions(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &decisions))
+	assert.Len(t, decisions, 3)
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// Stages reported by the placement explanation
+const (
+	explainStageBinding    = "ClusterSetBinding"
+	explainStageMembership = "ClusterSetMembership"
+	explainStagePredicate  = "Predicate"
+	explainStageRanking    = "Ranking"
+)
+
+// ExplainPlacement handles explaining why a placement does or does not select a cluster
+func ExplainPlacement(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	clusterName := c.Query("cluster")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
+		return
+	}
+
+	if clusterName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cluster query parameter is required"})
+		return
+	}
+
+	// Only explain placements the caller is allowed to read
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource).allowed(namespace, name) {
+		respondForbidden(c, placementsResource, name)
+		return
+	}
+	// The explanation details the cluster's labels, claims and taints
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource).allowed("", clusterName) {
+		respondForbidden(c, managedClustersResource, clusterName)
+		return
+	}
+
+	placement, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister().Placements(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("placement %s/%s not found", namespace, name)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	evalCtx, cancel := context.WithTimeout(c.Request.Context(), placementEvalTimeout)
+	defer cancel()
+	scheduler, err := newPlacementScheduler(evalCtx, ocmClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if scheduler.clustersByName()[clusterName] == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("cluster %s not found", clusterName)})
+		return
+	}
+
+	explanation, err := scheduler.explain(convertPlacementToModel(*placement), clusterName)
+	if isCELLimitError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// explain evaluates every scheduling stage of the Placement against one
+// cluster. Stages are evaluated independently, so a cluster failing an early
+// stage still shows which later stages it would pass.
+func (s *placementScheduler) explain(placement models.Placement, clusterName string) (models.PlacementExplanation, error) {
+	cluster := s.clustersByName()[clusterName]
+	existing := s.existingDecisions(placement)
+	explanation := models.PlacementExplanation{
+		Placement:   placement.Name,
+		Namespace:   placement.Namespace,
+		ClusterName: clusterName,
+		Selected:    existing[clusterName],
+		Stages:      []models.ExplanationStage{},
+	}
+
+	// Cluster set binding
+	eligible := s.eligibleClusterSets(placement)
+	bindingStage := models.ExplanationStage{Stage: explainStageBinding, Passed: len(eligible) > 0}
+	if bindingStage.Passed {
+		bindingStage.Details = fmt.Sprintf("cluster sets bound to namespace %s: %s", placement.Namespace, strings.Join(eligible, ", "))
+	} else {
+		bindingStage.Details = noClusterSetWarning(placement)
+	}
+	explanation.Stages = append(explanation.Stages, bindingStage)
+
+	// Cluster set membership
+	var memberOf []string
+	for _, name := range eligible {
+		selector, err := clusterSetSelector(s.clusterSets[name])
+		if err == nil && selector.Matches(labels.Set(cluster.Labels)) {
+			memberOf = append(memberOf, name)
+		}
+	}
+	membershipStage := models.ExplanationStage{Stage: explainStageMembership, Passed: len(memberOf) > 0}
+	switch {
+	case membershipStage.Passed:
+		membershipStage.Details = fmt.Sprintf("member of %s", strings.Join(memberOf, ", "))
+	case cluster.Labels[clusterv1beta2.ClusterSetLabel] != "":
+		membershipStage.Details = fmt.Sprintf("member of cluster set %s, which the placement cannot select from", cluster.Labels[clusterv1beta2.ClusterSetLabel])
+	default:
+		membershipStage.Details = "not a member of any cluster set the placement can select from"
+	}
+	explanation.Stages = append(explanation.Stages, membershipStage)
+
+	// Predicates, a cluster has to match one of them
+	predicates, err := s.compilePredicates(placement.Predicates)
+	if err != nil {
+		return explanation, err
+	}
+	if len(predicates) == 0 {
+		explanation.Stages = append(explanation.Stages, models.ExplanationStage{
+			Stage:   explainStagePredicate,
+			Passed:  true,
+			Details: "the placement has no predicates, every cluster matches",
+		})
+	}
+	for i, predicate := range predicates {
+		matched, reason, err := predicate.matches(cluster)
+		if err != nil {
+			return explanation, err
+		}
+		if matched {
+			reason = "matches"
+		}
+		explanation.Stages = append(explanation.Stages, models.ExplanationStage{
+			Stage:     explainStagePredicate,
+			Predicate: models.IntPtr(int32(i)),
+			Passed:    matched,
+			Details:   reason,
+		})
+	}
+
+	// Taints and tolerations
+	tolerated, reason := s.tolerated(cluster, placement.Tolerations, existing[clusterName])
+	if tolerated {
+		reason = "every taint that filters clusters is tolerated"
+		if len(cluster.Spec.Taints) == 0 {
+			reason = "the cluster has no taints"
+		}
+	}
+	explanation.Stages = append(explanation.Stages, models.ExplanationStage{Stage: stageTolerations, Passed: tolerated, Details: reason})
+
+	// Score ranking
+	simulation, err := s.schedule(placement)
+	if err != nil {
+		return explanation, err
+	}
+	explanation.Stages = append(explanation.Stages, rankingStage(simulation, clusterName))
+	for _, selected := range simulation.Selected {
+		if selected.ClusterName == clusterName {
+			explanation.WouldBeSelected = true
+		}
+	}
+
+	return explanation, nil
+}
+
+// rankingStage reports the cluster's rank among the feasible clusters
+func rankingStage(simulation models.PlacementSimulation, clusterName string) models.ExplanationStage {
+	// The simulation only reports the clusters of the eligible sets
+	stage := models.ExplanationStage{Stage: explainStageRanking, Details: "not ranked, the cluster is not in an eligible cluster set"}
+	for i, selected := range simulation.Selected {
+		if selected.ClusterName == clusterName {
+			stage.Passed = true
+			stage.Details = fmt.Sprintf("ranked %d of %d selected clusters with score %d%s", i+1, len(simulation.Selected), selected.Score, formatScores(selected.Scores))
+			return stage
+		}
+	}
+	for _, rejected := range simulation.Rejected {
+		if rejected.ClusterName != clusterName {
+			continue
+		}
+		if rejected.Stage == stageNumberOfClusters {
+			stage.Details = rejected.Reason
+		} else {
+			stage.Details = fmt.Sprintf("not ranked, the cluster is rejected at the %s stage", rejected.Stage)
+		}
+	}
+	return stage
+}
+
+// formatScores lists prioritizer scores by prioritizer name
+func formatScores(scores map[string]int64) string {
+	if len(scores) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %d", name, scores[name]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
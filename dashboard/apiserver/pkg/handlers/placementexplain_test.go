@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newExplainClient adds a placement selecting one eu cluster, currently
+// deciding cluster1, to the simulation hub
+func newExplainClient(t *testing.T) *client.OCMClient {
+	ocmClient := newSimulationClient(t)
+	numberOfClusters := int32(1)
+	store := ocmClient.ClusterInformerFactory.Cluster().V1beta1()
+	require.NoError(t, store.Placements().Informer().GetStore().Add(&clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: clusterv1beta1.PlacementSpec{
+			NumberOfClusters: &numberOfClusters,
+			Predicates: []clusterv1beta1.ClusterPredicate{
+				{RequiredClusterSelector: clusterv1beta1.ClusterSelector{
+					LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+				}},
+				{RequiredClusterSelector: clusterv1beta1.ClusterSelector{
+					LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+				}},
+			},
+		},
+	}))
+	require.NoError(t, store.PlacementDecisions().Informer().GetStore().Add(&clusterv1beta1.PlacementDecision{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-decision-1", Namespace: "app", Labels: map[string]string{clusterv1beta1.PlacementLabel: "web"}},
+		Status:     clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster1"}}},
+	}))
+	return ocmClient
+}
+
+func runExplain(ocmClient *client.OCMClient, name, cluster string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: name}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/namespaces/app/placements/"+name+"/explain?cluster="+cluster, nil)
+	ExplainPlacement(c, ocmClient, c.Request.Context())
+	return w
+}
+
+// stageResults maps each stage, predicates by index, to whether it passed
+func stageResults(explanation models.PlacementExplanation) map[string]bool {
+	results := map[string]bool{}
+	for _, stage := range explanation.Stages {
+		key := stage.Stage
+		if stage.Predicate != nil {
+			key = fmt.Sprintf("%s/%d", key, *stage.Predicate)
+		}
+		results[key] = stage.Passed
+	}
+	return results
+}
+
+func TestExplainPlacement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		cluster         string
+		selected        bool
+		wouldBeSelected bool
+		expectedStages  map[string]bool
+	}{
+		{
+			name:            "current decision keeps its place",
+			cluster:         "cluster1",
+			selected:        true,
+			wouldBeSelected: true,
+			expectedStages: map[string]bool{
+				explainStageBinding: true, explainStageMembership: true,
+				"Predicate/0": false, "Predicate/1": true,
+				stageTolerations: true, explainStageRanking: true,
+			},
+		},
+		{
+			name:    "outranked by the current decision",
+			cluster: "cluster2",
+			expectedStages: map[string]bool{
+				explainStageBinding: true, explainStageMembership: true,
+				"Predicate/0": true, "Predicate/1": true,
+				stageTolerations: true, explainStageRanking: false,
+			},
+		},
+		{
+			name:    "untolerated taint",
+			cluster: "cluster3",
+			expectedStages: map[string]bool{
+				explainStageBinding: true, explainStageMembership: true,
+				"Predicate/0": false, "Predicate/1": true,
+				stageTolerations: false, explainStageRanking: false,
+			},
+		},
+		{
+			name:    "cluster set not bound",
+			cluster: "cluster4",
+			expectedStages: map[string]bool{
+				explainStageBinding: true, explainStageMembership: false,
+				"Predicate/0": false, "Predicate/1": false,
+				stageTolerations: true, explainStageRanking: false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runExplain(newExplainClient(t), "web", tt.cluster)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var explanation models.PlacementExplanation
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &explanation))
+			assert.Equal(t, tt.cluster, explanation.ClusterName)
+			assert.Equal(t, tt.selected, explanation.Selected)
+			assert.Equal(t, tt.wouldBeSelected, explanation.WouldBeSelected)
+			assert.Equal(t, tt.expectedStages, stageResults(explanation))
+			for _, stage := range explanation.Stages {
+				assert.NotEmpty(t, stage.Details, stage.Stage)
+			}
+		})
+	}
+}
+
+func TestExplainPlacementErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		placement      string
+		cluster        string
+		expectedStatus int
+	}{
+		{name: "missing cluster", placement: "web", expectedStatus: http.StatusBadRequest},
+		{name: "unknown placement", placement: "missing", cluster: "cluster1", expectedStatus: http.StatusNotFound},
+		{name: "unknown cluster", placement: "web", cluster: "missing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runExplain(newExplainClient(t), tt.placement, tt.cluster)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	w := runExplain(nil, "web", "cluster1")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestExplainPlacementFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newExplainClient(t)
+	// tenantAuthorizer never allows reading placements
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/namespaces/app/placements/web/explain?cluster=cluster1", nil)
+	auth.SetUser(c, &authv1.UserInfo{Username: "alice"})
+
+	ExplainPlacement(c, ocmClient, context.Background())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Reading the placement is not enough, the cluster has to be readable too
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Resource == placementsResource || (attrs.Resource == managedClustersResource && attrs.Name == "cluster1")
+		return true, review, nil
+	})
+	ocmClient.Authorizer = auth.NewAuthorizer(kubeClient, time.Minute)
+
+	for cluster, expectedStatus := range map[string]int{"cluster1": http.StatusOK, "cluster2": http.StatusForbidden} {
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/namespaces/app/placements/web/explain?cluster="+cluster, nil)
+		auth.SetUser(c, &authv1.UserInfo{Username: "alice"})
+
+		ExplainPlacement(c, ocmClient, context.Background())
+		assert.Equal(t, expectedStatus, w.Code, cluster)
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/history"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// GetPlacementHistory handles retrieving the decision history of a placement.
+// The since and until query parameters (RFC3339) limit the time range and
+// cluster limits the events to one cluster.
+func GetPlacementHistory(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+	if ocmClient.PlacementHistory == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "placement history is not enabled, set DASHBOARD_HISTORY_PATH to record it"})
+		return
+	}
+
+	var since, until time.Time
+	for param, value := range map[string]*time.Time{"since": &since, "until": &until} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s %q, expected an RFC3339 time", param, raw)})
+			return
+		}
+		*value = parsed
+	}
+
+	// History outlives the placement, so only the caller's access is checked
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource).allowed(namespace, name) {
+		respondForbidden(c, placementsResource, name)
+		return
+	}
+
+	events, err := ocmClient.PlacementHistory.Events(namespace, name, since, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cluster := c.Query("cluster")
+	result := make([]models.PlacementHistoryEvent, 0, len(events))
+	for _, event := range events {
+		if cluster != "" && event.ClusterName != cluster {
+			continue
+		}
+		result = append(result, models.PlacementHistoryEvent{
+			Time:        event.Time.Format(time.RFC3339),
+			Namespace:   namespace,
+			Placement:   name,
+			ClusterName: event.ClusterName,
+			Type:        event.Type,
+			Reason:      event.Reason,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// historyPruneInterval is how often expired history is dropped
+const historyPruneInterval = time.Hour
+
+// RecordPlacementHistory watches PlacementDecisions and records every cluster
+// added to or removed from a placement in ocmClient.PlacementHistory. Once the
+// caches have synced, all placements are compared with the clusters last
+// recorded, so changes made while the dashboard was down are recorded too.
+// Expired history, including that of deleted placements, is pruned hourly.
+func RecordPlacementHistory(ctx context.Context, ocmClient *client.OCMClient) error {
+	recorder := &placementHistoryRecorder{
+		ocmClient: ocmClient,
+		store:     ocmClient.PlacementHistory,
+		now:       time.Now,
+	}
+
+	informers := ocmClient.ClusterInformerFactory.Cluster()
+	informer := informers.V1beta1().PlacementDecisions().Informer()
+	changed := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		if decision, ok := obj.(*clusterv1beta1.PlacementDecision); ok {
+			recorder.record(decision.Namespace, decision.Labels[clusterv1beta1.PlacementLabel], false)
+		}
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			// The initial list is compared in one go once the caches have synced
+			if !isInInitialList {
+				changed(obj)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) { changed(newObj) },
+		DeleteFunc: changed,
+	})
+	if err != nil {
+		return err
+	}
+
+	synced := []cache.InformerSynced{
+		informer.HasSynced,
+		informers.V1().ManagedClusters().Informer().HasSynced,
+		informers.V1beta1().Placements().Informer().HasSynced,
+	}
+	go func() {
+		if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+			return
+		}
+		recorder.recordAll()
+
+		ticker := time.NewTicker(historyPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				recorder.prune()
+			}
+		}
+	}()
+	return nil
+}
+
+// placementHistoryRecorder compares the clusters selected by a placement's
+// decisions with the clusters last recorded for it
+type placementHistoryRecorder struct {
+	ocmClient *client.OCMClient
+	store     *history.Store
+	now       func() time.Time
+
+	mu sync.Mutex
+}
+
+// recordAll records the changes of every placement with decisions or history.
+// Without any history, as on the first run, the current clusters are stored
+// as the baseline instead of being recorded as added.
+func (r *placementHistoryRecorder) recordAll() {
+	placements := map[[2]string]bool{}
+	decisions, err := r.ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().List(labels.Everything())
+	if err != nil {
+		log.Printf("Failed to list placement decisions for history: %v", err)
+		return
+	}
+	for _, decision := range decisions {
+		if name := decision.Labels[clusterv1beta1.PlacementLabel]; name != "" {
+			placements[[2]string{decision.Namespace, name}] = true
+		}
+	}
+	recorded, err := r.store.Placements()
+	if err != nil {
+		log.Printf("Failed to list placement history: %v", err)
+		return
+	}
+	for _, placement := range recorded {
+		placements[placement] = true
+	}
+
+	baseline := len(recorded) == 0
+	for placement := range placements {
+		r.record(placement[0], placement[1], baseline)
+	}
+	r.prune()
+}
+
+// prune drops expired events and the history of deleted placements
+func (r *placementHistoryRecorder) prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	placements := r.ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister()
+	err := r.store.Prune(func(namespace, name string) bool {
+		_, err := placements.Placements(namespace).Get(name)
+		return !apierrors.IsNotFound(err)
+	})
+	if err != nil {
+		log.Printf("Failed to prune placement history: %v", err)
+	}
+}
+
+// record compares the placement's current decisions with the clusters last
+// recorded and stores an event for every cluster added or removed. A baseline
+// record of a placement without history stores its clusters without events.
+func (r *placementHistoryRecorder) record(namespace, name string, baseline bool) {
+	if name == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	selector := labels.SelectorFromSet(labels.Set{clusterv1beta1.PlacementLabel: name})
+	decisions, err := r.ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(namespace).List(selector)
+	if err != nil {
+		log.Printf("Failed to list decisions of placement %s/%s: %v", namespace, name, err)
+		return
+	}
+	current := map[string]string{}
+	for _, decision := range decisions {
+		for _, d := range decision.Status.Decisions {
+			current[d.ClusterName] = d.Reason
+		}
+	}
+
+	previous, found, err := r.store.Clusters(namespace, name)
+	if err != nil {
+		log.Printf("Failed to read history of placement %s/%s: %v", namespace, name, err)
+		return
+	}
+
+	now := r.now()
+	var events []history.Event
+	for cluster, reason := range current {
+		if previous[cluster] {
+			continue
+		}
+		if reason == "" {
+			reason = "selected by the placement"
+		}
+		events = append(events, history.Event{Time: now, Type: history.EventAdded, ClusterName: cluster, Reason: reason})
+	}
+	for cluster := range previous {
+		if _, ok := current[cluster]; ok {
+			continue
+		}
+		events = append(events, history.Event{Time: now, Type: history.EventRemoved, ClusterName: cluster, Reason: r.removalReason(namespace, name, cluster)})
+	}
+	if !found && baseline {
+		events = nil
+	}
+	if found && len(events) == 0 {
+		return
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return events[i].Type < events[j].Type
+		}
+		return events[i].ClusterName < events[j].ClusterName
+	})
+
+	clusters := make([]string, 0, len(current))
+	for cluster := range current {
+		clusters = append(clusters, cluster)
+	}
+	if err := r.store.Record(namespace, name, clusters, events); err != nil {
+		log.Printf("Failed to record history of placement %s/%s: %v", namespace, name, err)
+	}
+}
+
+// removalReason explains why a cluster left the placement's decisions, using
+// the first scheduling stage the cluster now fails
+func (r *placementHistoryRecorder) removalReason(namespace, name, cluster string) string {
+	informers := r.ocmClient.ClusterInformerFactory.Cluster()
+	if _, err := informers.V1().ManagedClusters().Lister().Get(cluster); apierrors.IsNotFound(err) {
+		return "the ManagedCluster was deleted"
+	}
+	placement, err := informers.V1beta1().Placements().Lister().Placements(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return "the Placement was deleted"
+	}
+	if err != nil {
+		return "no longer selected by the placement"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), placementEvalTimeout)
+	defer cancel()
+	scheduler, err := newPlacementScheduler(ctx, r.ocmClient)
+	if err != nil {
+		return "no longer selected by the placement"
+	}
+	explanation, err := scheduler.explain(convertPlacementToModel(*placement), cluster)
+	if err != nil {
+		return "no longer selected by the placement"
+	}
+
+	// Predicates are ORed, a failed one only matters when none passed
+	predicatePassed := false
+	for _, stage := range explanation.Stages {
+		if stage.Stage == explainStagePredicate && stage.Passed {
+			predicatePassed = true
+		}
+	}
+	for _, stage := range explanation.Stages {
+		if stage.Stage == explainStagePredicate && predicatePassed {
+			continue
+		}
+		if !stage.Passed {
+			return fmt.Sprintf("%s: %s", stage.Stage, stage.Details)
+		}
+	}
+	return "no longer selected by the placement"
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/history"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+func openTestHistory(t *testing.T) *history.Store {
+	t.Helper()
+	store, err := history.Open(history.Options{Path: filepath.Join(t.TempDir(), "history.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func newHistoryDecision(clusters ...string) *clusterv1beta1.PlacementDecision {
+	decision := &clusterv1beta1.PlacementDecision{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-decision-1", Namespace: "app", Labels: map[string]string{clusterv1beta1.PlacementLabel: "web"}},
+	}
+	for _, cluster := range clusters {
+		decision.Status.Decisions = append(decision.Status.Decisions, clusterv1beta1.ClusterDecision{ClusterName: cluster})
+	}
+	return decision
+}
+
+func historyEvents(t *testing.T, store *history.Store) []history.Event {
+	t.Helper()
+	events, err := store.Events("app", "web", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	return events
+}
+
+func TestPlacementHistoryRecorder(t *testing.T) {
+	ocmClient := newSimulationClient(t)
+	ocmClient.PlacementHistory = openTestHistory(t)
+	decisions := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer().GetStore()
+	require.NoError(t, ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer().GetStore().Add(&clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+	}))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	recorder := &placementHistoryRecorder{ocmClient: ocmClient, store: ocmClient.PlacementHistory, now: func() time.Time { return now }}
+
+	// The first run stores the selected clusters without recording them as added
+	require.NoError(t, decisions.Add(newHistoryDecision("cluster1", "cluster3")))
+	recorder.recordAll()
+	assert.Empty(t, historyEvents(t, ocmClient.PlacementHistory))
+	clusters, found, err := ocmClient.PlacementHistory.Clusters("app", "web")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]bool{"cluster1": true, "cluster3": true}, clusters)
+
+	// Unchanged decisions record nothing
+	recorder.record("app", "web", false)
+	assert.Empty(t, historyEvents(t, ocmClient.PlacementHistory))
+
+	// cluster3 is removed because of its unreachable taint, cluster2 is added
+	now = now.Add(time.Hour)
+	require.NoError(t, decisions.Update(newHistoryDecision("cluster1", "cluster2")))
+	recorder.record("app", "web", false)
+	events := historyEvents(t, ocmClient.PlacementHistory)
+	require.Len(t, events, 2)
+	assert.Equal(t, history.Event{Time: now, Type: history.EventAdded, ClusterName: "cluster2", Reason: "selected by the placement"}, events[0])
+	assert.Equal(t, history.EventRemoved, events[1].Type)
+	assert.Equal(t, "cluster3", events[1].ClusterName)
+	assert.Equal(t, "Tolerations: taint cluster.open-cluster-management.io/unreachable:NoSelect is not tolerated", events[1].Reason)
+	assert.Equal(t, now, events[1].Time)
+
+	// Deleting the decision removes the remaining clusters
+	require.NoError(t, ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer().GetStore().Delete(&clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+	}))
+	require.NoError(t, decisions.Delete(newHistoryDecision()))
+	recorder.recordAll()
+	events = historyEvents(t, ocmClient.PlacementHistory)
+	require.Len(t, events, 4)
+	assert.Equal(t, "the Placement was deleted", events[3].Reason)
+
+	// Once history exists, a placement seen for the first time records its clusters as added
+	api := newHistoryDecision("cluster1")
+	api.Name, api.Labels[clusterv1beta1.PlacementLabel] = "api-decision-1", "api"
+	require.NoError(t, decisions.Add(api))
+	recorder.recordAll()
+	events, err = ocmClient.PlacementHistory.Events("app", "api", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, history.EventAdded, events[0].Type)
+}
+
+func TestPlacementHistoryRecorderPrunesDeletedPlacements(t *testing.T) {
+	ocmClient := newSimulationClient(t)
+	ocmClient.PlacementHistory = openTestHistory(t)
+	require.NoError(t, ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer().GetStore().Add(&clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+	}))
+	recorder := &placementHistoryRecorder{ocmClient: ocmClient, store: ocmClient.PlacementHistory, now: time.Now}
+
+	// Neither placement selects a cluster or has events left, only web still exists
+	require.NoError(t, ocmClient.PlacementHistory.Record("app", "web", nil, nil))
+	require.NoError(t, ocmClient.PlacementHistory.Record("app", "deleted", nil, nil))
+	require.NoError(t, ocmClient.PlacementHistory.Record("app", "with-events", nil, []history.Event{{Time: time.Now(), Type: history.EventRemoved, ClusterName: "cluster1"}}))
+
+	recorder.prune()
+	placements, err := ocmClient.PlacementHistory.Placements()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][2]string{{"app", "web"}, {"app", "with-events"}}, placements)
+}
+
+func TestRecordPlacementHistoryWatchesDecisions(t *testing.T) {
+	ocmClient := newFakeOCMClient(t)
+	ocmClient.PlacementHistory = openTestHistory(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	decisions := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions("app")
+	decision, err := decisions.Create(ctx, newHistoryDecision("cluster1"), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, RecordPlacementHistory(ctx, ocmClient))
+	ocmClient.ClusterInformerFactory.Start(ctx.Done())
+
+	// The initial list is stored as the baseline once the caches have synced
+	require.Eventually(t, func() bool {
+		_, found, err := ocmClient.PlacementHistory.Clusters("app", "web")
+		return err == nil && found
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, historyEvents(t, ocmClient.PlacementHistory))
+
+	decision.Status.Decisions = nil
+	_, err = decisions.UpdateStatus(ctx, decision, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		events := historyEvents(t, ocmClient.PlacementHistory)
+		return len(events) == 1 && events[0].Type == history.EventRemoved && events[0].ClusterName == "cluster1"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestGetPlacementHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ocmClient := newFakeOCMClient(t)
+	ocmClient.PlacementHistory = openTestHistory(t)
+	require.NoError(t, ocmClient.PlacementHistory.Record("app", "web", []string{"cluster1"}, []history.Event{
+		{Time: start, Type: history.EventAdded, ClusterName: "cluster1"},
+		{Time: start, Type: history.EventAdded, ClusterName: "cluster2"},
+		{Time: start.Add(time.Hour), Type: history.EventRemoved, ClusterName: "cluster2", Reason: "the ManagedCluster was deleted"},
+	}))
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedClusters []string
+	}{
+		{
+			name:             "all events",
+			expectedStatus:   http.StatusOK,
+			expectedClusters: []string{"cluster1", "cluster2", "cluster2"},
+		},
+		{
+			name:             "since",
+			query:            "?since=2025-06-01T12:30:00Z",
+			expectedStatus:   http.StatusOK,
+			expectedClusters: []string{"cluster2"},
+		},
+		{
+			name:             "until and cluster",
+			query:            "?until=2025-06-01T12:30:00Z&cluster=cluster2",
+			expectedStatus:   http.StatusOK,
+			expectedClusters: []string{"cluster2"},
+		},
+		{
+			name:           "invalid since",
+			query:          "?since=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runPlacementHistory(ocmClient, tt.query)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var events []models.PlacementHistoryEvent
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+			clusters := []string{}
+			for _, event := range events {
+				assert.Equal(t, "web", event.Placement)
+				clusters = append(clusters, event.ClusterName)
+			}
+			assert.Equal(t, tt.expectedClusters, clusters)
+		})
+	}
+}
+
+func TestGetPlacementHistoryRequiresStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runPlacementHistory(nil, "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// A hub without a history store reports the feature as not enabled
+	w = runPlacementHistory(newFakeOCMClient(t), "")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func runPlacementHistory(ocmClient *client.OCMClient, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "app"}, {Key: "name", Value: "web"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/namespaces/app/placements/web/history"+query, nil)
+	GetPlacementHistory(c, ocmClient, c.Request.Context())
+	return w
+}
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
@@ -18,26 +21,40 @@ import (
 // GetPlacements handles retrieving all placements across namespaces
 func GetPlacements(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Use the OCM cluster client to list placements
-	placementList, err := ocmClient.ClusterClient.ClusterV1beta1().Placements("").List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, placementListFields, placementListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List placements from the informer cache
+	placementList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister().List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource)
+
 	// Convert to our simplified Placement format
-	placements := make([]models.Placement, 0, len(placementList.Items))
-	for _, placement := range placementList.Items {
-		placementModel := convertPlacementToModel(placement)
+	placements := make([]models.Placement, 0, len(placementList))
+	for _, placement := range placementList {
+		if !access.allowed(placement.Namespace, placement.Name) {
+			continue
+		}
+		placementModel := convertPlacementToModel(*placement)
 		placements = append(placements, placementModel)
 	}
 
-	c.JSON(http.StatusOK, placements)
+	sortPlacements(placements)
+
+	query.respond(c, placements)
 }
 
 // GetPlacementsByNamespace handles retrieving placements for a specific namespace
@@ -45,26 +62,40 @@ func GetPlacementsByNamespace(c *gin.Context, ocmClient *client.OCMClient, ctx c
 	namespace := c.Param("namespace")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Use the OCM cluster client to list placements in the specified namespace
-	placementList, err := ocmClient.ClusterClient.ClusterV1beta1().Placements(namespace).List(ctx, metav1.ListOptions{})
+	query, err := parseListQuery(c, placementListFields, placementListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// List placements in the specified namespace from the informer cache
+	placementList, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister().Placements(namespace).List(query.selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource)
+
 	// Convert to our simplified Placement format
-	placements := make([]models.Placement, 0, len(placementList.Items))
-	for _, placement := range placementList.Items {
-		placementModel := convertPlacementToModel(placement)
+	placements := make([]models.Placement, 0, len(placementList))
+	for _, placement := range placementList {
+		if !access.allowed(placement.Namespace, placement.Name) {
+			continue
+		}
+		placementModel := convertPlacementToModel(*placement)
 		placements = append(placements, placementModel)
 	}
 
-	c.JSON(http.StatusOK, placements)
+	sortPlacements(placements)
+
+	query.respond(c, placements)
 }
 
 // GetPlacement handles retrieving a specific placement
@@ -73,13 +104,19 @@ func GetPlacement(c *gin.Context, ocmClient *client.OCMClient, ctx context.Conte
 	name := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
-	// Get the specific placement using the OCM cluster client
-	placement, err := ocmClient.ClusterClient.ClusterV1beta1().Placements(namespace).Get(ctx, name, metav1.GetOptions{})
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, placementsResource).allowed(namespace, name) {
+		respondForbidden(c, placementsResource, name)
+		return
+	}
+
+	// Get the specific placement from the informer cache
+	placement, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Lister().Placements(namespace).Get(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -97,26 +134,36 @@ func GetPlacementDecisions(c *gin.Context, ocmClient *client.OCMClient, ctx cont
 	placementName := c.Param("name")
 
 	// Ensure we have a client before proceeding
-	if ocmClient == nil || ocmClient.ClusterClient == nil {
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OCM client not initialized"})
 		return
 	}
 
+	query, err := parseListQuery(c, placementDecisionListFields, placementDecisionListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// List placement decisions for this placement
 	// We need to use a label selector to find decisions related to this placement
-	listOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("cluster.open-cluster-management.io/placement=%s", placementName),
-	}
+	selector := query.selectorWith(labels.Set{clusterv1beta1.PlacementLabel: placementName})
 
-	list, err := ocmClient.ClusterClient.ClusterV1beta1().PlacementDecisions(namespace).List(ctx, listOptions)
+	list, err := ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Lister().PlacementDecisions(namespace).List(selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, placementDecisionsResource)
+
 	// Convert to our simplified PlacementDecision format
-	placementDecisions := make([]models.PlacementDecision, 0, len(list.Items))
-	for _, item := range list.Items {
+	placementDecisions := make([]models.PlacementDecision, 0, len(list))
+	for _, item := range list {
+		if !access.allowed(item.Namespace, item.Name) {
+			continue
+		}
 		placementDecsion := models.PlacementDecision{
 			ID:        string(item.GetUID()),
 			Name:      item.GetName(),
@@ -138,7 +185,19 @@ func GetPlacementDecisions(c *gin.Context, ocmClient *client.OCMClient, ctx cont
 		placementDecisions = append(placementDecisions, placementDecsion)
 	}
 
-	c.JSON(http.StatusOK, placementDecisions)
+	sortPlacementDecisions(placementDecisions)
+
+	query.respond(c, placementDecisions)
+}
+
+// sortPlacements orders placements by namespace and name
+func sortPlacements(placements []models.Placement) {
+	sort.Slice(placements, func(i, j int) bool {
+		if placements[i].Namespace != placements[j].Namespace {
+			return placements[i].Namespace < placements[j].Namespace
+		}
+		return placements[i].Name < placements[j].Name
+	})
 }
 
 // Helper function to convert a Placement resource to our model
@@ -160,57 +219,88 @@ func convertPlacementToModel(placement clusterv1beta1.Placement) models.Placemen
 		p.NumberOfClusters = models.IntPtr(int32(*placement.Spec.NumberOfClusters))
 	}
 
-	// Extract Predicates
-	if len(placement.Spec.Predicates) > 0 {
-		for _, predicate := range placement.Spec.Predicates {
-			modelPredicate := models.Predicate{}
+	// Extract Predicates. The v1beta1 API this server is built against has no
+	// celSelector, so CelSelector is never set here.
+	for _, predicate := range placement.Spec.Predicates {
+		modelPredicate := models.Predicate{}
+
+		labelSelector := convertLabelSelectorToModel(predicate.RequiredClusterSelector.LabelSelector)
+		claimExpressions := convertMatchExpressions(predicate.RequiredClusterSelector.ClaimSelector.MatchExpressions)
+		if labelSelector != nil || len(claimExpressions) > 0 {
+			modelPredicate.RequiredClusterSelector = &models.RequiredClusterSelector{LabelSelector: labelSelector}
+			if len(claimExpressions) > 0 {
+				modelPredicate.RequiredClusterSelector.ClaimSelector = &models.ClaimSelectorWithExpressions{
+					MatchExpressions: claimExpressions,
+				}
+			}
+		}
 
-			// Check if the RequiredClusterSelector is set with a LabelSelector
-			if predicate.RequiredClusterSelector.LabelSelector.MatchLabels != nil ||
-				len(predicate.RequiredClusterSelector.LabelSelector.MatchExpressions) > 0 {
+		p.Predicates = append(p.Predicates, modelPredicate)
+	}
 
-				modelPredicate.RequiredClusterSelector = &models.RequiredClusterSelector{}
-				modelLabelSelector := &models.LabelSelectorWithExpressions{
-					MatchLabels: predicate.RequiredClusterSelector.LabelSelector.MatchLabels,
+	// Extract PrioritizerPolicy, including AddOnPlacementScore-backed prioritizers
+	policy := placement.Spec.PrioritizerPolicy
+	if policy.Mode != "" || len(policy.Configurations) > 0 {
+		p.PrioritizerPolicy = &models.PrioritizerPolicy{Mode: string(policy.Mode)}
+		for _, config := range policy.Configurations {
+			modelConfig := models.PrioritizerConfig{Weight: config.Weight}
+			if config.ScoreCoordinate != nil {
+				modelConfig.ScoreCoordinate = &models.ScoreCoordinate{
+					Type:    config.ScoreCoordinate.Type,
+					BuiltIn: config.ScoreCoordinate.BuiltIn,
 				}
-
-				// Process label selector expressions if they exist
-				if len(predicate.RequiredClusterSelector.LabelSelector.MatchExpressions) > 0 {
-					for _, expr := range predicate.RequiredClusterSelector.LabelSelector.MatchExpressions {
-						modelExpr := models.MatchExpression{
-							Key:      expr.Key,
-							Operator: string(expr.Operator),
-							Values:   expr.Values,
-						}
-						modelLabelSelector.MatchExpressions = append(modelLabelSelector.MatchExpressions, modelExpr)
+				if config.ScoreCoordinate.AddOn != nil {
+					modelConfig.ScoreCoordinate.AddOn = &models.AddOnScore{
+						ResourceName: config.ScoreCoordinate.AddOn.ResourceName,
+						ScoreName:    config.ScoreCoordinate.AddOn.ScoreName,
 					}
 				}
-
-				modelPredicate.RequiredClusterSelector.LabelSelector = modelLabelSelector
 			}
+			p.PrioritizerPolicy.Configurations = append(p.PrioritizerPolicy.Configurations, modelConfig)
+		}
+	}
 
-			// Check if the RequiredClusterSelector is set with a ClaimSelector
-			if len(predicate.RequiredClusterSelector.ClaimSelector.MatchExpressions) > 0 {
-				if modelPredicate.RequiredClusterSelector == nil {
-					modelPredicate.RequiredClusterSelector = &models.RequiredClusterSelector{}
-				}
-
-				modelClaimSelector := &models.ClaimSelectorWithExpressions{}
-
-				// Process claim selector expressions
-				for _, expr := range predicate.RequiredClusterSelector.ClaimSelector.MatchExpressions {
-					modelExpr := models.MatchExpression{
-						Key:      expr.Key,
-						Operator: string(expr.Operator),
-						Values:   expr.Values,
-					}
-					modelClaimSelector.MatchExpressions = append(modelClaimSelector.MatchExpressions, modelExpr)
-				}
+	// Extract SpreadPolicy
+	if len(placement.Spec.SpreadPolicy.SpreadConstraints) > 0 {
+		p.SpreadPolicy = &models.SpreadPolicy{}
+		for _, constraint := range placement.Spec.SpreadPolicy.SpreadConstraints {
+			p.SpreadPolicy.SpreadConstraints = append(p.SpreadPolicy.SpreadConstraints, models.SpreadConstraint{
+				TopologyKey:       constraint.TopologyKey,
+				TopologyKeyType:   string(constraint.TopologyKeyType),
+				MaxSkew:           constraint.MaxSkew,
+				WhenUnsatisfiable: string(constraint.WhenUnsatisfiable),
+			})
+		}
+	}
 
-				modelPredicate.RequiredClusterSelector.ClaimSelector = modelClaimSelector
-			}
+	// Extract Tolerations
+	for _, toleration := range placement.Spec.Tolerations {
+		p.Tolerations = append(p.Tolerations, models.PlacementToleration{
+			Key:               toleration.Key,
+			Operator:          string(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            string(toleration.Effect),
+			TolerationSeconds: toleration.TolerationSeconds,
+		})
+	}
 
-			p.Predicates = append(p.Predicates, modelPredicate)
+	// Extract DecisionStrategy
+	groupStrategy := placement.Spec.DecisionStrategy.GroupStrategy
+	clustersPerDecisionGroup := ""
+	if groupStrategy.ClustersPerDecisionGroup != (intstr.IntOrString{}) {
+		clustersPerDecisionGroup = groupStrategy.ClustersPerDecisionGroup.String()
+	}
+	if len(groupStrategy.DecisionGroups) > 0 || clustersPerDecisionGroup != "" {
+		p.DecisionStrategy = &models.DecisionStrategy{
+			GroupStrategy: models.GroupStrategy{ClustersPerDecisionGroup: clustersPerDecisionGroup},
+		}
+		for _, group := range groupStrategy.DecisionGroups {
+			p.DecisionStrategy.GroupStrategy.DecisionGroups = append(p.DecisionStrategy.GroupStrategy.DecisionGroups, models.DecisionGroup{
+				GroupName: group.GroupName,
+				GroupClusterSelector: models.GroupClusterSelector{
+					LabelSelector: convertLabelSelectorToModel(group.ClusterSelector.LabelSelector),
+				},
+			})
 		}
 	}
 
@@ -230,16 +320,22 @@ func convertPlacementToModel(placement clusterv1beta1.Placement) models.Placemen
 		}
 		p.Conditions = append(p.Conditions, modelCondition)
 
-		// Check if placement is satisfied
-		if condition.Type == clusterv1beta1.PlacementConditionSatisfied && condition.Status == metav1.ConditionTrue {
-			p.Satisfied = true
+		// Check if placement is satisfied, and explain why when it is not
+		if condition.Type == clusterv1beta1.PlacementConditionSatisfied {
+			if condition.Status == metav1.ConditionTrue {
+				p.Satisfied = true
+			} else if condition.Message != "" {
+				p.ReasonMessage = condition.Message
+			} else {
+				p.ReasonMessage = condition.Reason
+			}
 		}
 	}
 
 	// Extract decision groups
-	for i, group := range placement.Status.DecisionGroups {
+	for _, group := range placement.Status.DecisionGroups {
 		decisionGroup := models.DecisionGroupStatus{
-			DecisionGroupIndex: int32(i),
+			DecisionGroupIndex: group.DecisionGroupIndex,
 			DecisionGroupName:  group.DecisionGroupName,
 			Decisions:          group.Decisions,
 			ClusterCount:       int32(group.ClustersCount),
@@ -249,3 +345,47 @@ func convertPlacementToModel(placement clusterv1beta1.Placement) models.Placemen
 
 	return p
 }
+
+// convertLabelSelectorToModel converts a label selector, returning nil when it is empty
+func convertLabelSelectorToModel(selector metav1.LabelSelector) *models.LabelSelectorWithExpressions {
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return nil
+	}
+	return &models.LabelSelectorWithExpressions{
+		MatchLabels:      selector.MatchLabels,
+		MatchExpressions: convertMatchExpressions(selector.MatchExpressions),
+	}
+}
+
+// convertMatchExpressions converts label selector requirements to our model
+func convertMatchExpressions(requirements []metav1.LabelSelectorRequirement) []models.MatchExpression {
+	var expressions []models.MatchExpression
+	for _, expr := range requirements {
+		expressions = append(expressions, models.MatchExpression{
+			Key:      expr.Key,
+			Operator: string(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return expressions
+}
+
+// placementListFields are the fields the placement lists can be filtered on
+var placementListFields = func() listFields[models.Placement] {
+	fieldFuncs := namespacedFields(
+		func(placement models.Placement) string { return placement.Namespace },
+		func(placement models.Placement) string { return placement.Name },
+	)
+	fieldFuncs["satisfied"] = func(placement models.Placement) []string {
+		return []string{strconv.FormatBool(placement.Satisfied)}
+	}
+	fieldFuncs["clusterset"] = func(placement models.Placement) []string { return placement.ClusterSets }
+	return fieldFuncs
+}()
+
+// placementListSorts are the keys the placement lists can be sorted by
+var placementListSorts = listSorts[models.Placement]{
+	"namespace":         func(placement models.Placement) string { return placement.Namespace },
+	"name":              func(placement models.Placement) string { return placement.Name },
+	"creationTimestamp": func(placement models.Placement) string { return placement.CreationTimestamp },
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
@@ -180,6 +182,7 @@ func TestGetPlacementDecisions(t *testing.T) {
 func TestConvertPlacementToModel(t *testing.T) {
 	now := time.Now()
 	numberOfClusters := int32(3)
+	tolerationSeconds := int64(300)
 
 	tests := []struct {
 		name      string
@@ -213,12 +216,83 @@ func TestConvertPlacementToModel(t *testing.T) {
 										},
 									},
 								},
+								ClaimSelector: clusterv1beta1.ClusterClaimSelector{
+									MatchExpressions: []metav1.LabelSelectorRequirement{
+										{
+											Key:      "platform.open-cluster-management.io",
+											Operator: metav1.LabelSelectorOpIn,
+											Values:   []string{"AWS"},
+										},
+									},
+								},
+							},
+						},
+					},
+					PrioritizerPolicy: clusterv1beta1.PrioritizerPolicy{
+						Mode: clusterv1beta1.PrioritizerPolicyModeExact,
+						Configurations: []clusterv1beta1.PrioritizerConfig{
+							{
+								ScoreCoordinate: &clusterv1beta1.ScoreCoordinate{
+									Type:    clusterv1beta1.ScoreCoordinateTypeBuiltIn,
+									BuiltIn: "ResourceAllocatableMemory",
+								},
+								Weight: 2,
+							},
+							{
+								ScoreCoordinate: &clusterv1beta1.ScoreCoordinate{
+									Type: clusterv1beta1.ScoreCoordinateTypeAddOn,
+									AddOn: &clusterv1beta1.AddOnScore{
+										ResourceName: "resource-usage-score",
+										ScoreName:    "cpuAvailable",
+									},
+								},
+								Weight: 1,
 							},
 						},
 					},
+					SpreadPolicy: clusterv1beta1.SpreadPolicy{
+						SpreadConstraints: []clusterv1beta1.SpreadConstraintsTerm{
+							{
+								TopologyKey:       "zone",
+								TopologyKeyType:   clusterv1beta1.TopologyKeyTypeLabel,
+								MaxSkew:           1,
+								WhenUnsatisfiable: clusterv1beta1.ScheduleAnyway,
+							},
+						},
+					},
+					Tolerations: []clusterv1beta1.Toleration{
+						{
+							Key:               "cluster.open-cluster-management.io/unreachable",
+							Operator:          clusterv1beta1.TolerationOpExists,
+							TolerationSeconds: &tolerationSeconds,
+						},
+					},
+					DecisionStrategy: clusterv1beta1.DecisionStrategy{
+						GroupStrategy: clusterv1beta1.GroupStrategy{
+							DecisionGroups: []clusterv1beta1.DecisionGroup{
+								{
+									GroupName: "canary",
+									ClusterSelector: clusterv1beta1.ClusterSelector{
+										LabelSelector: metav1.LabelSelector{
+											MatchLabels: map[string]string{"canary": "true"},
+										},
+									},
+								},
+							},
+							ClustersPerDecisionGroup: intstr.FromString("25%"),
+						},
+					},
 				},
 				Status: clusterv1beta1.PlacementStatus{
 					NumberOfSelectedClusters: 2,
+					DecisionGroups: []clusterv1beta1.DecisionGroupStatus{
+						{
+							DecisionGroupIndex: 1,
+							DecisionGroupName:  "canary",
+							Decisions:          []string{"test-placement-decision-1"},
+							ClustersCount:      2,
+						},
+					},
 					Conditions: []metav1.Condition{
 						{
 							Type:               string(clusterv1beta1.PlacementConditionSatisfied),
@@ -231,14 +305,124 @@ func TestConvertPlacementToModel(t *testing.T) {
 				},
 			},
 			expected: models.Placement{
-				ID:                       "test-uid",
-				Name:                     "test-placement",
-				Namespace:                "test-namespace",
-				CreationTimestamp:        now.Format(time.RFC3339),
-				ClusterSets:              []string{"clusterset1", "clusterset2"},
-				NumberOfClusters:         &numberOfClusters,
+				ID:                "test-uid",
+				Name:              "test-placement",
+				Namespace:         "test-namespace",
+				CreationTimestamp: now.Format(time.RFC3339),
+				ClusterSets:       []string{"clusterset1", "clusterset2"},
+				NumberOfClusters:  &numberOfClusters,
+				Predicates: []models.Predicate{
+					{
+						RequiredClusterSelector: &models.RequiredClusterSelector{
+							LabelSelector: &models.LabelSelectorWithExpressions{
+								MatchLabels: map[string]string{"env": "prod"},
+								MatchExpressions: []models.MatchExpression{
+									{Key: "region", Operator: "In", Values: []string{"us-east-1", "us-west-2"}},
+								},
+							},
+							ClaimSelector: &models.ClaimSelectorWithExpressions{
+								MatchExpressions: []models.MatchExpression{
+									{Key: "platform.open-cluster-management.io", Operator: "In", Values: []string{"AWS"}},
+								},
+							},
+						},
+					},
+				},
+				PrioritizerPolicy: &models.PrioritizerPolicy{
+					Mode: "Exact",
+					Configurations: []models.PrioritizerConfig{
+						{
+							ScoreCoordinate: &models.ScoreCoordinate{Type: "BuiltIn", BuiltIn: "ResourceAllocatableMemory"},
+							Weight:          2,
+						},
+						{
+							ScoreCoordinate: &models.ScoreCoordinate{
+								Type:  "AddOn",
+								AddOn: &models.AddOnScore{ResourceName: "resource-usage-score", ScoreName: "cpuAvailable"},
+							},
+							Weight: 1,
+						},
+					},
+				},
+				SpreadPolicy: &models.SpreadPolicy{
+					SpreadConstraints: []models.SpreadConstraint{
+						{TopologyKey: "zone", TopologyKeyType: "Label", MaxSkew: 1, WhenUnsatisfiable: "ScheduleAnyway"},
+					},
+				},
+				Tolerations: []models.PlacementToleration{
+					{
+						Key:               "cluster.open-cluster-management.io/unreachable",
+						Operator:          "Exists",
+						TolerationSeconds: &tolerationSeconds,
+					},
+				},
+				DecisionStrategy: &models.DecisionStrategy{
+					GroupStrategy: models.GroupStrategy{
+						DecisionGroups: []models.DecisionGroup{
+							{
+								GroupName: "canary",
+								GroupClusterSelector: models.GroupClusterSelector{
+									LabelSelector: &models.LabelSelectorWithExpressions{MatchLabels: map[string]string{"canary": "true"}},
+								},
+							},
+						},
+						ClustersPerDecisionGroup: "25%",
+					},
+				},
 				NumberOfSelectedClusters: 2,
-				Satisfied:                true,
+				DecisionGroups: []models.DecisionGroupStatus{
+					{
+						DecisionGroupIndex: 1,
+						DecisionGroupName:  "canary",
+						Decisions:          []string{"test-placement-decision-1"},
+						ClusterCount:       2,
+					},
+				},
+				Satisfied: true,
+			},
+		},
+		{
+			name: "unsatisfied placement",
+			placement: clusterv1beta1.Placement{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "unsatisfied-placement",
+					Namespace:         "default",
+					UID:               types.UID("unsatisfied-uid"),
+					CreationTimestamp: metav1.Time{Time: now},
+				},
+				Spec: clusterv1beta1.PlacementSpec{
+					NumberOfClusters: &numberOfClusters,
+					DecisionStrategy: clusterv1beta1.DecisionStrategy{
+						GroupStrategy: clusterv1beta1.GroupStrategy{
+							ClustersPerDecisionGroup: intstr.FromInt32(2),
+						},
+					},
+				},
+				Status: clusterv1beta1.PlacementStatus{
+					NumberOfSelectedClusters: 1,
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(clusterv1beta1.PlacementConditionSatisfied),
+							Status:             metav1.ConditionFalse,
+							LastTransitionTime: metav1.Time{Time: now},
+							Reason:             "NotAllDecisionsScheduled",
+							Message:            "1 cluster selected, 3 requested",
+						},
+					},
+				},
+			},
+			expected: models.Placement{
+				ID:                "unsatisfied-uid",
+				Name:              "unsatisfied-placement",
+				Namespace:         "default",
+				CreationTimestamp: now.Format(time.RFC3339),
+				NumberOfClusters:  &numberOfClusters,
+				DecisionStrategy: &models.DecisionStrategy{
+					GroupStrategy: models.GroupStrategy{ClustersPerDecisionGroup: "2"},
+				},
+				NumberOfSelectedClusters: 1,
+				Satisfied:                false,
+				ReasonMessage:            "1 cluster selected, 3 requested",
 			},
 		},
 		{
@@ -274,6 +458,13 @@ func TestConvertPlacementToModel(t *testing.T) {
 			assert.Equal(t, tt.expected.NumberOfClusters, result.NumberOfClusters)
 			assert.Equal(t, tt.expected.NumberOfSelectedClusters, result.NumberOfSelectedClusters)
 			assert.Equal(t, tt.expected.Satisfied, result.Satisfied)
+			assert.Equal(t, tt.expected.ReasonMessage, result.ReasonMessage)
+			assert.Equal(t, tt.expected.Predicates, result.Predicates)
+			assert.Equal(t, tt.expected.PrioritizerPolicy, result.PrioritizerPolicy)
+			assert.Equal(t, tt.expected.SpreadPolicy, result.SpreadPolicy)
+			assert.Equal(t, tt.expected.Tolerations, result.Tolerations)
+			assert.Equal(t, tt.expected.DecisionStrategy, result.DecisionStrategy)
+			assert.Equal(t, tt.expected.DecisionGroups, result.DecisionGroups)
 
 			if len(tt.placement.Spec.Predicates) > 0 {
 				assert.NotEmpty(t, result.Predicates)
@@ -285,3 +476,80 @@ func TestConvertPlacementToModel(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPlacementsFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-b", Namespace: "ns1"}},
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-a", Namespace: "ns2"}},
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-a", Namespace: "ns1"}},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	GetPlacements(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var placements []models.Placement
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &placements))
+	assert.Len(t, placements, 3)
+	assert.Equal(t, "ns1", placements[0].Namespace)
+	assert.Equal(t, "placement-a", placements[0].Name)
+	assert.Equal(t, "ns1", placements[1].Namespace)
+	assert.Equal(t, "placement-b", placements[1].Name)
+	assert.Equal(t, "ns2", placements[2].Namespace)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "namespace", Value: "ns2"}}
+
+	GetPlacementsByNamespace(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &placements))
+	assert.Len(t, placements, 1)
+}
+
+func TestGetPlacementDecisionsFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placement-a-decision-1",
+				Namespace: "ns1",
+				Labels:    map[string]string{clusterv1beta1.PlacementLabel: "placement-a"},
+			},
+			Status: clusterv1beta1.PlacementDecisionStatus{
+				Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster1"}},
+			},
+		},
+		&clusterv1beta1.PlacementDecision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placement-b-decision-1",
+				Namespace: "ns1",
+				Labels:    map[string]string{clusterv1beta1.PlacementLabel: "placement-b"},
+			},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{
+		{Key: "namespace", Value: "ns1"},
+		{Key: "name", Value: "placement-a"},
+	}
+
+	GetPlacementDecisions(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var decisions []models.PlacementDecision
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &decisions))
+	assert.Len(t, decisions, 1)
+	assert.Equal(t, "placement-a-decision-1", decisions[0].Name)
+	assert.Equal(t, "cluster1", decisions[0].Decisions[0].ClusterName)
+}
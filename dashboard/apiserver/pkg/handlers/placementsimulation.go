@@ -0,0 +1,789 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clusterv1alpha1listers "open-cluster-management.io/api/client/cluster/listers/cluster/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// Scheduling stages reported for rejected clusters
+const (
+	stagePredicates       = "Predicates"
+	stageTolerations      = "Tolerations"
+	stageNumberOfClusters = "NumberOfClusters"
+)
+
+// Built-in prioritizers of the placement controller
+const (
+	prioritizerBalance                   = "Balance"
+	prioritizerSteady                    = "Steady"
+	prioritizerResourceAllocatableCPU    = "ResourceAllocatableCPU"
+	prioritizerResourceAllocatableMemory = "ResourceAllocatableMemory"
+	prioritizerSpread                    = "Spread"
+
+	maxClusterScore = 100
+)
+
+// placementEvalTimeout bounds the evaluation of one Placement, mostly spent in
+// its CEL expressions
+const placementEvalTimeout = 5 * time.Second
+
+// SimulatePlacement evaluates a Placement against the hub's clusters, cluster
+// sets and bindings without creating it
+func SimulatePlacement(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	var placement models.Placement
+	if err := c.ShouldBindJSON(&placement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if placement.Namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "namespace is required"})
+		return
+	}
+
+	// Anyone who may create the Placement would see its decisions anyway. Only
+	// clusters of the sets bound to the namespace are reported, selected or
+	// rejected, so the rest of the fleet is not disclosed.
+	allowed := authorize(c, ocmClient, ctx, authorizationv1.ResourceAttributes{
+		Verb:      "create",
+		Group:     clusterGroup,
+		Resource:  placementsResource,
+		Namespace: placement.Namespace,
+	})
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("creating placements in namespace %q is forbidden for the current user", placement.Namespace)})
+		return
+	}
+
+	evalCtx, cancel := context.WithTimeout(c.Request.Context(), placementEvalTimeout)
+	defer cancel()
+	scheduler, err := newPlacementScheduler(evalCtx, ocmClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	simulation, err := scheduler.schedule(placement)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
+// placementScheduler reproduces the placement controller's scheduling on the
+// informer caches: cluster set filtering, predicates, taints and tolerations,
+// prioritizers, numberOfClusters and decision groups
+type placementScheduler struct {
+	ctx         context.Context
+	clusters    []*clusterv1.ManagedCluster
+	clusterSets map[string]*clusterv1beta2.ManagedClusterSet
+	bindings    []*clusterv1beta2.ManagedClusterSetBinding
+	decisions   []*clusterv1beta1.PlacementDecision
+	scores      clusterv1alpha1listers.AddOnPlacementScoreLister
+	now         time.Time
+}
+
+// newPlacementScheduler loads the scheduling inputs from the informer caches.
+// CEL expressions stop evaluating when ctx is done.
+func newPlacementScheduler(ctx context.Context, ocmClient *client.OCMClient) (*placementScheduler, error) {
+	informers := ocmClient.ClusterInformerFactory.Cluster()
+
+	clusters, err := informers.V1().ManagedClusters().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	clusterSets, err := informers.V1beta2().ManagedClusterSets().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := informers.V1beta2().ManagedClusterSetBindings().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := informers.V1beta1().PlacementDecisions().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler := &placementScheduler{
+		ctx:         ctx,
+		clusters:    clusters,
+		clusterSets: make(map[string]*clusterv1beta2.ManagedClusterSet, len(clusterSets)),
+		bindings:    bindings,
+		decisions:   decisions,
+		scores:      informers.V1alpha1().AddOnPlacementScores().Lister(),
+		now:         time.Now(),
+	}
+	for _, clusterSet := range clusterSets {
+		scheduler.clusterSets[clusterSet.Name] = clusterSet
+	}
+
+	// Keep the evaluation order, and so the results, stable
+	sort.Slice(scheduler.clusters, func(i, j int) bool {
+		return scheduler.clusters[i].Name < scheduler.clusters[j].Name
+	})
+
+	return scheduler, nil
+}
+
+// schedule evaluates a Placement. An existing Placement with the same name
+// keeps its current decisions for the Steady prioritizer and NoSelectIfNew
+// taints. An error means the Placement itself is invalid.
+func (s *placementScheduler) schedule(placement models.Placement) (models.PlacementSimulation, error) {
+	result := models.PlacementSimulation{
+		ClusterSets:    []string{},
+		Selected:       []models.SimulatedCluster{},
+		Rejected:       []models.RejectedCluster{},
+		DecisionGroups: []models.DecisionGroupStatus{},
+	}
+
+	predicates, err := s.compilePredicates(placement.Predicates)
+	if err != nil {
+		return result, err
+	}
+	prioritizers, warnings, err := placementPrioritizers(placement.PrioritizerPolicy)
+	if err != nil {
+		return result, err
+	}
+	result.Warnings = warnings
+	groups, err := compileDecisionGroups(placement.DecisionStrategy)
+	if err != nil {
+		return result, err
+	}
+
+	// Only clusters in sets bound to the Placement's namespace can be selected
+	result.ClusterSets = s.eligibleClusterSets(placement)
+	if len(result.ClusterSets) == 0 {
+		result.Warnings = append(result.Warnings, noClusterSetWarning(placement))
+	}
+	setSelectors := make([]labels.Selector, 0, len(result.ClusterSets))
+	for _, name := range result.ClusterSets {
+		selector, err := clusterSetSelector(s.clusterSets[name])
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cluster set %s is ignored: %v", name, err))
+			continue
+		}
+		setSelectors = append(setSelectors, selector)
+	}
+
+	existing := s.existingDecisions(placement)
+	feasible := make([]*clusterv1.ManagedCluster, 0, len(s.clusters))
+	for _, cluster := range s.clusters {
+		// Clusters outside the eligible sets are not reported, the caller may
+		// not be allowed to see them
+		if !matchesAnySelector(setSelectors, cluster.Labels) {
+			continue
+		}
+		ok, reason, err := predicates.matches(cluster)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			result.Rejected = append(result.Rejected, models.RejectedCluster{ClusterName: cluster.Name, Stage: stagePredicates, Reason: reason})
+			continue
+		}
+		if ok, reason := s.tolerated(cluster, placement.Tolerations, existing[cluster.Name]); !ok {
+			result.Rejected = append(result.Rejected, models.RejectedCluster{ClusterName: cluster.Name, Stage: stageTolerations, Reason: reason})
+			continue
+		}
+		feasible = append(feasible, cluster)
+	}
+
+	// Score the feasible clusters, highest score first
+	selected := s.score(feasible, prioritizers, placement, existing)
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Score != selected[j].Score {
+			return selected[i].Score > selected[j].Score
+		}
+		return selected[i].ClusterName < selected[j].ClusterName
+	})
+
+	result.Satisfied = true
+	if placement.NumberOfClusters != nil {
+		wanted := int(*placement.NumberOfClusters)
+		if len(selected) > wanted {
+			for _, cluster := range selected[wanted:] {
+				result.Rejected = append(result.Rejected, models.RejectedCluster{
+					ClusterName: cluster.ClusterName,
+					Stage:       stageNumberOfClusters,
+					Reason:      fmt.Sprintf("score %d is not among the top %d", cluster.Score, wanted),
+				})
+			}
+			selected = selected[:wanted]
+		}
+		result.Satisfied = len(selected) >= wanted
+	}
+	result.Selected = selected
+
+	sort.Slice(result.Rejected, func(i, j int) bool {
+		return result.Rejected[i].ClusterName < result.Rejected[j].ClusterName
+	})
+
+	result.DecisionGroups = groups.split(selected, s.clustersByName())
+	return result, nil
+}
+
+// eligibleClusterSets returns the sets bound to the Placement's namespace,
+// limited to spec.clusterSets when it is set
+func (s *placementScheduler) eligibleClusterSets(placement models.Placement) []string {
+	wanted := map[string]bool{}
+	for _, name := range placement.ClusterSets {
+		wanted[name] = true
+	}
+
+	eligible := []string{}
+	seen := map[string]bool{}
+	for _, binding := range s.bindings {
+		name := binding.Spec.ClusterSet
+		if binding.Namespace != placement.Namespace || seen[name] || s.clusterSets[name] == nil {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		seen[name] = true
+		eligible = append(eligible, name)
+	}
+	sort.Strings(eligible)
+	return eligible
+}
+
+// noClusterSetWarning explains why a Placement has no cluster to select from
+func noClusterSetWarning(placement models.Placement) string {
+	if len(placement.ClusterSets) > 0 {
+		return fmt.Sprintf("none of the cluster sets %s is bound to namespace %s", strings.Join(placement.ClusterSets, ", "), placement.Namespace)
+	}
+	return fmt.Sprintf("no cluster set is bound to namespace %s", placement.Namespace)
+}
+
+// existingDecisions returns the clusters the Placement currently selects, if it exists
+func (s *placementScheduler) existingDecisions(placement models.Placement) map[string]bool {
+	existing := map[string]bool{}
+	if placement.Name == "" {
+		return existing
+	}
+	for _, decision := range s.decisions {
+		if isDecisionOf(decision, placement.Namespace, placement.Name) {
+			for _, d := range decision.Status.Decisions {
+				existing[d.ClusterName] = true
+			}
+		}
+	}
+	return existing
+}
+
+// isDecisionOf reports whether a PlacementDecision belongs to the named Placement
+func isDecisionOf(decision *clusterv1beta1.PlacementDecision, namespace, name string) bool {
+	return decision.Namespace == namespace && decision.Labels[clusterv1beta1.PlacementLabel] == name
+}
+
+func (s *placementScheduler) clustersByName() map[string]*clusterv1.ManagedCluster {
+	byName := make(map[string]*clusterv1.ManagedCluster, len(s.clusters))
+	for _, cluster := range s.clusters {
+		byName[cluster.Name] = cluster
+	}
+	return byName
+}
+
+func matchesAnySelector(selectors []labels.Selector, clusterLabels map[string]string) bool {
+	for _, selector := range selectors {
+		if selector.Matches(labels.Set(clusterLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledPredicate is one Placement predicate. All of its selectors have to match.
+type compiledPredicate struct {
+	labelSelector labels.Selector
+	claimSelector labels.Selector
+	celSelector   *clusterCELSelector
+}
+
+// compiledPredicates are ORed, a cluster has to match one of them
+type compiledPredicates []compiledPredicate
+
+// compilePredicates validates and compiles the predicates of a Placement
+func (s *placementScheduler) compilePredicates(predicates []models.Predicate) (compiledPredicates, error) {
+	compiled := make(compiledPredicates, 0, len(predicates))
+	for i, predicate := range predicates {
+		p := compiledPredicate{labelSelector: labels.Everything(), claimSelector: labels.Everything()}
+		required := predicate.RequiredClusterSelector
+		if required == nil {
+			compiled = append(compiled, p)
+			continue
+		}
+
+		if required.LabelSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(toMetaLabelSelector(required.LabelSelector.MatchLabels, required.LabelSelector.MatchExpressions))
+			if err != nil {
+				return nil, fmt.Errorf("predicate %d: invalid labelSelector: %v", i, err)
+			}
+			p.labelSelector = selector
+		}
+		if required.ClaimSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(toMetaLabelSelector(nil, required.ClaimSelector.MatchExpressions))
+			if err != nil {
+				return nil, fmt.Errorf("predicate %d: invalid claimSelector: %v", i, err)
+			}
+			p.claimSelector = selector
+		}
+		if required.CelSelector != nil && len(required.CelSelector.CelExpressions) > 0 {
+			selector, err := newClusterCELSelector(s.ctx, required.CelSelector.CelExpressions, s.addOnScores)
+			if err != nil {
+				return nil, fmt.Errorf("predicate %d: %v", i, err)
+			}
+			p.celSelector = selector
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+// matches reports whether the cluster matches any predicate. Without
+// predicates every cluster matches. An error means a CEL expression exceeded
+// its evaluation limits.
+func (p compiledPredicates) matches(cluster *clusterv1.ManagedCluster) (bool, string, error) {
+	if len(p) == 0 {
+		return true, "", nil
+	}
+
+	reasons := make([]string, 0, len(p))
+	for _, predicate := range p {
+		ok, reason, err := predicate.matches(cluster)
+		if err != nil {
+			return false, "", err
+		}
+		if ok {
+			return true, "", nil
+		}
+		reasons = append(reasons, reason)
+	}
+	if len(reasons) == 1 {
+		return false, reasons[0], nil
+	}
+	return false, "matches no predicate: " + strings.Join(reasons, "; "), nil
+}
+
+func (p compiledPredicate) matches(cluster *clusterv1.ManagedCluster) (bool, string, error) {
+	if !p.labelSelector.Matches(labels.Set(cluster.Labels)) {
+		return false, fmt.Sprintf("labels do not match %q", p.labelSelector.String()), nil
+	}
+
+	claims := labels.Set{}
+	for _, claim := range cluster.Status.ClusterClaims {
+		claims[claim.Name] = claim.Value
+	}
+	if !p.claimSelector.Matches(claims) {
+		return false, fmt.Sprintf("cluster claims do not match %q", p.claimSelector.String()), nil
+	}
+
+	if p.celSelector != nil {
+		return p.celSelector.matches(cluster)
+	}
+	return true, "", nil
+}
+
+// toMetaLabelSelector converts the model's selector fields to a metav1.LabelSelector
+func toMetaLabelSelector(matchLabels map[string]string, expressions []models.MatchExpression) *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{MatchLabels: matchLabels}
+	for _, expr := range expressions {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      expr.Key,
+			Operator: metav1.LabelSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return selector
+}
+
+// tolerated checks the cluster's taints against the tolerations. NoSelect
+// taints always have to be tolerated, NoSelectIfNew taints only when the
+// cluster is not already selected. PreferNoSelect taints never filter.
+func (s *placementScheduler) tolerated(cluster *clusterv1.ManagedCluster, tolerations []models.PlacementToleration, alreadySelected bool) (bool, string) {
+	for _, taint := range cluster.Spec.Taints {
+		switch taint.Effect {
+		case clusterv1.TaintEffectNoSelect:
+		case clusterv1.TaintEffectNoSelectIfNew:
+			if alreadySelected {
+				continue
+			}
+		default:
+			continue
+		}
+		if !s.taintTolerated(taint, tolerations) {
+			return false, fmt.Sprintf("taint %s is not tolerated", formatTaint(taint))
+		}
+	}
+	return true, ""
+}
+
+// taintTolerated reports whether a toleration matches the taint and, when it
+// has tolerationSeconds, has not expired yet
+func (s *placementScheduler) taintTolerated(taint clusterv1.Taint, tolerations []models.PlacementToleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.Key != "" && toleration.Key != taint.Key {
+			continue
+		}
+		if toleration.Effect != "" && toleration.Effect != string(taint.Effect) {
+			continue
+		}
+		switch clusterv1beta1.TolerationOperator(toleration.Operator) {
+		case clusterv1beta1.TolerationOpExists:
+		case "", clusterv1beta1.TolerationOpEqual:
+			if toleration.Value != taint.Value {
+				continue
+			}
+		default:
+			continue
+		}
+		if toleration.TolerationSeconds != nil && !taint.TimeAdded.IsZero() &&
+			taint.TimeAdded.Add(time.Duration(*toleration.TolerationSeconds)*time.Second).Before(s.now) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func formatTaint(taint clusterv1.Taint) string {
+	if taint.Value == "" {
+		return fmt.Sprintf("%s:%s", taint.Key, taint.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect)
+}
+
+// prioritizer is a built-in or add-on prioritizer with its weight
+type prioritizer struct {
+	name    string
+	builtIn string
+	addOn   *models.AddOnScore
+	weight  int64
+}
+
+// placementPrioritizers returns the prioritizers a policy enables. Additive
+// mode starts from Balance and Steady with weight 1, Exact mode only uses the
+// configured ones.
+func placementPrioritizers(policy *models.PrioritizerPolicy) ([]prioritizer, []string, error) {
+	mode := string(clusterv1beta1.PrioritizerPolicyModeAdditive)
+	var configurations []models.PrioritizerConfig
+	if policy != nil {
+		if policy.Mode != "" {
+			mode = policy.Mode
+		}
+		configurations = policy.Configurations
+	}
+
+	var prioritizers []prioritizer
+	switch clusterv1beta1.PrioritizerPolicyModeType(mode) {
+	case clusterv1beta1.PrioritizerPolicyModeAdditive:
+		prioritizers = []prioritizer{
+			{name: prioritizerBalance, builtIn: prioritizerBalance, weight: 1},
+			{name: prioritizerSteady, builtIn: prioritizerSteady, weight: 1},
+		}
+	case clusterv1beta1.PrioritizerPolicyModeExact:
+	default:
+		return nil, nil, fmt.Errorf("invalid prioritizerPolicy mode %q, must be Additive or Exact", mode)
+	}
+
+	var warnings []string
+	for _, config := range configurations {
+		if config.ScoreCoordinate == nil {
+			return nil, nil, fmt.Errorf("prioritizer configuration is missing scoreCoordinate")
+		}
+		// The API defaults an omitted weight to 1, and the model cannot tell
+		// an omitted weight from 0
+		weight := int64(config.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+
+		p := prioritizer{weight: weight}
+		coordinate := config.ScoreCoordinate
+		switch {
+		case coordinate.Type == clusterv1beta1.ScoreCoordinateTypeAddOn || (coordinate.Type == "" && coordinate.AddOn != nil):
+			if coordinate.AddOn == nil || coordinate.AddOn.ResourceName == "" || coordinate.AddOn.ScoreName == "" {
+				return nil, nil, fmt.Errorf("AddOn prioritizer requires addOn.resourceName and addOn.scoreName")
+			}
+			p.addOn = coordinate.AddOn
+			p.name = fmt.Sprintf("AddOn/%s/%s", coordinate.AddOn.ResourceName, coordinate.AddOn.ScoreName)
+		case coordinate.Type == clusterv1beta1.ScoreCoordinateTypeBuiltIn || coordinate.Type == "":
+			switch coordinate.BuiltIn {
+			case prioritizerBalance, prioritizerSteady, prioritizerResourceAllocatableCPU, prioritizerResourceAllocatableMemory:
+			case prioritizerSpread:
+				warnings = append(warnings, "the Spread prioritizer is not simulated and scores 0")
+			default:
+				return nil, nil, fmt.Errorf("invalid builtIn prioritizer %q", coordinate.BuiltIn)
+			}
+			p.builtIn = coordinate.BuiltIn
+			p.name = coordinate.BuiltIn
+		default:
+			return nil, nil, fmt.Errorf("invalid scoreCoordinate type %q, must be BuiltIn or AddOn", coordinate.Type)
+		}
+
+		// A configuration replaces the default weight of the same prioritizer
+		replaced := false
+		for i := range prioritizers {
+			if prioritizers[i].name == p.name {
+				prioritizers[i].weight = p.weight
+				replaced = true
+			}
+		}
+		if !replaced {
+			prioritizers = append(prioritizers, p)
+		}
+	}
+	return prioritizers, warnings, nil
+}
+
+// score runs the prioritizers over the feasible clusters
+func (s *placementScheduler) score(clusters []*clusterv1.ManagedCluster, prioritizers []prioritizer, placement models.Placement, existing map[string]bool) []models.SimulatedCluster {
+	scored := make([]models.SimulatedCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		scored = append(scored, models.SimulatedCluster{ClusterName: cluster.Name, Scores: map[string]int64{}})
+	}
+
+	for _, p := range prioritizers {
+		var scores map[string]int64
+		switch {
+		case p.addOn != nil:
+			scores = s.addOnPrioritizerScores(clusters, p.addOn)
+		case p.builtIn == prioritizerBalance:
+			scores = s.balanceScores(clusters, placement)
+		case p.builtIn == prioritizerSteady:
+			scores = map[string]int64{}
+			for _, cluster := range clusters {
+				if existing[cluster.Name] {
+					scores[cluster.Name] = maxClusterScore
+				}
+			}
+		case p.builtIn == prioritizerResourceAllocatableCPU:
+			scores = allocatableScores(clusters, clusterv1.ResourceCPU)
+		case p.builtIn == prioritizerResourceAllocatableMemory:
+			scores = allocatableScores(clusters, clusterv1.ResourceMemory)
+		default:
+			scores = map[string]int64{}
+		}
+
+		for i := range scored {
+			score := scores[scored[i].ClusterName]
+			scored[i].Scores[p.name] = score
+			scored[i].Score += p.weight * score
+		}
+	}
+	return scored
+}
+
+// balanceScores favours clusters with fewer decisions from other Placements.
+// The busiest cluster scores -100, a cluster without decisions 100.
+func (s *placementScheduler) balanceScores(clusters []*clusterv1.ManagedCluster, placement models.Placement) map[string]int64 {
+	counts := map[string]int{}
+	maxCount := 0
+	for _, decision := range s.decisions {
+		if isDecisionOf(decision, placement.Namespace, placement.Name) {
+			continue
+		}
+		for _, d := range decision.Status.Decisions {
+			counts[d.ClusterName]++
+			if counts[d.ClusterName] > maxCount {
+				maxCount = counts[d.ClusterName]
+			}
+		}
+	}
+
+	scores := make(map[string]int64, len(clusters))
+	for _, cluster := range clusters {
+		count, ok := counts[cluster.Name]
+		if !ok {
+			scores[cluster.Name] = maxClusterScore
+			continue
+		}
+		usage := float64(count) / float64(maxCount)
+		scores[cluster.Name] = int64(maxClusterScore - 2*usage*maxClusterScore)
+	}
+	return scores
+}
+
+// allocatableScores spreads the clusters from -100 for the least allocatable
+// resource to 100 for the most
+func allocatableScores(clusters []*clusterv1.ManagedCluster, resourceName clusterv1.ResourceName) map[string]int64 {
+	values := map[string]float64{}
+	minValue, maxValue := math.MaxFloat64, -math.MaxFloat64
+	for _, cluster := range clusters {
+		quantity, ok := cluster.Status.Allocatable[resourceName]
+		if !ok {
+			continue
+		}
+		value := quantity.AsApproximateFloat64()
+		values[cluster.Name] = value
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+	}
+
+	scores := make(map[string]int64, len(clusters))
+	for name, value := range values {
+		if maxValue == minValue {
+			scores[name] = maxClusterScore
+			continue
+		}
+		scores[name] = int64(2*maxClusterScore*(value-minValue)/(maxValue-minValue) - maxClusterScore)
+	}
+	return scores
+}
+
+// addOnPrioritizerScores reads a score from the AddOnPlacementScores the
+// add-on reported. Missing or expired scores count as 0.
+func (s *placementScheduler) addOnPrioritizerScores(clusters []*clusterv1.ManagedCluster, addOn *models.AddOnScore) map[string]int64 {
+	scores := make(map[string]int64, len(clusters))
+	for _, cluster := range clusters {
+		score, err := s.scores.AddOnPlacementScores(cluster.Name).Get(addOn.ResourceName)
+		if err != nil {
+			continue
+		}
+		if score.Status.ValidUntil != nil && score.Status.ValidUntil.Time.Before(s.now) {
+			continue
+		}
+		for _, item := range score.Status.Scores {
+			if item.Name == addOn.ScoreName {
+				scores[cluster.Name] = int64(item.Value)
+			}
+		}
+	}
+	return scores
+}
+
+// addOnScores lists the scores of an AddOnPlacementScore for CEL expressions
+func (s *placementScheduler) addOnScores(clusterName, resourceName string) []interface{} {
+	scores := []interface{}{}
+	score, err := s.scores.AddOnPlacementScores(clusterName).Get(resourceName)
+	if err != nil {
+		return scores
+	}
+	for _, item := range score.Status.Scores {
+		scores = append(scores, map[string]interface{}{"name": item.Name, "value": int64(item.Value)})
+	}
+	return scores
+}
+
+// compiledDecisionGroup is a named decision group with its cluster selector
+type compiledDecisionGroup struct {
+	name     string
+	selector labels.Selector
+}
+
+// compiledDecisionGroups splits the selected clusters into decision groups
+type compiledDecisionGroups struct {
+	groups           []compiledDecisionGroup
+	clustersPerGroup intstr.IntOrString
+}
+
+// compileDecisionGroups validates the decision strategy of a Placement
+func compileDecisionGroups(strategy *models.DecisionStrategy) (compiledDecisionGroups, error) {
+	compiled := compiledDecisionGroups{clustersPerGroup: intstr.FromString("100%")}
+	if strategy == nil {
+		return compiled, nil
+	}
+
+	groupStrategy := strategy.GroupStrategy
+	if groupStrategy.ClustersPerDecisionGroup != "" {
+		compiled.clustersPerGroup = intstr.Parse(groupStrategy.ClustersPerDecisionGroup)
+		if _, err := intstr.GetScaledValueFromIntOrPercent(&compiled.clustersPerGroup, 100, true); err != nil {
+			return compiled, fmt.Errorf("invalid clustersPerDecisionGroup %q: %v", groupStrategy.ClustersPerDecisionGroup, err)
+		}
+	}
+	for i, group := range groupStrategy.DecisionGroups {
+		selector := labels.Everything()
+		if group.GroupClusterSelector.LabelSelector != nil {
+			s, err := metav1.LabelSelectorAsSelector(toMetaLabelSelector(group.GroupClusterSelector.LabelSelector.MatchLabels, group.GroupClusterSelector.LabelSelector.MatchExpressions))
+			if err != nil {
+				return compiled, fmt.Errorf("decision group %d: invalid labelSelector: %v", i, err)
+			}
+			selector = s
+		}
+		compiled.groups = append(compiled.groups, compiledDecisionGroup{name: group.GroupName, selector: selector})
+	}
+	return compiled, nil
+}
+
+// split assigns the selected clusters to the decision groups in order, the
+// remaining clusters go to unnamed groups. Every group is split further into
+// chunks of clustersPerDecisionGroup.
+func (g compiledDecisionGroups) split(selected []models.SimulatedCluster, clusters map[string]*clusterv1.ManagedCluster) []models.DecisionGroupStatus {
+	groups := []models.DecisionGroupStatus{}
+	if len(selected) == 0 {
+		return groups
+	}
+
+	size, _ := intstr.GetScaledValueFromIntOrPercent(&g.clustersPerGroup, len(selected), true)
+	if size < 1 {
+		size = 1
+	}
+
+	grouped := map[string]bool{}
+	addChunks := func(name string, names []string) {
+		sort.Strings(names)
+		for start := 0; start < len(names); start += size {
+			end := start + size
+			if end > len(names) {
+				end = len(names)
+			}
+			groups = append(groups, models.DecisionGroupStatus{
+				DecisionGroupIndex: int32(len(groups)),
+				DecisionGroupName:  name,
+				Decisions:          names[start:end],
+				ClusterCount:       int32(end - start),
+			})
+		}
+	}
+
+	for _, group := range g.groups {
+		var names []string
+		for _, s := range selected {
+			if grouped[s.ClusterName] {
+				continue
+			}
+			cluster := clusters[s.ClusterName]
+			if cluster != nil && group.selector.Matches(labels.Set(cluster.Labels)) {
+				names = append(names, s.ClusterName)
+				grouped[s.ClusterName] = true
+			}
+		}
+		addChunks(group.name, names)
+	}
+
+	var rest []string
+	for _, s := range selected {
+		if !grouped[s.ClusterName] {
+			rest = append(rest, s.ClusterName)
+		}
+	}
+	addChunks("", rest)
+
+	return groups
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// newSimulationClient returns a hub with the clusters of the dev and prod
+// sets, with only dev bound to the app namespace
+func newSimulationClient(t *testing.T) *client.OCMClient {
+	return newFakeOCMClient(t,
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "app"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "dev"},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev", "env": "dev"}},
+			Status: clusterv1.ManagedClusterStatus{
+				Allocatable:   clusterv1.ResourceList{clusterv1.ResourceCPU: resource.MustParse("4")},
+				ClusterClaims: []clusterv1.ManagedClusterClaim{{Name: "platform.open-cluster-management.io", Value: "AWS"}},
+				Version:       clusterv1.ManagedClusterVersion{Kubernetes: "v1.30.2"},
+			},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster2", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev", "env": "dev", "region": "eu"}},
+			Status: clusterv1.ManagedClusterStatus{
+				Allocatable:   clusterv1.ResourceList{clusterv1.ResourceCPU: resource.MustParse("8")},
+				ClusterClaims: []clusterv1.ManagedClusterClaim{{Name: "platform.open-cluster-management.io", Value: "GCP"}},
+				Version:       clusterv1.ManagedClusterVersion{Kubernetes: "v1.28.0"},
+			},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster3", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "dev", "env": "dev"}},
+			Spec: clusterv1.ManagedClusterSpec{Taints: []clusterv1.Taint{{
+				Key:       clusterv1.ManagedClusterTaintUnreachable,
+				Effect:    clusterv1.TaintEffectNoSelect,
+				TimeAdded: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster4", Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "prod"}},
+		},
+		&clusterv1alpha1.AddOnPlacementScore{
+			ObjectMeta: metav1.ObjectMeta{Name: "resource-usage", Namespace: "cluster1"},
+			Status:     clusterv1alpha1.AddOnPlacementScoreStatus{Scores: []clusterv1alpha1.AddOnPlacementScoreItem{{Name: "cpu", Value: 80}}},
+		},
+		&clusterv1alpha1.AddOnPlacementScore{
+			ObjectMeta: metav1.ObjectMeta{Name: "resource-usage", Namespace: "cluster2"},
+			Status:     clusterv1alpha1.AddOnPlacementScoreStatus{Scores: []clusterv1alpha1.AddOnPlacementScoreItem{{Name: "cpu", Value: 20}}},
+		},
+	)
+}
+
+func runSimulation(ocmClient *client.OCMClient, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/placements/simulate", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	SimulatePlacement(c, ocmClient, context.Background())
+	return w
+}
+
+func selectedNames(simulation models.PlacementSimulation) []string {
+	names := []string{}
+	for _, cluster := range simulation.Selected {
+		names = append(names, cluster.ClusterName)
+	}
+	return names
+}
+
+func rejectedStages(simulation models.PlacementSimulation) map[string]string {
+	stages := map[string]string{}
+	for _, cluster := range simulation.Rejected {
+		stages[cluster.ClusterName] = cluster.Stage
+	}
+	return stages
+}
+
+func TestSimulatePlacement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedSelected []string
+		expectedRejected map[string]string
+		satisfied        bool
+	}{
+		{
+			name:             "all clusters of bound sets",
+			body:             `{"namespace": "app"}`,
+			expectedSelected: []string{"cluster1", "cluster2"},
+			expectedRejected: map[string]string{"cluster3": stageTolerations},
+			satisfied:        true,
+		},
+		{
+			name:             "unbound cluster set",
+			body:             `{"namespace": "app", "clusterSets": ["prod"]}`,
+			expectedSelected: []string{},
+			expectedRejected: map[string]string{},
+			satisfied:        true,
+		},
+		{
+			name:             "label selector",
+			body:             `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"labelSelector": {"matchExpressions": [{"key": "region", "operator": "In", "values": ["eu"]}]}}}]}`,
+			expectedSelected: []string{"cluster2"},
+			expectedRejected: map[string]string{"cluster1": stagePredicates, "cluster3": stagePredicates},
+			satisfied:        true,
+		},
+		{
+			name:             "claim selector",
+			body:             `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"claimSelector": {"matchExpressions": [{"key": "platform.open-cluster-management.io", "operator": "In", "values": ["AWS"]}]}}}]}`,
+			expectedSelected: []string{"cluster1"},
+			expectedRejected: map[string]string{"cluster2": stagePredicates, "cluster3": stagePredicates},
+			satisfied:        true,
+		},
+		{
+			name:             "CEL selector",
+			body:             `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"celSelector": {"celExpressions": ["managedCluster.status.version.kubernetes.versionIsGreaterThan(\"v1.29.0\")"]}}}]}`,
+			expectedSelected: []string{"cluster1"},
+			expectedRejected: map[string]string{"cluster2": stagePredicates, "cluster3": stagePredicates},
+			satisfied:        true,
+		},
+		{
+			name:             "CEL selector on add-on scores",
+			body:             `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"celSelector": {"celExpressions": ["managedCluster.scores(\"resource-usage\").filter(s, s.name == \"cpu\").all(s, s.value < 50)"]}}}]}`,
+			expectedSelected: []string{"cluster2"},
+			expectedRejected: map[string]string{"cluster1": stagePredicates, "cluster3": stageTolerations},
+			satisfied:        true,
+		},
+		{
+			name:             "predicates are ORed",
+			body:             `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"labelSelector": {"matchLabels": {"region": "eu"}}}}, {"requiredClusterSelector": {"claimSelector": {"matchExpressions": [{"key": "platform.open-cluster-management.io", "operator": "In", "values": ["AWS"]}]}}}]}`,
+			expectedSelected: []string{"cluster1", "cluster2"},
+			expectedRejected: map[string]string{"cluster3": stagePredicates},
+			satisfied:        true,
+		},
+		{
+			name:             "tolerated taint",
+			body:             `{"namespace": "app", "tolerations": [{"key": "cluster.open-cluster-management.io/unreachable", "operator": "Exists"}]}`,
+			expectedSelected: []string{"cluster1", "cluster2", "cluster3"},
+			expectedRejected: map[string]string{},
+			satisfied:        true,
+		},
+		{
+			name:             "expired toleration",
+			body:             `{"namespace": "app", "tolerations": [{"key": "cluster.open-cluster-management.io/unreachable", "operator": "Exists", "tolerationSeconds": 60}]}`,
+			expectedSelected: []string{"cluster1", "cluster2"},
+			expectedRejected: map[string]string{"cluster3": stageTolerations},
+			satisfied:        true,
+		},
+		{
+			name:             "number of clusters by allocatable CPU",
+			body:             `{"namespace": "app", "numberOfClusters": 1, "prioritizerPolicy": {"mode": "Exact", "configurations": [{"scoreCoordinate": {"builtIn": "ResourceAllocatableCPU"}}]}}`,
+			expectedSelected: []string{"cluster2"},
+			expectedRejected: map[string]string{"cluster1": stageNumberOfClusters, "cluster3": stageTolerations},
+			satisfied:        true,
+		},
+		{
+			name:             "number of clusters by add-on score",
+			body:             `{"namespace": "app", "numberOfClusters": 1, "prioritizerPolicy": {"mode": "Exact", "configurations": [{"scoreCoordinate": {"type": "AddOn", "addOn": {"resourceName": "resource-usage", "scoreName": "cpu"}}, "weight": 2}]}}`,
+			expectedSelected: []string{"cluster1"},
+			expectedRejected: map[string]string{"cluster2": stageNumberOfClusters, "cluster3": stageTolerations},
+			satisfied:        true,
+		},
+		{
+			name:             "not enough clusters",
+			body:             `{"namespace": "app", "numberOfClusters": 3}`,
+			expectedSelected: []string{"cluster1", "cluster2"},
+			expectedRejected: map[string]string{"cluster3": stageTolerations},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runSimulation(newSimulationClient(t), tt.body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var simulation models.PlacementSimulation
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &simulation))
+			assert.Equal(t, tt.expectedSelected, selectedNames(simulation))
+			assert.Equal(t, tt.expectedRejected, rejectedStages(simulation))
+			assert.Equal(t, tt.satisfied, simulation.Satisfied)
+			for _, rejected := range simulation.Rejected {
+				assert.NotEmpty(t, rejected.Reason)
+			}
+		})
+	}
+}
+
+func TestSimulatePlacementHidesUnboundClusters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// No set is bound to the namespace, so no cluster is reported at all
+	w := runSimulation(newSimulationClient(t), `{"namespace": "other"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var simulation models.PlacementSimulation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &simulation))
+	assert.Empty(t, simulation.Selected)
+	assert.Empty(t, simulation.Rejected)
+	assert.Equal(t, []string{"no cluster set is bound to namespace other"}, simulation.Warnings)
+}
+
+func TestSimulatePlacementScores(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newSimulationClient(t)
+	// cluster1 already carries decisions of another placement, cluster2 is
+	// selected by the simulated placement
+	for _, obj := range []*clusterv1beta1.PlacementDecision{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-decision-1", Namespace: "app", Labels: map[string]string{clusterv1beta1.PlacementLabel: "other"}},
+			Status:     clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster1"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-decision-1", Namespace: "app", Labels: map[string]string{clusterv1beta1.PlacementLabel: "web"}},
+			Status:     clusterv1beta1.PlacementDecisionStatus{Decisions: []clusterv1beta1.ClusterDecision{{ClusterName: "cluster2"}}},
+		},
+	} {
+		require.NoError(t, ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer().GetStore().Add(obj))
+	}
+
+	w := runSimulation(ocmClient, `{"name": "web", "namespace": "app"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var simulation models.PlacementSimulation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &simulation))
+	assert.Equal(t, []string{"dev"}, simulation.ClusterSets)
+	require.Len(t, simulation.Selected, 2)
+
+	assert.Equal(t, "cluster2", simulation.Selected[0].ClusterName)
+	assert.Equal(t, int64(200), simulation.Selected[0].Score)
+	assert.Equal(t, map[string]int64{prioritizerBalance: 100, prioritizerSteady: 100}, simulation.Selected[0].Scores)
+
+	assert.Equal(t, "cluster1", simulation.Selected[1].ClusterName)
+	assert.Equal(t, int64(-100), simulation.Selected[1].Score)
+	assert.Equal(t, map[string]int64{prioritizerBalance: -100, prioritizerSteady: 0}, simulation.Selected[1].Scores)
+}
+
+func TestSimulatePlacementDecisionGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runSimulation(newSimulationClient(t), `{
+		"namespace": "app",
+		"tolerations": [{"operator": "Exists"}],
+		"decisionStrategy": {"groupStrategy": {
+			"clustersPerDecisionGroup": "1",
+			"decisionGroups": [{"groupName": "canary", "groupClusterSelector": {"labelSelector": {"matchLabels": {"region": "eu"}}}}]
+		}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var simulation models.PlacementSimulation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &simulation))
+	assert.Equal(t, []models.DecisionGroupStatus{
+		{DecisionGroupIndex: 0, DecisionGroupName: "canary", Decisions: []string{"cluster2"}, ClusterCount: 1},
+		{DecisionGroupIndex: 1, Decisions: []string{"cluster1"}, ClusterCount: 1},
+		{DecisionGroupIndex: 2, Decisions: []string{"cluster3"}, ClusterCount: 1},
+	}, simulation.DecisionGroups)
+}
+
+func TestSimulatePlacementInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed body", body: `{`},
+		{name: "missing namespace", body: `{"name": "web"}`},
+		{name: "invalid label selector", body: `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"labelSelector": {"matchExpressions": [{"key": "env", "operator": "Like"}]}}}]}`},
+		{name: "invalid CEL expression", body: `{"namespace": "app", "predicates": [{"requiredClusterSelector": {"celSelector": {"celExpressions": ["managedCluster.metadata.name =="]}}}]}`},
+		{name: "unknown prioritizer", body: `{"namespace": "app", "prioritizerPolicy": {"configurations": [{"scoreCoordinate": {"builtIn": "Random"}}]}}`},
+		{name: "invalid clusters per group", body: `{"namespace": "app", "decisionStrategy": {"groupStrategy": {"clustersPerDecisionGroup": "ten"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runSimulation(newSimulationClient(t), tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+// expensiveCELExpression iterates a million times over a list of 100 numbers
+func expensiveCELExpression() string {
+	numbers := make([]string, 100)
+	for i := range numbers {
+		numbers[i] = strconv.Itoa(i)
+	}
+	list := "[" + strings.Join(numbers, ", ") + "]"
+	return fmt.Sprintf("%[1]s.all(a, %[1]s.all(b, %[1]s.all(c, a + b + c >= 0)))", list)
+}
+
+func TestSimulatePlacementCELCostLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := fmt.Sprintf(`{"namespace": "app", "predicates": [{"requiredClusterSelector": {"celSelector": {"celExpressions": [%q]}}}]}`, expensiveCELExpression())
+	w := runSimulation(newSimulationClient(t), body)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "exceeded its evaluation limit")
+}
+
+func TestClusterCELSelectorDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	selector, err := newClusterCELSelector(ctx, []string{"[1, 2, 3].all(n, n > 0)"}, func(string, string) []interface{} { return nil })
+	require.NoError(t, err)
+
+	_, _, err = selector.matches(&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}})
+	assert.True(t, isCELLimitError(err), "expected a limit error, got %v", err)
+}
+
+func TestSimulatePlacementRequiresCreatePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newSimulationClient(t)
+	// tenantAuthorizer never allows creating placements
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/placements/simulate", strings.NewReader(`{"namespace": "app"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	auth.SetUser(c, &authv1.UserInfo{Username: "alice"})
+
+	SimulatePlacement(c, ocmClient, context.Background())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSimulatePlacementRequiresClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runSimulation(nil, `{"namespace": "app"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
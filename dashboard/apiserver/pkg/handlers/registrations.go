@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+const (
+	registrationsResource      = "registrations"
+	registrationStatusPending  = "Pending"
+	registrationStatusApproved = "Approved"
+	registrationStatusDenied   = "Denied"
+)
+
+// GetRegistrations lists the clusters waiting to join the hub. A cluster is
+// listed while it has pending registration CSRs or has not been accepted yet.
+func GetRegistrations(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil || ocmClient.KubeInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	query, err := parseListQuery(c, registrationListFields, registrationListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clusterList, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	csrList, err := ocmClient.KubeInformerFactory.Certificates().V1().CertificateSigningRequests().Lister().List(labels.Everything())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Group everything by cluster name
+	clustersByName := map[string]*clusterv1.ManagedCluster{}
+	for _, cluster := range clusterList {
+		clustersByName[cluster.Name] = cluster
+	}
+	csrsByCluster := map[string][]*certificatesv1.CertificateSigningRequest{}
+	for _, csr := range csrList {
+		clusterName := csr.Labels[clusterv1.ClusterNameLabelKey]
+		if clusterName == "" || isAddonCSR(csr) {
+			continue
+		}
+		csrsByCluster[clusterName] = append(csrsByCluster[clusterName], csr)
+	}
+
+	names := map[string]bool{}
+	for name, cluster := range clustersByName {
+		if !cluster.Spec.HubAcceptsClient {
+			names[name] = true
+		}
+	}
+	for name, csrs := range csrsByCluster {
+		// A label selector matches the labels of the ManagedCluster, which
+		// clusters that only sent CSRs do not have yet
+		if !query.selector.Empty() && clustersByName[name] == nil {
+			continue
+		}
+		for _, csr := range csrs {
+			if isPendingCSR(csr) {
+				names[name] = true
+				break
+			}
+		}
+	}
+
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource)
+	csrAccess := newAccessChecker(c, ocmClient, ctx, certificatesGroup, certificateSigningRequestsResource)
+
+	now := time.Now()
+	registrations := make([]models.Registration, 0, len(names))
+	for name := range names {
+		if !access.allowed("", name) {
+			continue
+		}
+		registrations = append(registrations, buildRegistration(name, clustersByName[name], visibleCSRs(csrAccess, csrsByCluster[name]), now))
+	}
+
+	// The cache has no ordering, keep the response stable
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].ClusterName < registrations[j].ClusterName
+	})
+
+	query.respond(c, registrations)
+}
+
+// GetRegistration returns the registration state of a single cluster
+func GetRegistration(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	name := c.Param("name")
+
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.ClusterInformerFactory == nil || ocmClient.KubeInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	// Only return the resource if the caller is allowed to read it
+	if !newAccessChecker(c, ocmClient, ctx, clusterGroup, managedClustersResource).allowed("", name) {
+		respondForbidden(c, registrationsResource, name)
+		return
+	}
+
+	// The ManagedCluster is only created once the klusterlet starts registering
+	cluster, err := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Lister().Get(name)
+	if apierrors.IsNotFound(err) {
+		cluster, err = nil, nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	csrs, err := ocmClient.KubeInformerFactory.Certificates().V1().CertificateSigningRequests().Lister().List(registrationCSRSelector(name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if cluster == nil && len(csrs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("registration for cluster %q not found", name)})
+		return
+	}
+
+	csrAccess := newAccessChecker(c, ocmClient, ctx, certificatesGroup, certificateSigningRequestsResource)
+	c.JSON(http.StatusOK, buildRegistration(name, cluster, visibleCSRs(csrAccess, csrs), time.Now()))
+}
+
+// ApproveRegistration accepts a cluster and approves its pending registration CSRs
+func ApproveRegistration(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	decideRegistration(c, ocmClient, ctx, true)
+}
+
+// DenyRegistration denies the pending registration CSRs of a cluster that is
+// not accepted yet. An accepted cluster is revoked with DenyCluster instead.
+func DenyRegistration(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	decideRegistration(c, ocmClient, ctx, false)
+}
+
+// decideRegistration approves or denies a registration as the caller. The
+// ManagedCluster may not exist yet when the klusterlet is still waiting for
+// its first CSR, in which case only the CSRs are decided. Denying never
+// changes hubAcceptsClient.
+func decideRegistration(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, approve bool) {
+	name := c.Param("name")
+
+	userClients, ok := userClient(c, ocmClient)
+	if !ok {
+		return
+	}
+	if userClients.KubernetesClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	var cluster *clusterv1.ManagedCluster
+	var err error
+	if approve {
+		cluster, err = updateHubAcceptsClient(ctx, userClients, name, true)
+	} else {
+		cluster, err = userClients.ClusterClient.ClusterV1().ManagedClusters().Get(ctx, name, metav1.GetOptions{})
+	}
+	if apierrors.IsNotFound(err) {
+		cluster, err = nil, nil
+	}
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+	if !approve && cluster != nil && cluster.Spec.HubAcceptsClient {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("cluster %q is already accepted, revoke it with POST /api/clusters/%s/deny", name, name)})
+		return
+	}
+
+	skipped, err := decidePendingClusterCSRs(ctx, userClients.KubernetesClient, name, approve)
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	// Read the CSRs back from the API, the cache may not have seen the decision yet
+	csrList, err := userClients.KubernetesClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{LabelSelector: registrationCSRSelector(name).String()})
+	if err != nil {
+		respondKubeError(c, err)
+		return
+	}
+
+	if cluster == nil && len(csrList.Items) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("registration for cluster %q not found", name)})
+		return
+	}
+
+	csrs := make([]*certificatesv1.CertificateSigningRequest, 0, len(csrList.Items))
+	for i := range csrList.Items {
+		csrs = append(csrs, &csrList.Items[i])
+	}
+
+	registration := buildRegistration(name, cluster, csrs, time.Now())
+	registration.SkippedCSRs = skipped
+	c.JSON(http.StatusOK, registration)
+}
+
+// visibleCSRs returns the CSRs the caller is allowed to read
+func visibleCSRs(access accessChecker, csrs []*certificatesv1.CertificateSigningRequest) []*certificatesv1.CertificateSigningRequest {
+	visible := make([]*certificatesv1.CertificateSigningRequest, 0, len(csrs))
+	for _, csr := range csrs {
+		if access.allowed("", csr.Name) {
+			visible = append(visible, csr)
+		}
+	}
+	return visible
+}
+
+// buildRegistration combines a cluster, which may be nil, and its registration
+// CSRs into a Registration. Requests are ordered newest first.
+func buildRegistration(name string, cluster *clusterv1.ManagedCluster, csrs []*certificatesv1.CertificateSigningRequest, now time.Time) models.Registration {
+	registration := models.Registration{
+		ClusterName: name,
+		Requests:    make([]models.RegistrationRequest, 0, len(csrs)),
+	}
+
+	var created time.Time
+	if cluster != nil {
+		registration.ClusterExists = true
+		registration.HubAccepted = cluster.Spec.HubAcceptsClient
+		created = cluster.CreationTimestamp.Time
+	}
+
+	sorted := append([]*certificatesv1.CertificateSigningRequest(nil), csrs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].CreationTimestamp.Time, sorted[j].CreationTimestamp.Time
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, csr := range sorted {
+		request := models.RegistrationRequest{
+			Name:       csr.Name,
+			Username:   csr.Spec.Username,
+			Groups:     csr.Spec.Groups,
+			SignerName: csr.Spec.SignerName,
+			Status:     csrStatus(csr),
+		}
+		if !csr.CreationTimestamp.IsZero() {
+			request.CreationTimestamp = csr.CreationTimestamp.Format(time.RFC3339)
+			request.Age = duration.HumanDuration(now.Sub(csr.CreationTimestamp.Time))
+		}
+		registration.Requests = append(registration.Requests, request)
+
+		if registration.Requester == "" && request.Status == registrationStatusPending {
+			registration.Requester = csr.Spec.Username
+		}
+		// Without a ManagedCluster the registration started with its first CSR
+		if cluster == nil && !csr.CreationTimestamp.IsZero() && (created.IsZero() || csr.CreationTimestamp.Time.Before(created)) {
+			created = csr.CreationTimestamp.Time
+		}
+	}
+
+	if !created.IsZero() {
+		registration.CreationTimestamp = created.Format(time.RFC3339)
+		registration.Age = duration.HumanDuration(now.Sub(created))
+	}
+
+	return registration
+}
+
+// csrStatus returns Pending, Approved or Denied for a CSR
+func csrStatus(csr *certificatesv1.CertificateSigningRequest) string {
+	for _, condition := range csr.Status.Conditions {
+		switch condition.Type {
+		case certificatesv1.CertificateDenied:
+			return registrationStatusDenied
+		case certificatesv1.CertificateApproved:
+			return registrationStatusApproved
+		}
+	}
+	return registrationStatusPending
+}
+
+// registrationListFields are the fields the registration list can be filtered on
+var registrationListFields = listFields[models.Registration]{
+	"name": func(registration models.Registration) []string { return []string{registration.ClusterName} },
+	"hubAccepted": func(registration models.Registration) []string {
+		return []string{strconv.FormatBool(registration.HubAccepted)}
+	},
+	"clusterExists": func(registration models.Registration) []string {
+		return []string{strconv.FormatBool(registration.ClusterExists)}
+	},
+}
+
+// registrationListSorts are the keys the registration list can be sorted by
+var registrationListSorts = listSorts[models.Registration]{
+	"name":              func(registration models.Registration) string { return registration.ClusterName },
+	"creationTimestamp": func(registration models.Registration) string { return registration.CreationTimestamp },
+}
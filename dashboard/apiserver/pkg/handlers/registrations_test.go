@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+var approvedCondition = certificatesv1.CertificateSigningRequestCondition{
+	Type:   certificatesv1.CertificateApproved,
+	Status: corev1.ConditionTrue,
+}
+
+func TestGetRegistrations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	pending := newRegistrationCSR("cluster1-abcde", "cluster1")
+	pending.CreationTimestamp = created
+	pending.Spec.Username = "system:open-cluster-management:cluster1:agent"
+	pending.Spec.SignerName = certificatesv1.KubeAPIServerClientSignerName
+	addonCSR := newRegistrationCSR("addon-cluster2-abcde", "cluster2")
+	addonCSR.Labels[addonv1alpha1.AddonLabelKey] = "governance"
+
+	ocmClient := newFakeOCMClient(t,
+		// Waiting for acceptance with a pending CSR
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", CreationTimestamp: created}},
+		pending,
+		// Accepted and all CSRs approved
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}, Spec: clusterv1.ManagedClusterSpec{HubAcceptsClient: true}},
+		newRegistrationCSR("cluster2-abcde", "cluster2", approvedCondition),
+		// Pending addon CSRs are not registrations
+		addonCSR,
+		// CSR created before the ManagedCluster
+		newRegistrationCSR("cluster3-abcde", "cluster3"),
+	)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/registrations", nil)
+	GetRegistrations(c, ocmClient, c.Request.Context())
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var registrations []models.Registration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registrations))
+	require.Len(t, registrations, 2)
+
+	assert.Equal(t, "cluster1", registrations[0].ClusterName)
+	assert.True(t, registrations[0].ClusterExists)
+	assert.False(t, registrations[0].HubAccepted)
+	assert.Equal(t, "system:open-cluster-management:cluster1:agent", registrations[0].Requester)
+	assert.Equal(t, "10m", registrations[0].Age)
+	require.Len(t, registrations[0].Requests, 1)
+	assert.Equal(t, "Pending", registrations[0].Requests[0].Status)
+	assert.Equal(t, certificatesv1.KubeAPIServerClientSignerName, registrations[0].Requests[0].SignerName)
+
+	assert.Equal(t, "cluster3", registrations[1].ClusterName)
+	assert.False(t, registrations[1].ClusterExists)
+}
+
+func TestGetRegistration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		newRegistrationCSR("cluster1-abcde", "cluster1", approvedCondition),
+		newRegistrationCSR("cluster2-abcde", "cluster2"),
+	)
+
+	tests := []struct {
+		name           string
+		cluster        string
+		expectedStatus int
+		expectedExists bool
+	}{
+		{
+			name:           "cluster with CSRs",
+			cluster:        "cluster1",
+			expectedStatus: http.StatusOK,
+			expectedExists: true,
+		},
+		{
+			name:           "CSR without cluster",
+			cluster:        "cluster2",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown cluster",
+			cluster:        "missing",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "name", Value: tt.cluster}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/registrations/"+tt.cluster, nil)
+			GetRegistration(c, ocmClient, c.Request.Context())
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var registration models.Registration
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registration))
+			assert.Equal(t, tt.cluster, registration.ClusterName)
+			assert.Equal(t, tt.expectedExists, registration.ClusterExists)
+			assert.Len(t, registration.Requests, 1)
+		})
+	}
+}
+
+func TestApproveRegistration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		newRegistrationCSR("cluster1-abcde", "cluster1"),
+	)
+
+	w := runClusterAction(ApproveRegistration, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/registrations/cluster1/approve", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var registration models.Registration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registration))
+	assert.True(t, registration.HubAccepted)
+	require.Len(t, registration.Requests, 1)
+	assert.Equal(t, "Approved", registration.Requests[0].Status)
+	assert.Equal(t, certificatesv1.CertificateApproved, csrDecision(t, ocmClient, "cluster1-abcde"))
+}
+
+func TestApproveRegistrationReportsSkippedCSRs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	invalid := newRegistrationCSR("cluster1-invalid", "cluster1")
+	invalid.Spec.SignerName = certificatesv1.KubeletServingSignerName
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		invalid,
+	)
+
+	w := runClusterAction(ApproveRegistration, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/registrations/cluster1/approve", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var registration models.Registration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registration))
+	require.Len(t, registration.SkippedCSRs, 1)
+	assert.Equal(t, "cluster1-invalid", registration.SkippedCSRs[0].Name)
+	assert.Contains(t, registration.SkippedCSRs[0].Reason, "signer")
+	assert.Equal(t, certificatesv1.RequestConditionType(""), csrDecision(t, ocmClient, "cluster1-invalid"))
+}
+
+func TestDenyRegistrationWithoutCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The klusterlet has not created the ManagedCluster yet, only its CSR is decided
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		newRegistrationCSR("cluster1-abcde", "cluster1"),
+	)
+
+	w := runClusterAction(DenyRegistration, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/registrations/cluster1/deny", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var registration models.Registration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registration))
+	assert.False(t, registration.ClusterExists)
+	assert.Equal(t, certificatesv1.CertificateDenied, csrDecision(t, ocmClient, "cluster1-abcde"))
+
+	w = runClusterAction(DenyRegistration, ocmClient, gin.Params{{Key: "name", Value: "missing"}}, "/api/registrations/missing/deny", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDenyRegistrationOfAcceptedCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// An accepted cluster is revoked through the cluster actions, denying its
+	// registration leaves both the cluster and its CSRs alone
+	ocmClient := newClusterActionClient(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}, Spec: clusterv1.ManagedClusterSpec{HubAcceptsClient: true}},
+		newRegistrationCSR("cluster1-abcde", "cluster1"),
+	)
+
+	w := runClusterAction(DenyRegistration, ocmClient, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/registrations/cluster1/deny", "")
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	cluster, err := ocmClient.ClusterClient.ClusterV1().ManagedClusters().Get(context.Background(), "cluster1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, cluster.Spec.HubAcceptsClient)
+	assert.Empty(t, csrDecision(t, ocmClient, "cluster1-abcde"))
+}
+
+func TestRegistrationsRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/registrations", nil)
+	GetRegistrations(c, nil, c.Request.Context())
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = runClusterAction(ApproveRegistration, nil, gin.Params{{Key: "name", Value: "cluster1"}}, "/api/registrations/cluster1/approve", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
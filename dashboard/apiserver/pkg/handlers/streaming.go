@@ -4,188 +4,614 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/dynamic"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
 
 	"open-cluster-management-io/lab/apiserver/pkg/client"
-	"open-cluster-management-io/lab/apiserver/pkg/models"
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
 )
 
-// StreamClusters handles streaming cluster updates via SSE
-func StreamClusters(c *gin.Context, dynamicClient dynamic.Interface, ctx context.Context) {
-	// Ensure we have a client before proceeding
-	if dynamicClient == nil {
-		c.JSON(500, gin.H{"error": "Kubernetes client not initialized"})
-		return
+const (
+	// streamKeepaliveInterval is how often a comment is sent on an idle stream
+	streamKeepaliveInterval = 30 * time.Second
+
+	// streamHistorySize bounds the number of events kept for Last-Event-ID resume
+	streamHistorySize = 1024
+
+	// streamSubscriberBuffer is how far a client may fall behind before it is disconnected
+	streamSubscriberBuffer = 256
+)
+
+// Event types sent on a resource stream
+const (
+	streamEventSnapshot = "snapshot"
+	streamEventAdded    = "added"
+	streamEventModified = "modified"
+	streamEventDeleted  = "deleted"
+	streamEventError    = "error"
+)
+
+// streamEvent is a single change sent to SSE clients. The ID is the
+// resourceVersion of the object that changed; namespace and labels are kept
+// so that each subscriber can apply its own filter.
+type streamEvent struct {
+	ID   string
+	Type string
+	Data interface{}
+
+	namespace string
+	name      string
+	labels    map[string]string
+}
+
+// eventBroadcaster fans out informer events to SSE subscribers and keeps a
+// bounded history so that reconnecting clients can resume from Last-Event-ID
+type eventBroadcaster struct {
+	mu      sync.Mutex
+	history []streamEvent
+	// start is the ID of the position just before the oldest event in the
+	// history, so snapshots taken before any event are resumable too. It is
+	// unique per broadcaster, so IDs from before a restart are not mistaken for it.
+	start       string
+	subscribers map[chan streamEvent]struct{}
+}
+
+func newEventBroadcaster() *eventBroadcaster {
+	return &eventBroadcaster{
+		start:       fmt.Sprintf("start-%d", time.Now().UnixNano()),
+		subscribers: make(map[chan streamEvent]struct{}),
 	}
+}
 
-	// Set headers for SSE
-	c.Writer.Header().Set("Content-Type", "text/event-stream")
-	c.Writer.Header().Set("Cache-Control", "no-cache")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Transfer-Encoding", "chunked")
-	c.Writer.Flush()
+// publish records the event and delivers it to every subscriber. Subscribers
+// that cannot keep up are disconnected and expected to resume.
+func (b *eventBroadcaster) publish(event streamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = append(b.history, event)
+	if dropped := len(b.history) - streamHistorySize; dropped > 0 {
+		b.start = b.history[dropped-1].ID
+		b.history = b.history[dropped:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a new subscriber. If lastEventID is still in the history
+// the events after it are returned for replay and resumed is true. Otherwise the
+// caller has to send a full snapshot, tagged with latestID. latestID is never
+// empty, so a client can resume even when no event was published yet.
+func (b *eventBroadcaster) subscribe(lastEventID string) (events chan streamEvent, replay []streamEvent, resumed bool, latestID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events = make(chan streamEvent, streamSubscriberBuffer)
+	b.subscribers[events] = struct{}{}
+
+	latestID = b.start
+	if len(b.history) > 0 {
+		latestID = b.history[len(b.history)-1].ID
+	}
+
+	if lastEventID != "" && lastEventID == b.start {
+		replay = append(replay, b.history...)
+		resumed = true
+	} else if lastEventID != "" {
+		for i := len(b.history) - 1; i >= 0; i-- {
+			if b.history[i].ID == lastEventID {
+				replay = append(replay, b.history[i+1:]...)
+				resumed = true
+				break
+			}
+		}
+	}
+
+	return events, replay, resumed, latestID
+}
+
+// unsubscribe removes a subscriber if it has not already been dropped
+func (b *eventBroadcaster) unsubscribe(events chan streamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[events]; ok {
+		delete(b.subscribers, events)
+		close(events)
+	}
+}
+
+var (
+	broadcastersMu sync.Mutex
+	broadcasters   = map[cache.SharedIndexInformer]*eventBroadcaster{}
+)
+
+// broadcasterFor returns the broadcaster attached to the informer, registering
+// an event handler the first time. convert turns a cached object into the
+// model sent to clients.
+func broadcasterFor(informer cache.SharedIndexInformer, convert func(obj interface{}) (interface{}, bool)) (*eventBroadcaster, error) {
+	broadcastersMu.Lock()
+	defer broadcastersMu.Unlock()
+
+	if b, ok := broadcasters[informer]; ok {
+		return b, nil
+	}
 
-	// Create a watch for ManagedClusters
-	watcher, err := dynamicClient.Resource(client.ManagedClusterResource).Watch(ctx, metav1.ListOptions{})
+	b := newEventBroadcaster()
+	publish := func(eventType string, obj interface{}) {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		data, ok := convert(obj)
+		if !ok {
+			return
+		}
+		b.publish(streamEvent{
+			ID:        accessor.GetResourceVersion(),
+			Type:      eventType,
+			Data:      data,
+			namespace: accessor.GetNamespace(),
+			name:      accessor.GetName(),
+			labels:    accessor.GetLabels(),
+		})
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			// Objects from the initial list are already part of every snapshot
+			if isInInitialList {
+				return
+			}
+			publish(streamEventAdded, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldAccessor, oldErr := meta.Accessor(oldObj)
+			newAccessor, newErr := meta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldAccessor.GetResourceVersion() == newAccessor.GetResourceVersion() {
+				// Periodic resync, nothing changed
+				return
+			}
+			publish(streamEventModified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			publish(streamEventDeleted, obj)
+		},
+	})
 	if err != nil {
-		c.Writer.Write([]byte(fmt.Sprintf("event: error\ndata: %s\n\n", err.Error())))
-		c.Writer.Flush()
-		return
+		return nil, err
 	}
-	defer watcher.Stop()
 
-	// Send initial list of clusters
-	initialList, err := dynamicClient.Resource(client.ManagedClusterResource).List(ctx, metav1.ListOptions{})
+	broadcasters[informer] = b
+	return b, nil
+}
+
+// writeStreamEvent writes a single SSE frame and flushes it to the client
+func writeStreamEvent(c *gin.Context, event streamEvent) error {
+	data, err := json.Marshal(event.Data)
 	if err != nil {
-		c.Writer.Write([]byte(fmt.Sprintf("event: error\ndata: %s\n\n", err.Error())))
-		c.Writer.Flush()
-		return
+		return err
+	}
+
+	frame := fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data)
+	if event.ID != "" {
+		frame = fmt.Sprintf("id: %s\n", event.ID) + frame
+	}
+
+	if _, err := c.Writer.Write([]byte(frame)); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// writeStreamError sends an error event to the client
+func writeStreamError(c *gin.Context, err error) {
+	c.Writer.Write([]byte(fmt.Sprintf("event: %s\ndata: %s\n\n", streamEventError, err.Error())))
+	c.Writer.Flush()
+}
+
+// resourceStream describes a resource that can be streamed over SSE
+type resourceStream struct {
+	// informer returns the shared informer backing the stream, or nil if the
+	// required informer factory is not available
+	informer func(ocmClient *client.OCMClient) cache.SharedIndexInformer
+	// convert turns a cached object into the model sent to clients
+	convert func(obj interface{}) (interface{}, bool)
+	// namespaced reports whether the resource can be filtered by namespace
+	namespaced bool
+	// group and resource identify the resource for RBAC checks
+	group    string
+	resource string
+}
+
+// streamFilter restricts the events a subscriber receives
+type streamFilter struct {
+	namespace string
+	selector  labels.Selector
+	access    accessChecker
+}
+
+// matches reports whether an object passes the filter and may be read by the subscriber
+func (f streamFilter) matches(namespace, name string, objLabels map[string]string) bool {
+	if f.namespace != "" && f.namespace != namespace {
+		return false
+	}
+	if !f.selector.Matches(labels.Set(objLabels)) {
+		return false
+	}
+	return f.access.allowed(namespace, name)
+}
+
+// newStreamFilter builds the filter for a request. A namespace from the route
+// takes precedence over the ?namespace= query parameter, and ?labelSelector=
+// accepts the usual Kubernetes selector syntax.
+func newStreamFilter(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, stream resourceStream, namespace string) (streamFilter, error) {
+	filter := streamFilter{
+		namespace: namespace,
+		selector:  labels.Everything(),
+		access:    newAccessChecker(c, ocmClient, ctx, stream.group, stream.resource),
 	}
 
-	// Convert to our simplified Cluster format
-	clusters := make([]models.Cluster, 0, len(initialList.Items))
-	for _, item := range initialList.Items {
-		cluster, err := convertToCluster(item)
+	if filter.namespace == "" && stream.namespaced {
+		filter.namespace = c.Query("namespace")
+	}
+
+	if labelSelector := c.Query("labelSelector"); labelSelector != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return filter, err
+		}
+		filter.selector = selector
+	}
+
+	return filter, nil
+}
+
+// streamSnapshot lists the cached objects that match the filter, ordered by
+// namespace and name, and converts them for the client
+func streamSnapshot(informer cache.SharedIndexInformer, stream resourceStream, filter streamFilter) ([]interface{}, error) {
+	var objs []interface{}
+	if filter.namespace != "" {
+		var err error
+		objs, err = informer.GetIndexer().ByIndex(cache.NamespaceIndex, filter.namespace)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		objs = informer.GetIndexer().List()
+	}
+
+	type keyedObject struct {
+		namespace string
+		name      string
+		obj       interface{}
+	}
+
+	matched := make([]keyedObject, 0, len(objs))
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
 		if err != nil {
 			continue
 		}
-		clusters = append(clusters, cluster)
+		if !filter.matches(accessor.GetNamespace(), accessor.GetName(), accessor.GetLabels()) {
+			continue
+		}
+		matched = append(matched, keyedObject{namespace: accessor.GetNamespace(), name: accessor.GetName(), obj: obj})
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].namespace != matched[j].namespace {
+			return matched[i].namespace < matched[j].namespace
+		}
+		return matched[i].name < matched[j].name
+	})
+
+	items := make([]interface{}, 0, len(matched))
+	for _, m := range matched {
+		if data, ok := stream.convert(m.obj); ok {
+			items = append(items, data)
+		}
+	}
+
+	return items, nil
+}
+
+// serveStream streams a resource over SSE. The first event is a snapshot of
+// all matching objects, followed by added/modified/deleted events that carry
+// only the changed object. Clients reconnecting with Last-Event-ID (or
+// ?resourceVersion=) resume from the broadcaster history when possible.
+func serveStream(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context, stream resourceStream, namespace string) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	informer := stream.informer(ocmClient)
+	if informer == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	filter, err := newStreamFilter(c, ocmClient, ctx, stream, namespace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid labelSelector: " + err.Error()})
+		return
 	}
 
-	// Send initial data
-	data, err := json.Marshal(clusters)
+	broadcaster, err := broadcasterFor(informer, stream.convert)
 	if err != nil {
-		c.Writer.Write([]byte(fmt.Sprintf("event: error\ndata: %s\n\n", err.Error())))
-		c.Writer.Flush()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.Writer.Write([]byte(fmt.Sprintf("event: clusters\ndata: %s\n\n", data)))
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("resourceVersion")
+	}
+
+	events, replay, resumed, latestID := broadcaster.subscribe(lastEventID)
+	defer broadcaster.unsubscribe(events)
+
+	connections := metrics.StreamConnections.WithLabelValues(stream.resource)
+	connections.Inc()
+	defer connections.Dec()
+
+	// send writes an event and counts it for the stream
+	send := func(event streamEvent) error {
+		if err := writeStreamEvent(c, event); err != nil {
+			return err
+		}
+		metrics.StreamEvents.WithLabelValues(stream.resource, event.Type).Inc()
+		return nil
+	}
+
+	// Set headers for SSE
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("Transfer-Encoding", "chunked")
 	c.Writer.Flush()
 
-	// Listen for watch events
+	if resumed {
+		// Replay only what the client missed
+		for _, event := range replay {
+			if !filter.matches(event.namespace, event.name, event.labels) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	} else {
+		// Send the initial snapshot from the informer cache
+		items, err := streamSnapshot(informer, stream, filter)
+		if err != nil {
+			writeStreamError(c, err)
+			return
+		}
+		if err := send(streamEvent{ID: latestID, Type: streamEventSnapshot, Data: items}); err != nil {
+			return
+		}
+	}
+
+	keepalive := time.NewTicker(streamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	// Listen for resource events
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case event, ok := <-watcher.ResultChan():
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
 			if !ok {
-				// Channel closed, end streaming
+				// Dropped for falling behind, the client will reconnect and resume
 				return
 			}
-
-			// Process the event
-			switch event.Type {
-			case watch.Added, watch.Modified, watch.Deleted:
-				// Get updated list to ensure we have full state
-				updatedList, err := dynamicClient.Resource(client.ManagedClusterResource).List(ctx, metav1.ListOptions{})
-				if err != nil {
-					continue
-				}
-
-				// Convert again to our simplified format
-				updatedClusters := make([]models.Cluster, 0, len(updatedList.Items))
-				for _, item := range updatedList.Items {
-					cluster, err := convertToCluster(item)
-					if err != nil {
-						continue
-					}
-					updatedClusters = append(updatedClusters, cluster)
-				}
-
-				// Send updated data
-				data, err := json.Marshal(updatedClusters)
-				if err != nil {
-					continue
-				}
-				c.Writer.Write([]byte(fmt.Sprintf("event: clusters\ndata: %s\n\n", data)))
-				c.Writer.Flush()
-			case watch.Error:
-				c.Writer.Write([]byte(fmt.Sprintf("event: error\ndata: Watch error occurred\n\n")))
-				c.Writer.Flush()
+			if !filter.matches(event.namespace, event.name, event.labels) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
 			}
-		case <-time.After(30 * time.Second):
-			// Send a keepalive ping every 30 seconds
+		case <-keepalive.C:
+			// Send a keepalive ping
 			c.Writer.Write([]byte(": ping\n\n"))
 			c.Writer.Flush()
 		}
 	}
 }
 
-// Helper function to convert unstructured item to Cluster
-func convertToCluster(item interface{}) (models.Cluster, error) {
-	// Re-use the logic from GetClusters but without context - keep it simple for streaming
-	unstructuredMap, err := json.Marshal(item)
-	if err != nil {
-		return models.Cluster{}, err
-	}
+var clusterStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.ClusterInformerFactory == nil {
+			return nil
+		}
+		return ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		managedCluster, ok := obj.(*clusterv1.ManagedCluster)
+		if !ok {
+			return nil, false
+		}
+		return convertManagedClusterToCluster(*managedCluster), true
+	},
+	group:    clusterGroup,
+	resource: managedClustersResource,
+}
 
-	// Convert to our simplified format
-	var obj map[string]interface{}
-	if err := json.Unmarshal(unstructuredMap, &obj); err != nil {
-		return models.Cluster{}, err
-	}
+var clusterSetStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.ClusterInformerFactory == nil {
+			return nil
+		}
+		return ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSets().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		clusterSet, ok := obj.(*clusterv1beta2.ManagedClusterSet)
+		if !ok {
+			return nil, false
+		}
+		return convertClusterSetToModel(clusterSet), true
+	},
+	group:    clusterGroup,
+	resource: managedClusterSetsResource,
+}
 
-	// Extract metadata
-	metadata, ok := obj["metadata"].(map[string]interface{})
-	if !ok {
-		return models.Cluster{}, fmt.Errorf("metadata not found or not a map")
-	}
+var clusterSetBindingStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.ClusterInformerFactory == nil {
+			return nil
+		}
+		return ocmClient.ClusterInformerFactory.Cluster().V1beta2().ManagedClusterSetBindings().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		binding, ok := obj.(*clusterv1beta2.ManagedClusterSetBinding)
+		if !ok {
+			return nil, false
+		}
+		return convertClusterSetBindingToModel(binding), true
+	},
+	namespaced: true,
+	group:      clusterGroup,
+	resource:   managedClusterSetBindingsResource,
+}
 
-	cluster := models.Cluster{
-		ID:                fmt.Sprintf("%v", metadata["uid"]),
-		Name:              fmt.Sprintf("%v", metadata["name"]),
-		Status:            "Unknown",
-		CreationTimestamp: fmt.Sprintf("%v", metadata["creationTimestamp"]),
-	}
+var placementStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.ClusterInformerFactory == nil {
+			return nil
+		}
+		return ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		placement, ok := obj.(*clusterv1beta1.Placement)
+		if !ok {
+			return nil, false
+		}
+		return convertPlacementToModel(*placement), true
+	},
+	namespaced: true,
+	group:      clusterGroup,
+	resource:   placementsResource,
+}
 
-	// Extract labels
-	if labels, ok := metadata["labels"].(map[string]interface{}); ok {
-		cluster.Labels = make(map[string]string)
-		for k, v := range labels {
-			cluster.Labels[k] = fmt.Sprintf("%v", v)
+var placementDecisionStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.ClusterInformerFactory == nil {
+			return nil
 		}
-	}
+		return ocmClient.ClusterInformerFactory.Cluster().V1beta1().PlacementDecisions().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		pd, ok := obj.(*clusterv1beta1.PlacementDecision)
+		if !ok {
+			return nil, false
+		}
+		return convertPlacementDecisionToModel(pd), true
+	},
+	namespaced: true,
+	group:      clusterGroup,
+	resource:   placementDecisionsResource,
+}
 
-	// Extract version from status
-	if status, ok := obj["status"].(map[string]interface{}); ok {
-		if version, ok := status["version"].(map[string]interface{}); ok {
-			if k8sVersion, ok := version["kubernetes"].(string); ok {
-				cluster.Version = k8sVersion
-			}
+var managedClusterAddOnStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.AddonInformerFactory == nil {
+			return nil
+		}
+		return ocmClient.AddonInformerFactory.Addon().V1alpha1().ManagedClusterAddOns().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		addon, ok := obj.(*addonv1alpha1.ManagedClusterAddOn)
+		if !ok {
+			return nil, false
 		}
+		return convertManagedClusterAddOnToModel(addon), true
+	},
+	namespaced: true,
+	group:      addonGroup,
+	resource:   managedClusterAddOnsResource,
+}
 
-		// Extract conditions
-		if conditions, ok := status["conditions"].([]interface{}); ok {
-			for _, c := range conditions {
-				condMap, ok := c.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				condition := models.Condition{
-					Type:               fmt.Sprintf("%v", condMap["type"]),
-					Status:             fmt.Sprintf("%v", condMap["status"]),
-					Reason:             fmt.Sprintf("%v", condMap["reason"]),
-					Message:            fmt.Sprintf("%v", condMap["message"]),
-					LastTransitionTime: fmt.Sprintf("%v", condMap["lastTransitionTime"]),
-				}
-
-				cluster.Conditions = append(cluster.Conditions, condition)
-
-				// Update status based on the ManagedClusterConditionAvailable condition
-				if condition.Type == "ManagedClusterConditionAvailable" && condition.Status == "True" {
-					cluster.Status = "Online"
-				} else if condition.Type == "ManagedClusterConditionAvailable" && condition.Status != "True" {
-					cluster.Status = "Offline"
-				}
-			}
+var manifestWorkStream = resourceStream{
+	informer: func(ocmClient *client.OCMClient) cache.SharedIndexInformer {
+		if ocmClient.WorkInformerFactory == nil {
+			return nil
 		}
-	}
+		return ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Informer()
+	},
+	convert: func(obj interface{}) (interface{}, bool) {
+		manifestWork, ok := obj.(*workv1.ManifestWork)
+		if !ok {
+			return nil, false
+		}
+		return convertManifestWorkToModel(manifestWork), true
+	},
+	namespaced: true,
+	group:      workGroup,
+	resource:   manifestWorksResource,
+}
+
+// StreamClusters handles streaming ManagedCluster updates via SSE
+func StreamClusters(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, clusterStream, "")
+}
+
+// StreamClusterSets handles streaming ManagedClusterSet updates via SSE
+func StreamClusterSets(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, clusterSetStream, "")
+}
+
+// StreamClusterSetBindings handles streaming ManagedClusterSetBinding updates via SSE,
+// optionally restricted to the namespace in the route
+func StreamClusterSetBindings(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, clusterSetBindingStream, c.Param("namespace"))
+}
+
+// StreamPlacements handles streaming Placement updates via SSE,
+// optionally restricted to the namespace in the route
+func StreamPlacements(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, placementStream, c.Param("namespace"))
+}
+
+// StreamPlacementDecisions handles streaming PlacementDecision updates via SSE,
+// optionally restricted to the namespace in the route
+func StreamPlacementDecisions(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, placementDecisionStream, c.Param("namespace"))
+}
+
+// StreamManifestWorks handles streaming ManifestWork updates for a namespace via SSE
+func StreamManifestWorks(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, manifestWorkStream, c.Param("namespace"))
+}
 
-	return cluster, nil
+// StreamClusterAddons handles streaming ManagedClusterAddOn updates for a cluster via SSE
+func StreamClusterAddons(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	serveStream(c, ocmClient, ctx, managedClusterAddOnStream, c.Param("name"))
 }
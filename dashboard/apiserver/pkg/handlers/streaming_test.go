@@ -1,14 +1,29 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
-	"k8s.io/client-go/dynamic"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	fakecluster "open-cluster-management.io/api/client/cluster/clientset/versioned/fake"
+	clusterinformers "open-cluster-management.io/api/client/cluster/informers/externalversions"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
 	"open-cluster-management-io/lab/apiserver/pkg/models"
 )
 
@@ -17,13 +32,18 @@ func TestStreamClusters(t *testing.T) {
 
 	tests := []struct {
 		name           string
-		dynamicClient  dynamic.Interface
+		client         *client.OCMClient
 		expectedStatus int
 	}{
 		{
 			name:           "nil client",
-			dynamicClient:  nil,
-			expectedStatus: 500,
+			client:         nil,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "client with nil informer factory",
+			client:         &client.OCMClient{},
+			expectedStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -34,158 +54,355 @@ func TestStreamClusters(t *testing.T) {
 
 			ctx := context.Background()
 
-			StreamClusters(c, tt.dynamicClient, ctx)
+			StreamClusters(c, tt.client, ctx)
 
-			if tt.expectedStatus == 500 {
-				assert.Contains(t, w.Body.String(), "error")
-			}
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), "error")
 		})
 	}
 }
 
-func TestConvertToCluster(t *testing.T) {
+// sseFrame is a parsed server-sent event
+type sseFrame struct {
+	id        string
+	eventType string
+	data      string
+}
+
+// readFrame reads the next event frame, skipping keepalive comments
+func readFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+
+	frame := sseFrame{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case line == "":
+			if frame.eventType != "" {
+				return frame
+			}
+		case strings.HasPrefix(line, "id: "):
+			frame.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamClustersSendsIncrementalEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clusterClient := fakecluster.NewSimpleClientset(&clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-a", ResourceVersion: "1"},
+		Spec: clusterv1.ManagedClusterSpec{
+			Taints: []clusterv1.Taint{{Key: "maintenance", Effect: clusterv1.TaintEffectNoSelect}},
+		},
+	})
+	ocmClient := &client.OCMClient{
+		ClusterClient:          clusterClient,
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Informer()
+	ocmClient.ClusterInformerFactory.Start(ctx.Done())
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), informer.HasSynced))
+
+	router := gin.New()
+	router.GET("/api/stream/clusters", func(c *gin.Context) {
+		StreamClusters(c, ocmClient, ctx)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	connections := metrics.StreamConnections.WithLabelValues(managedClustersResource)
+	snapshots := metrics.StreamEvents.WithLabelValues(managedClustersResource, streamEventSnapshot)
+	connectionsBefore, snapshotsBefore := testutil.ToFloat64(connections), testutil.ToFloat64(snapshots)
+
+	resp, err := http.Get(server.URL + "/api/stream/clusters")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	// The initial snapshot uses the same conversion as GET /api/clusters
+	frame := readFrame(t, reader)
+	assert.Equal(t, streamEventSnapshot, frame.eventType)
+	var snapshot []models.Cluster
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &snapshot))
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "cluster-a", snapshot[0].Name)
+	assert.Len(t, snapshot[0].Taints, 1)
+
+	// The open connection and the events sent are counted for the stream
+	assert.Equal(t, connectionsBefore+1, testutil.ToFloat64(connections))
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(snapshots) == snapshotsBefore+1 }, time.Second, 10*time.Millisecond)
+
+	// Only the changed cluster is sent afterwards
+	_, err = clusterClient.ClusterV1().ManagedClusters().Create(ctx, &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-b", ResourceVersion: "2"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	frame = readFrame(t, reader)
+	assert.Equal(t, streamEventAdded, frame.eventType)
+	assert.Equal(t, "2", frame.id)
+	var added models.Cluster
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &added))
+	assert.Equal(t, "cluster-b", added.Name)
+
+	err = clusterClient.ClusterV1().ManagedClusters().Delete(ctx, "cluster-a", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	frame = readFrame(t, reader)
+	assert.Equal(t, streamEventDeleted, frame.eventType)
+	var deleted models.Cluster
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &deleted))
+	assert.Equal(t, "cluster-a", deleted.Name)
+}
+
+func TestEventBroadcasterResume(t *testing.T) {
+	b := newEventBroadcaster()
+	b.publish(streamEvent{ID: "10", Type: streamEventAdded})
+	b.publish(streamEvent{ID: "11", Type: streamEventModified})
+	b.publish(streamEvent{ID: "12", Type: streamEventDeleted})
+
 	tests := []struct {
-		name        string
-		item        interface{}
-		expectError bool
-		expected    models.Cluster
+		name            string
+		lastEventID     string
+		expectedResumed bool
+		expectedReplay  []string
 	}{
 		{
-			name: "valid cluster object",
-			item: map[string]interface{}{
-				"metadata": map[string]interface{}{
-					"uid":               "test-uid",
-					"name":              "test-cluster",
-					"creationTimestamp": "2023-01-01T00:00:00Z",
-					"labels": map[string]interface{}{
-						"env": "test",
-					},
-				},
-				"status": map[string]interface{}{
-					"version": map[string]interface{}{
-						"kubernetes": "v1.20.0",
-					},
-					"conditions": []interface{}{
-						map[string]interface{}{
-							"type":               "ManagedClusterConditionAvailable",
-							"status":             "True",
-							"reason":             "Available",
-							"message":            "Cluster is available",
-							"lastTransitionTime": "2023-01-01T00:00:00Z",
-						},
-					},
-				},
-			},
-			expectError: false,
-			expected: models.Cluster{
-				ID:                "test-uid",
-				Name:              "test-cluster",
-				Status:            "Online",
-				Version:           "v1.20.0",
-				CreationTimestamp: "2023-01-01T00:00:00Z",
-				Labels: map[string]string{
-					"env": "test",
-				},
-				Conditions: []models.Condition{
-					{
-						Type:               "ManagedClusterConditionAvailable",
-						Status:             "True",
-						Reason:             "Available",
-						Message:            "Cluster is available",
-						LastTransitionTime: "2023-01-01T00:00:00Z",
-					},
-				},
-			},
+			name:            "no last event id",
+			lastEventID:     "",
+			expectedResumed: false,
 		},
 		{
-			name: "cluster with offline status",
-			item: map[string]interface{}{
-				"metadata": map[string]interface{}{
-					"uid":               "test-uid-2",
-					"name":              "test-cluster-2",
-					"creationTimestamp": "2023-01-01T00:00:00Z",
-				},
-				"status": map[string]interface{}{
-					"conditions": []interface{}{
-						map[string]interface{}{
-							"type":               "ManagedClusterConditionAvailable",
-							"status":             "False",
-							"reason":             "Unavailable",
-							"message":            "Cluster is not available",
-							"lastTransitionTime": "2023-01-01T00:00:00Z",
-						},
-					},
-				},
-			},
-			expectError: false,
-			expected: models.Cluster{
-				ID:                "test-uid-2",
-				Name:              "test-cluster-2",
-				Status:            "Offline",
-				CreationTimestamp: "2023-01-01T00:00:00Z",
-				Conditions: []models.Condition{
-					{
-						Type:               "ManagedClusterConditionAvailable",
-						Status:             "False",
-						Reason:             "Unavailable",
-						Message:            "Cluster is not available",
-						LastTransitionTime: "2023-01-01T00:00:00Z",
-					},
-				},
-			},
+			name:            "resume from history",
+			lastEventID:     "10",
+			expectedResumed: true,
+			expectedReplay:  []string{"11", "12"},
 		},
 		{
-			name: "invalid object without metadata",
-			item: map[string]interface{}{
-				"spec": map[string]interface{}{},
-			},
-			expectError: true,
+			name:            "already up to date",
+			lastEventID:     "12",
+			expectedResumed: true,
 		},
 		{
-			name:        "invalid object type",
-			item:        "invalid",
-			expectError: true,
+			name:            "unknown event id",
+			lastEventID:     "5",
+			expectedResumed: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cluster, err := convertToCluster(tt.item)
+			events, replay, resumed, latestID := b.subscribe(tt.lastEventID)
+			defer b.unsubscribe(events)
 
-			if tt.expectError {
-				assert.Error(t, err)
+			assert.Equal(t, tt.expectedResumed, resumed)
+			assert.Equal(t, "12", latestID)
+
+			ids := []string{}
+			for _, event := range replay {
+				ids = append(ids, event.ID)
+			}
+			if tt.expectedReplay == nil {
+				assert.Empty(t, ids)
 			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expected.ID, cluster.ID)
-				assert.Equal(t, tt.expected.Name, cluster.Name)
-				assert.Equal(t, tt.expected.Status, cluster.Status)
-				assert.Equal(t, tt.expected.Version, cluster.Version)
-				assert.Equal(t, tt.expected.CreationTimestamp, cluster.CreationTimestamp)
-				assert.Equal(t, tt.expected.Labels, cluster.Labels)
-
-				if len(tt.expected.Conditions) > 0 {
-					assert.Len(t, cluster.Conditions, len(tt.expected.Conditions))
-					assert.Equal(t, tt.expected.Conditions[0].Type, cluster.Conditions[0].Type)
-					assert.Equal(t, tt.expected.Conditions[0].Status, cluster.Conditions[0].Status)
-				}
+				assert.Equal(t, tt.expectedReplay, ids)
 			}
 		})
 	}
 }
 
-func TestConvertToClusterWithMarshalError(t *testing.T) {
-	invalidItem := make(chan int)
+func TestEventBroadcasterResumeFromEmptyHistory(t *testing.T) {
+	b := newEventBroadcaster()
 
-	_, err := convertToCluster(invalidItem)
-	assert.Error(t, err)
+	// A snapshot taken before any event still gets an ID to resume from
+	events, _, _, snapshotID := b.subscribe("")
+	b.unsubscribe(events)
+	require.NotEmpty(t, snapshotID)
+
+	b.publish(streamEvent{ID: "1", Type: streamEventAdded})
+	b.publish(streamEvent{ID: "2", Type: streamEventModified})
+
+	events, replay, resumed, latestID := b.subscribe(snapshotID)
+	b.unsubscribe(events)
+	assert.True(t, resumed)
+	require.Len(t, replay, 2)
+	assert.Equal(t, "1", replay[0].ID)
+	assert.Equal(t, "2", latestID)
+
+	// Once the history no longer reaches back to the snapshot a new one is needed
+	for i := 0; i < streamHistorySize; i++ {
+		b.publish(streamEvent{ID: fmt.Sprintf("%d", i+3), Type: streamEventModified})
+	}
+	events, _, resumed, _ = b.subscribe(snapshotID)
+	b.unsubscribe(events)
+	assert.False(t, resumed)
+
+	// The last dropped event marks the start of the remaining history
+	events, replay, resumed, _ = b.subscribe("2")
+	b.unsubscribe(events)
+	assert.True(t, resumed)
+	assert.Len(t, replay, streamHistorySize)
 }
 
-func TestConvertToClusterWithUnmarshalError(t *testing.T) {
-	item := map[string]interface{}{
-		"metadata": "invalid-metadata-type",
+func TestEventBroadcasterDropsSlowSubscribers(t *testing.T) {
+	b := newEventBroadcaster()
+	events, _, _, _ := b.subscribe("")
+
+	for i := 0; i <= streamSubscriberBuffer; i++ {
+		b.publish(streamEvent{ID: "1", Type: streamEventModified})
+	}
+
+	drained := 0
+	for range events {
+		drained++
 	}
+	assert.Equal(t, streamSubscriberBuffer, drained)
+
+	// Unsubscribing a dropped subscriber is a no-op
+	assert.NotPanics(t, func() { b.unsubscribe(events) })
+}
 
-	cluster, err := convertToCluster(item)
-	assert.Error(t, err)
-	assert.Equal(t, models.Cluster{}, cluster)
+func TestStreamClustersResumeSkipsSnapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clusterClient := fakecluster.NewSimpleClientset()
+	ocmClient := &client.OCMClient{
+		ClusterClient:          clusterClient,
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+	}
+
+	informer := ocmClient.ClusterInformerFactory.Cluster().V1().ManagedClusters().Informer()
+	b, err := broadcasterFor(informer, clusterStream.convert)
+	require.NoError(t, err)
+	b.publish(streamEvent{ID: "7", Type: streamEventAdded, Data: models.Cluster{Name: "cluster-a"}})
+	b.publish(streamEvent{ID: "8", Type: streamEventModified, Data: models.Cluster{Name: "cluster-a"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/api/stream/clusters", nil)
+	c.Request.Header.Set("Last-Event-ID", "7")
+
+	StreamClusters(c, ocmClient, ctx)
+
+	body := w.Body.String()
+	assert.NotContains(t, body, "event: snapshot")
+	assert.Contains(t, body, "id: 8\nevent: modified\n")
+}
+
+func TestStreamPlacementsFiltersByNamespaceAndLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clusterClient := fakecluster.NewSimpleClientset(
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-a", Namespace: "ns1", Labels: map[string]string{"app": "web"}}},
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-b", Namespace: "ns1", Labels: map[string]string{"app": "db"}}},
+		&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "placement-c", Namespace: "ns2", Labels: map[string]string{"app": "web"}}},
+	)
+	ocmClient := &client.OCMClient{
+		ClusterClient:          clusterClient,
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := ocmClient.ClusterInformerFactory.Cluster().V1beta1().Placements().Informer()
+	ocmClient.ClusterInformerFactory.Start(ctx.Done())
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), informer.HasSynced))
+
+	router := gin.New()
+	router.GET("/api/stream/namespaces/:namespace/placements", func(c *gin.Context) {
+		StreamPlacements(c, ocmClient, ctx)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/stream/namespaces/ns1/placements?labelSelector=app%3Dweb")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+
+	frame := readFrame(t, reader)
+	assert.Equal(t, streamEventSnapshot, frame.eventType)
+	var snapshot []models.Placement
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &snapshot))
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "placement-a", snapshot[0].Name)
+
+	// Neither of these match the subscriber's filter
+	_, err = clusterClient.ClusterV1beta1().Placements("ns2").Create(ctx, &clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "placement-d", Namespace: "ns2", Labels: map[string]string{"app": "web"}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = clusterClient.ClusterV1beta1().Placements("ns1").Create(ctx, &clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "placement-e", Namespace: "ns1", Labels: map[string]string{"app": "db"}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = clusterClient.ClusterV1beta1().Placements("ns1").Create(ctx, &clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: "placement-f", Namespace: "ns1", Labels: map[string]string{"app": "web"}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	frame = readFrame(t, reader)
+	assert.Equal(t, streamEventAdded, frame.eventType)
+	var added models.Placement
+	require.NoError(t, json.Unmarshal([]byte(frame.data), &added))
+	assert.Equal(t, "placement-f", added.Name)
+}
+
+func TestStreamInvalidLabelSelector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newFakeOCMClient(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/stream/placements?labelSelector=app%20in%20(", nil)
+
+	StreamPlacements(c, ocmClient, context.Background())
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestResourceStreamsRequireInformerFactory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlers := map[string]func(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context){
+		"clustersets":        StreamClusterSets,
+		"clustersetbindings": StreamClusterSetBindings,
+		"placements":         StreamPlacements,
+		"placementdecisions": StreamPlacementDecisions,
+		"manifestworks":      StreamManifestWorks,
+		"addons":             StreamClusterAddons,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/stream/"+name, nil)
+
+			handler(c, &client.OCMClient{}, context.Background())
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+	}
 }
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+// workloadKey identifies a workload across ManifestWorks, the version is left
+// out so a workload shipped with different API versions is grouped once
+type workloadKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+// workloadFilter holds the query parameters of SearchManifestWorks
+type workloadFilter struct {
+	kind            string
+	name            string
+	namespace       string
+	conditionType   string
+	conditionStatus metav1.ConditionStatus
+}
+
+// SearchManifestWorks searches the ManifestWorks of every cluster namespace
+// and groups the manifests they ship by workload. The kind, name and namespace
+// query parameters match the embedded manifests, labelSelector matches the
+// ManifestWork labels, and condition with an optional status (default True)
+// matches the manifest's condition, e.g. condition=Degraded.
+func SearchManifestWorks(c *gin.Context, ocmClient *client.OCMClient, ctx context.Context) {
+	// Ensure we have a client before proceeding
+	if ocmClient == nil || ocmClient.WorkInformerFactory == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	filter, err := parseWorkloadFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := parseListQuery(c, workloadListFields, workloadListSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ocmClient.WorkInformerFactory.Work().V1().ManifestWorks().Lister().List(query.selector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Drop anything the caller is not allowed to read
+	access := newAccessChecker(c, ocmClient, ctx, workGroup, manifestWorksResource)
+
+	workloads := map[workloadKey]*models.Workload{}
+	for _, work := range list {
+		if !access.allowed(work.Namespace, work.Name) {
+			continue
+		}
+		statuses := map[int32][]metav1.Condition{}
+		for _, manifestStatus := range work.Status.ResourceStatus.Manifests {
+			statuses[manifestStatus.ResourceMeta.Ordinal] = manifestStatus.Conditions
+		}
+
+		for i, manifest := range work.Spec.Workload.Manifests {
+			key, ok := manifestWorkloadKey(manifest)
+			if !ok || !filter.matches(key, statuses[int32(i)]) {
+				continue
+			}
+			workload, found := workloads[key]
+			if !found {
+				workload = &models.Workload{Group: key.group, Kind: key.kind, Namespace: key.namespace, Name: key.name}
+				workloads[key] = workload
+			}
+			conditions := statuses[int32(i)]
+			workload.ManifestWorks = append(workload.ManifestWorks, models.WorkloadManifestWork{
+				ClusterName:  work.Namespace,
+				ManifestWork: work.Name,
+				Applied:      meta.IsStatusConditionTrue(conditions, workv1.ManifestApplied),
+				Available:    meta.IsStatusConditionTrue(conditions, workv1.ManifestAvailable),
+				Degraded:     meta.IsStatusConditionTrue(conditions, workv1.ManifestDegraded),
+			})
+		}
+	}
+
+	result := make([]models.Workload, 0, len(workloads))
+	for _, workload := range workloads {
+		summarizeWorkload(workload)
+		result = append(result, *workload)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	query.respond(c, result)
+}
+
+// parseWorkloadFilter reads the search query parameters
+func parseWorkloadFilter(c *gin.Context) (workloadFilter, error) {
+	filter := workloadFilter{
+		kind:            c.Query("kind"),
+		name:            c.Query("name"),
+		namespace:       c.Query("namespace"),
+		conditionType:   c.Query("condition"),
+		conditionStatus: metav1.ConditionTrue,
+	}
+
+	if raw := c.Query("status"); raw != "" {
+		switch status := metav1.ConditionStatus(raw); status {
+		case metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown:
+			filter.conditionStatus = status
+		default:
+			return filter, fmt.Errorf("invalid status %q, must be True, False or Unknown", raw)
+		}
+		if filter.conditionType == "" {
+			return filter, fmt.Errorf("status requires a condition")
+		}
+	}
+
+	return filter, nil
+}
+
+// matches reports whether a manifest passes the filter. A manifest without
+// the condition counts as Unknown.
+func (f workloadFilter) matches(key workloadKey, conditions []metav1.Condition) bool {
+	if f.kind != "" && !strings.EqualFold(f.kind, key.kind) {
+		return false
+	}
+	if f.name != "" && f.name != key.name {
+		return false
+	}
+	if f.namespace != "" && f.namespace != key.namespace {
+		return false
+	}
+	if f.conditionType != "" {
+		status := metav1.ConditionUnknown
+		if condition := meta.FindStatusCondition(conditions, f.conditionType); condition != nil {
+			status = condition.Status
+		}
+		if status != f.conditionStatus {
+			return false
+		}
+	}
+	return true
+}
+
+// manifestWorkloadKey reads the identity of an embedded manifest, false when
+// the manifest is not a well-formed object
+func manifestWorkloadKey(manifest workv1.Manifest) (workloadKey, bool) {
+	var obj struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(manifest.Raw, &obj); err != nil || obj.Kind == "" || obj.Metadata.Name == "" {
+		return workloadKey{}, false
+	}
+	gv, err := schema.ParseGroupVersion(obj.APIVersion)
+	if err != nil {
+		return workloadKey{}, false
+	}
+	return workloadKey{group: gv.Group, kind: obj.Kind, namespace: obj.Metadata.Namespace, name: obj.Metadata.Name}, true
+}
+
+// summarizeWorkload orders the works of a workload and counts its clusters
+func summarizeWorkload(workload *models.Workload) {
+	sort.Slice(workload.ManifestWorks, func(i, j int) bool {
+		a, b := workload.ManifestWorks[i], workload.ManifestWorks[j]
+		if a.ClusterName != b.ClusterName {
+			return a.ClusterName < b.ClusterName
+		}
+		return a.ManifestWork < b.ManifestWork
+	})
+
+	clusters := map[string]bool{}
+	applied := map[string]bool{}
+	available := map[string]bool{}
+	degraded := map[string]bool{}
+	for _, work := range workload.ManifestWorks {
+		clusters[work.ClusterName] = true
+		if work.Applied {
+			applied[work.ClusterName] = true
+		}
+		if work.Available {
+			available[work.ClusterName] = true
+		}
+		if work.Degraded {
+			degraded[work.ClusterName] = true
+		}
+	}
+	workload.Clusters = len(clusters)
+	workload.Applied = len(applied)
+	workload.Available = len(available)
+	workload.Degraded = len(degraded)
+}
+
+// workloadListFields are the fields the workload search can be filtered on
+var workloadListFields = func() listFields[models.Workload] {
+	fieldFuncs := namespacedFields(
+		func(workload models.Workload) string { return workload.Namespace },
+		func(workload models.Workload) string { return workload.Name },
+	)
+	fieldFuncs["kind"] = func(workload models.Workload) []string { return []string{workload.Kind} }
+	fieldFuncs["group"] = func(workload models.Workload) []string { return []string{workload.Group} }
+	return fieldFuncs
+}()
+
+// workloadListSorts are the keys the workload search can be sorted by
+var workloadListSorts = listSorts[models.Workload]{
+	"kind":      func(workload models.Workload) string { return workload.Kind },
+	"namespace": func(workload models.Workload) string { return workload.Namespace },
+	"name":      func(workload models.Workload) string { return workload.Name },
+}
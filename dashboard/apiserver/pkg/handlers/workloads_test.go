@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
+	"open-cluster-management-io/lab/apiserver/pkg/client"
+	"open-cluster-management-io/lab/apiserver/pkg/models"
+)
+
+const (
+	webDeployment = `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "namespace": "shop"}}`
+	webService    = `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web", "namespace": "shop"}}`
+	crd           = `{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "carts.shop.io"}}`
+)
+
+// newWorkloadWork returns a work shipping the manifests, where the condition
+// types in states[i] are true for manifest i
+func newWorkloadWork(cluster, name string, workLabels map[string]string, manifests []string, states ...[]string) *workv1.ManifestWork {
+	work := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster, Labels: workLabels}}
+	for i, manifest := range manifests {
+		work.Spec.Workload.Manifests = append(work.Spec.Workload.Manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: []byte(manifest)}})
+		if i >= len(states) {
+			continue
+		}
+		status := workv1.ManifestCondition{ResourceMeta: workv1.ManifestResourceMeta{Ordinal: int32(i)}}
+		for _, conditionType := range states[i] {
+			status.Conditions = append(status.Conditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue})
+		}
+		work.Status.ResourceStatus.Manifests = append(work.Status.ResourceStatus.Manifests, status)
+	}
+	return work
+}
+
+func newWorkloadClient(t *testing.T) *client.OCMClient {
+	applied := []string{workv1.ManifestApplied, workv1.ManifestAvailable}
+	degraded := []string{workv1.ManifestApplied, workv1.ManifestDegraded}
+	return newFakeOCMClient(t,
+		newWorkloadWork("cluster1", "shop", map[string]string{"app": "shop"}, []string{webDeployment, webService, crd}, applied, applied, applied),
+		newWorkloadWork("cluster2", "shop", map[string]string{"app": "shop"}, []string{webDeployment, webService}, degraded, applied),
+		newWorkloadWork("cluster3", "shop", map[string]string{"app": "shop"}, []string{webDeployment, webService, crd}),
+		newWorkloadWork("cluster3", "crds", map[string]string{"app": "platform"}, []string{crd, `not json`}, applied),
+	)
+}
+
+func runSearchManifestWorks(ocmClient *client.OCMClient, query string, user *authv1.UserInfo) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/manifestworks"+query, nil)
+	if user != nil {
+		auth.SetUser(c, user)
+	}
+	SearchManifestWorks(c, ocmClient, c.Request.Context())
+	return w
+}
+
+func TestSearchManifestWorks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newWorkloadClient(t)
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedStatus    int
+		expectedWorkloads []string
+	}{
+		{
+			name:              "all workloads",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"Service shop/web", "CustomResourceDefinition carts.shop.io", "Deployment shop/web"},
+		},
+		{
+			name:              "kind is case insensitive",
+			query:             "?kind=deployment",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"Deployment shop/web"},
+		},
+		{
+			name:              "name and namespace",
+			query:             "?name=web&namespace=shop",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"Service shop/web", "Deployment shop/web"},
+		},
+		{
+			name:              "label selector",
+			query:             "?labelSelector=app%3Dplatform",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"CustomResourceDefinition carts.shop.io"},
+		},
+		{
+			name:              "degraded manifests",
+			query:             "?condition=Degraded",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"Deployment shop/web"},
+		},
+		{
+			name:              "manifests without status are unknown",
+			query:             "?condition=Applied&status=Unknown&kind=Service",
+			expectedStatus:    http.StatusOK,
+			expectedWorkloads: []string{"Service shop/web"},
+		},
+		{
+			name:           "invalid label selector",
+			query:          "?labelSelector=app%3D%3D%3D",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid status",
+			query:          "?condition=Applied&status=Maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "status without condition",
+			query:          "?status=False",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := runSearchManifestWorks(ocmClient, tt.query, nil)
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var workloads []models.Workload
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workloads))
+			names := []string{}
+			for _, workload := range workloads {
+				name := workload.Name
+				if workload.Namespace != "" {
+					name = workload.Namespace + "/" + name
+				}
+				names = append(names, workload.Kind+" "+name)
+			}
+			assert.Equal(t, tt.expectedWorkloads, names)
+		})
+	}
+}
+
+func TestSearchManifestWorksGroupsByWorkload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runSearchManifestWorks(newWorkloadClient(t), "?kind=Deployment", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var workloads []models.Workload
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workloads))
+	require.Len(t, workloads, 1)
+	assert.Equal(t, models.Workload{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "shop",
+		Name:      "web",
+		Clusters:  3,
+		Applied:   2,
+		Available: 1,
+		Degraded:  1,
+		ManifestWorks: []models.WorkloadManifestWork{
+			{ClusterName: "cluster1", ManifestWork: "shop", Applied: true, Available: true},
+			{ClusterName: "cluster2", ManifestWork: "shop", Applied: true, Degraded: true},
+			{ClusterName: "cluster3", ManifestWork: "shop"},
+		},
+	}, workloads[0])
+
+	// The same object shipped by two works on cluster3 counts once
+	w = runSearchManifestWorks(newWorkloadClient(t), "?kind=CustomResourceDefinition", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workloads))
+	require.Len(t, workloads, 1)
+	assert.Equal(t, 2, workloads[0].Clusters)
+	assert.Equal(t, 2, workloads[0].Applied)
+	require.Len(t, workloads[0].ManifestWorks, 3)
+	assert.Equal(t, "crds", workloads[0].ManifestWorks[1].ManifestWork)
+	assert.Equal(t, "shop", workloads[0].ManifestWorks[2].ManifestWork)
+}
+
+func TestSearchManifestWorksFiltersByCallerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ocmClient := newWorkloadClient(t)
+	ocmClient.Authorizer = tenantAuthorizer()
+
+	// The tenant may only list works in cluster1
+	w := runSearchManifestWorks(ocmClient, "?kind=Deployment", &authv1.UserInfo{Username: "tenant"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var workloads []models.Workload
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workloads))
+	require.Len(t, workloads, 1)
+	assert.Equal(t, 1, workloads[0].Clusters)
+	assert.Equal(t, "cluster1", workloads[0].ManifestWorks[0].ClusterName)
+}
+
+func TestSearchManifestWorksRequiresClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := runSearchManifestWorks(nil, "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,339 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultMaxEvents bounds the events kept per placement
+	DefaultMaxEvents = 1000
+
+	// DefaultRetention is how long events are kept
+	DefaultRetention = 30 * 24 * time.Hour
+
+	// EventAdded is recorded when a cluster joins a placement's decisions
+	EventAdded = "Added"
+
+	// EventRemoved is recorded when a cluster leaves a placement's decisions
+	EventRemoved = "Removed"
+)
+
+var (
+	placementsBucket = []byte("placements")
+	eventsBucket     = []byte("events")
+	clustersKey      = []byte("clusters")
+)
+
+// Options configures the history store
+type Options struct {
+	// Path is the bbolt database file
+	Path string
+	// MaxEvents bounds the events kept per placement, the oldest are dropped first
+	MaxEvents int
+	// Retention drops events older than this; zero keeps them until MaxEvents is reached
+	Retention time.Duration
+}
+
+// OptionsFromEnv reads the store options from DASHBOARD_HISTORY_PATH,
+// DASHBOARD_HISTORY_MAX_EVENTS and DASHBOARD_HISTORY_RETENTION, falling back
+// to the defaults for unset or invalid values. The path has no default, an
+// empty Path means history is not recorded.
+func OptionsFromEnv() Options {
+	opts := Options{
+		Path:      os.Getenv("DASHBOARD_HISTORY_PATH"),
+		MaxEvents: DefaultMaxEvents,
+		Retention: DefaultRetention,
+	}
+
+	if value := os.Getenv("DASHBOARD_HISTORY_MAX_EVENTS"); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			opts.MaxEvents = size
+		} else {
+			log.Printf("Ignoring invalid DASHBOARD_HISTORY_MAX_EVENTS %q", value)
+		}
+	}
+	if value := os.Getenv("DASHBOARD_HISTORY_RETENTION"); value != "" {
+		if retention, err := time.ParseDuration(value); err == nil && retention >= 0 {
+			opts.Retention = retention
+		} else {
+			log.Printf("Ignoring invalid DASHBOARD_HISTORY_RETENTION %q", value)
+		}
+	}
+
+	return opts
+}
+
+// Event is a cluster being added to or removed from a placement's decisions
+type Event struct {
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"`
+	ClusterName string    `json:"clusterName"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// Store keeps the decision history of each placement in a bbolt database.
+// Next to the events it keeps the clusters last recorded for the placement,
+// so changes made while the dashboard was down are still recorded.
+type Store struct {
+	db   *bolt.DB
+	opts Options
+	now  func() time.Time
+}
+
+// Open opens or creates the history database
+func Open(opts Options) (*Store, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("no history database path is set")
+	}
+	if opts.MaxEvents <= 0 {
+		opts.MaxEvents = DefaultMaxEvents
+	}
+
+	db, err := bolt.Open(opts.Path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", opts.Path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(placementsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db, opts: opts, now: time.Now}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// placementKey identifies a placement in the database
+func placementKey(namespace, name string) []byte {
+	return []byte(namespace + "/" + name)
+}
+
+// Placements lists the namespace and name of every placement with history
+func (s *Store) Placements() ([][2]string, error) {
+	var placements [][2]string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(placementsBucket).ForEachBucket(func(k []byte) error {
+			if namespace, name, ok := splitPlacementKey(k); ok {
+				placements = append(placements, [2]string{namespace, name})
+			}
+			return nil
+		})
+	})
+	return placements, err
+}
+
+// splitPlacementKey returns the namespace and name of a placement key
+func splitPlacementKey(k []byte) (string, string, bool) {
+	for i := range k {
+		if k[i] == '/' {
+			return string(k[:i]), string(k[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// Clusters returns the clusters last recorded for a placement. The second
+// return value is false when nothing was recorded yet.
+func (s *Store) Clusters(namespace, name string) (map[string]bool, bool, error) {
+	clusters := map[string]bool{}
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		placement := tx.Bucket(placementsBucket).Bucket(placementKey(namespace, name))
+		if placement == nil {
+			return nil
+		}
+		value := placement.Get(clustersKey)
+		if value == nil {
+			return nil
+		}
+		found = true
+
+		var names []string
+		if err := json.Unmarshal(value, &names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			clusters[name] = true
+		}
+		return nil
+	})
+	return clusters, found, err
+}
+
+// Record stores the placement's current clusters and appends the events that
+// led to them, then drops events beyond the retention and size limits
+func (s *Store) Record(namespace, name string, clusters []string, events []Event) error {
+	sorted := append([]string{}, clusters...)
+	sort.Strings(sorted)
+	value, err := json.Marshal(sorted)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		placement, err := tx.Bucket(placementsBucket).CreateBucketIfNotExists(placementKey(namespace, name))
+		if err != nil {
+			return err
+		}
+		if err := placement.Put(clustersKey, value); err != nil {
+			return err
+		}
+
+		bucket, err := placement.CreateBucketIfNotExists(eventsBucket)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(sequenceKey(seq), data); err != nil {
+				return err
+			}
+		}
+
+		return s.trim(bucket)
+	})
+}
+
+// trim drops the oldest events until the bucket is within the limits
+func (s *Store) trim(bucket *bolt.Bucket) error {
+	count := 0
+	counter := bucket.Cursor()
+	for k, _ := counter.First(); k != nil; k, _ = counter.Next() {
+		count++
+	}
+	cutoff := time.Time{}
+	if s.opts.Retention > 0 {
+		cutoff = s.now().Add(-s.opts.Retention)
+	}
+
+	// Collect first, deleting while iterating makes the cursor skip keys
+	var expired [][]byte
+	cursor := bucket.Cursor()
+	for k, v := cursor.First(); k != nil && count > 0; k, v = cursor.Next() {
+		if count <= s.opts.MaxEvents {
+			var event Event
+			if err := json.Unmarshal(v, &event); err != nil || !event.Time.Before(cutoff) {
+				break
+			}
+		}
+		expired = append(expired, append([]byte{}, k...))
+		count--
+	}
+	for _, k := range expired {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune drops the expired events of every placement and deletes the history
+// of placements keep returns false for, once their events have expired and no
+// clusters are recorded for them. With a zero retention events only expire
+// when MaxEvents is exceeded, so the history of deleted placements is kept.
+func (s *Store) Prune(keep func(namespace, name string) bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		placements := tx.Bucket(placementsBucket)
+
+		// Collect first, deleting while iterating makes the cursor skip keys
+		var deleted [][]byte
+		err := placements.ForEachBucket(func(k []byte) error {
+			placement := placements.Bucket(k)
+			events := placement.Bucket(eventsBucket)
+			if events != nil {
+				if err := s.trim(events); err != nil {
+					return err
+				}
+				if first, _ := events.Cursor().First(); first != nil {
+					return nil
+				}
+			}
+
+			var clusters []string
+			if value := placement.Get(clustersKey); value != nil {
+				if err := json.Unmarshal(value, &clusters); err != nil {
+					return err
+				}
+			}
+			if len(clusters) > 0 {
+				return nil
+			}
+			if namespace, name, ok := splitPlacementKey(k); ok && keep(namespace, name) {
+				return nil
+			}
+			deleted = append(deleted, append([]byte{}, k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range deleted {
+			if err := placements.DeleteBucket(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Events returns the events of a placement between since and until, oldest
+// first. A zero since or until leaves that end of the range open.
+func (s *Store) Events(namespace, name string, since, until time.Time) ([]Event, error) {
+	events := []Event{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		placement := tx.Bucket(placementsBucket).Bucket(placementKey(namespace, name))
+		if placement == nil {
+			return nil
+		}
+		bucket := placement.Bucket(eventsBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, v []byte) error {
+			var event Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return err
+			}
+			if !since.IsZero() && event.Time.Before(since) {
+				return nil
+			}
+			if !until.IsZero() && event.Time.After(until) {
+				return nil
+			}
+			events = append(events, event)
+			return nil
+		})
+	})
+	return events, err
+}
+
+// sequenceKey encodes a sequence number so keys sort in insertion order
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
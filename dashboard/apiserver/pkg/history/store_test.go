@@ -0,0 +1,203 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+	opts.Path = filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStoreRecordAndEvents(t *testing.T) {
+	store := openTestStore(t, Options{})
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	_, found, err := store.Clusters("app", "web")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Record("app", "web", []string{"cluster2", "cluster1"}, []Event{
+		{Time: start, Type: EventAdded, ClusterName: "cluster1"},
+		{Time: start, Type: EventAdded, ClusterName: "cluster2"},
+	}))
+	require.NoError(t, store.Record("app", "web", []string{"cluster1"}, []Event{
+		{Time: start.Add(time.Hour), Type: EventRemoved, ClusterName: "cluster2", Reason: "taint is not tolerated"},
+	}))
+	require.NoError(t, store.Record("app", "api", []string{}, nil))
+
+	clusters, found, err := store.Clusters("app", "web")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]bool{"cluster1": true}, clusters)
+
+	placements, err := store.Placements()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][2]string{{"app", "web"}, {"app", "api"}}, placements)
+
+	tests := []struct {
+		name          string
+		since         time.Time
+		until         time.Time
+		expectedTypes []string
+	}{
+		{
+			name:          "all events oldest first",
+			expectedTypes: []string{EventAdded, EventAdded, EventRemoved},
+		},
+		{
+			name:          "since",
+			since:         start.Add(time.Minute),
+			expectedTypes: []string{EventRemoved},
+		},
+		{
+			name:          "until",
+			until:         start.Add(time.Minute),
+			expectedTypes: []string{EventAdded, EventAdded},
+		},
+		{
+			name:          "empty range",
+			since:         start.Add(time.Minute),
+			until:         start.Add(2 * time.Minute),
+			expectedTypes: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := store.Events("app", "web", tt.since, tt.until)
+			require.NoError(t, err)
+			types := []string{}
+			for _, event := range events {
+				types = append(types, event.Type)
+			}
+			assert.Equal(t, tt.expectedTypes, types)
+		})
+	}
+
+	events, err := store.Events("app", "missing", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestStoreTrimsEvents(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		opts             Options
+		expectedClusters []string
+	}{
+		{
+			name:             "max events",
+			opts:             Options{MaxEvents: 2},
+			expectedClusters: []string{"cluster3", "cluster4"},
+		},
+		{
+			name:             "retention",
+			opts:             Options{MaxEvents: 10, Retention: 90 * time.Minute},
+			expectedClusters: []string{"cluster3", "cluster4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := openTestStore(t, tt.opts)
+			store.now = func() time.Time { return now }
+
+			// One event per hour, the last one now
+			for i, cluster := range []string{"cluster1", "cluster2", "cluster3", "cluster4"} {
+				event := Event{Time: now.Add(time.Duration(i-3) * time.Hour), Type: EventAdded, ClusterName: cluster}
+				require.NoError(t, store.Record("app", "web", []string{cluster}, []Event{event}))
+			}
+
+			events, err := store.Events("app", "web", time.Time{}, time.Time{})
+			require.NoError(t, err)
+			clusters := []string{}
+			for _, event := range events {
+				clusters = append(clusters, event.ClusterName)
+			}
+			assert.Equal(t, tt.expectedClusters, clusters)
+		})
+	}
+}
+
+func TestStorePersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := Open(Options{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, store.Record("app", "web", []string{"cluster1"}, []Event{{Time: time.Now(), Type: EventAdded, ClusterName: "cluster1"}}))
+	require.NoError(t, store.Close())
+
+	store, err = Open(Options{Path: path})
+	require.NoError(t, err)
+	defer store.Close()
+
+	clusters, found, err := store.Clusters("app", "web")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]bool{"cluster1": true}, clusters)
+
+	events, err := store.Events("app", "web", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("DASHBOARD_HISTORY_PATH", "/data/history.db")
+	t.Setenv("DASHBOARD_HISTORY_MAX_EVENTS", "50")
+	t.Setenv("DASHBOARD_HISTORY_RETENTION", "not-a-duration")
+
+	opts := OptionsFromEnv()
+	assert.Equal(t, "/data/history.db", opts.Path)
+	assert.Equal(t, 50, opts.MaxEvents)
+	assert.Equal(t, DefaultRetention, opts.Retention)
+}
+
+func TestOptionsFromEnvWithoutPath(t *testing.T) {
+	t.Setenv("DASHBOARD_HISTORY_PATH", "")
+
+	// History is only recorded where it is asked for, never in a temp file
+	opts := OptionsFromEnv()
+	assert.Empty(t, opts.Path)
+	_, err := Open(opts)
+	assert.Error(t, err)
+}
+
+func TestStorePrune(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := openTestStore(t, Options{Retention: time.Hour})
+
+	// Recorded an hour and a half ago, so every event has expired by now
+	store.now = func() time.Time { return now.Add(-90 * time.Minute) }
+	removed := []Event{{Time: store.now(), Type: EventRemoved, ClusterName: "cluster1"}}
+	require.NoError(t, store.Record("app", "deleted", nil, removed))
+	require.NoError(t, store.Record("app", "live", nil, removed))
+	require.NoError(t, store.Record("app", "orphaned", []string{"cluster2"}, removed))
+	store.now = func() time.Time { return now.Add(-30 * time.Minute) }
+	require.NoError(t, store.Record("app", "recent", nil, []Event{{Time: store.now(), Type: EventRemoved, ClusterName: "cluster1"}}))
+
+	store.now = func() time.Time { return now }
+	require.NoError(t, store.Prune(func(namespace, name string) bool {
+		return name == "live"
+	}))
+
+	// Deleted placements are kept while they have events or clusters
+	placements, err := store.Placements()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][2]string{{"app", "live"}, {"app", "orphaned"}, {"app", "recent"}}, placements)
+
+	events, err := store.Events("app", "live", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
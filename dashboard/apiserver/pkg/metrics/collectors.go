@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	addonlisters "open-cluster-management.io/api/client/addon/listers/addon/v1alpha1"
+	clusterlistersv1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1"
+	clusterlistersv1beta1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1beta1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+var (
+	informerSyncedDesc = prometheus.NewDesc(namespace+"_informer_synced",
+		"Whether the informer cache of a resource has completed its initial sync (1) or not (0).",
+		[]string{"resource"}, nil)
+
+	fleetClustersDesc = prometheus.NewDesc(namespace+"_fleet_clusters",
+		"Number of ManagedClusters by the status of their ManagedClusterConditionAvailable condition.",
+		[]string{"available"}, nil)
+	fleetUnsatisfiedPlacementsDesc = prometheus.NewDesc(namespace+"_fleet_unsatisfied_placements",
+		"Number of Placements whose PlacementSatisfied condition is not True.",
+		nil, nil)
+	fleetDegradedAddonsDesc = prometheus.NewDesc(namespace+"_fleet_degraded_addons",
+		"Number of ManagedClusterAddOns whose Degraded condition is True, by addon name. Every installed addon is reported.",
+		[]string{"addon"}, nil)
+	tokenCacheHitsDesc = prometheus.NewDesc(namespace+"_token_cache_hits_total",
+		"Number of bearer tokens authenticated from the TokenReview cache.",
+		nil, nil)
+	tokenCacheMissesDesc = prometheus.NewDesc(namespace+"_token_cache_misses_total",
+		"Number of bearer tokens not in the TokenReview cache, each costing a TokenReview.",
+		nil, nil)
+	tokenCacheEntriesDesc = prometheus.NewDesc(namespace+"_token_cache_entries",
+		"Number of TokenReview results currently cached.",
+		nil, nil)
+)
+
+// informerCollector reports the sync state of the tracked informers when scraped
+type informerCollector struct {
+	mu     sync.Mutex
+	synced map[string]func() bool
+}
+
+var informers = &informerCollector{synced: map[string]func() bool{}}
+
+// TrackInformer reports the sync state of an informer under the resource name
+func TrackInformer(resource string, hasSynced func() bool) {
+	informers.mu.Lock()
+	defer informers.mu.Unlock()
+	informers.synced[resource] = hasSynced
+}
+
+// Describe implements prometheus.Collector
+func (i *informerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- informerSyncedDesc
+}
+
+// Collect implements prometheus.Collector
+func (i *informerCollector) Collect(ch chan<- prometheus.Metric) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for resource, hasSynced := range i.synced {
+		value := 0.0
+		if hasSynced() {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(informerSyncedDesc, prometheus.GaugeValue, value, resource)
+	}
+}
+
+// FleetListers are the informer listers the fleet gauges are computed from
+type FleetListers struct {
+	Clusters   clusterlistersv1.ManagedClusterLister
+	Placements clusterlistersv1beta1.PlacementLister
+	Addons     addonlisters.ManagedClusterAddOnLister
+}
+
+// fleetCollector computes the fleet gauges from the informer caches when
+// scraped, so they are never staler than the caches themselves
+type fleetCollector struct {
+	mu      sync.Mutex
+	listers *FleetListers
+}
+
+var fleet = &fleetCollector{}
+
+// WatchFleet reports the fleet gauges from the given listers. Until it is
+// called no fleet gauges are exported.
+func WatchFleet(listers FleetListers) {
+	fleet.mu.Lock()
+	defer fleet.mu.Unlock()
+	fleet.listers = &listers
+}
+
+// Describe implements prometheus.Collector
+func (f *fleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- fleetClustersDesc
+	ch <- fleetUnsatisfiedPlacementsDesc
+	ch <- fleetDegradedAddonsDesc
+}
+
+// Collect implements prometheus.Collector
+func (f *fleetCollector) Collect(ch chan<- prometheus.Metric) {
+	f.mu.Lock()
+	listers := f.listers
+	f.mu.Unlock()
+	if listers == nil {
+		return
+	}
+
+	if listers.Clusters != nil {
+		if clusters, err := listers.Clusters.List(labels.Everything()); err == nil {
+			counts := map[string]int{"True": 0, "False": 0, "Unknown": 0}
+			for _, cluster := range clusters {
+				status := "Unknown"
+				if condition := meta.FindStatusCondition(cluster.Status.Conditions, clusterv1.ManagedClusterConditionAvailable); condition != nil {
+					status = string(condition.Status)
+				}
+				counts[status]++
+			}
+			for status, count := range counts {
+				ch <- prometheus.MustNewConstMetric(fleetClustersDesc, prometheus.GaugeValue, float64(count), status)
+			}
+		}
+	}
+
+	if listers.Placements != nil {
+		if placements, err := listers.Placements.List(labels.Everything()); err == nil {
+			unsatisfied := 0
+			for _, placement := range placements {
+				if !meta.IsStatusConditionTrue(placement.Status.Conditions, clusterv1beta1.PlacementConditionSatisfied) {
+					unsatisfied++
+				}
+			}
+			ch <- prometheus.MustNewConstMetric(fleetUnsatisfiedPlacementsDesc, prometheus.GaugeValue, float64(unsatisfied))
+		}
+	}
+
+	if listers.Addons != nil {
+		if addons, err := listers.Addons.List(labels.Everything()); err == nil {
+			degraded := map[string]int{}
+			for _, addon := range addons {
+				count := degraded[addon.Name]
+				if meta.IsStatusConditionTrue(addon.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionDegraded) {
+					count++
+				}
+				degraded[addon.Name] = count
+			}
+			for name, count := range degraded {
+				ch <- prometheus.MustNewConstMetric(fleetDegradedAddonsDesc, prometheus.GaugeValue, float64(count), name)
+			}
+		}
+	}
+}
+
+// TokenCacheStats reports how the TokenReview cache is performing
+type TokenCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// tokenCacheCollector reports the TokenReview cache counters when scraped
+type tokenCacheCollector struct {
+	mu    sync.Mutex
+	stats func() TokenCacheStats
+}
+
+var tokenCache = &tokenCacheCollector{}
+
+// TrackTokenCache reports the TokenReview cache counters returned by stats.
+// Until it is called no token cache metrics are exported.
+func TrackTokenCache(stats func() TokenCacheStats) {
+	tokenCache.mu.Lock()
+	defer tokenCache.mu.Unlock()
+	tokenCache.stats = stats
+}
+
+// Describe implements prometheus.Collector
+func (t *tokenCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tokenCacheHitsDesc
+	ch <- tokenCacheMissesDesc
+	ch <- tokenCacheEntriesDesc
+}
+
+// Collect implements prometheus.Collector
+func (t *tokenCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	stats := t.stats
+	t.mu.Unlock()
+	if stats == nil {
+		return
+	}
+
+	current := stats()
+	ch <- prometheus.MustNewConstMetric(tokenCacheHitsDesc, prometheus.CounterValue, float64(current.Hits))
+	ch <- prometheus.MustNewConstMetric(tokenCacheMissesDesc, prometheus.CounterValue, float64(current.Misses))
+	ch <- prometheus.MustNewConstMetric(tokenCacheEntriesDesc, prometheus.GaugeValue, float64(current.Entries))
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	addonlisters "open-cluster-management.io/api/client/addon/listers/addon/v1alpha1"
+	clusterlistersv1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1"
+	clusterlistersv1beta1 "open-cluster-management.io/api/client/cluster/listers/cluster/v1beta1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+)
+
+func newIndexer(t *testing.T, objs ...interface{}) cache.Indexer {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		require.NoError(t, indexer.Add(obj))
+	}
+	return indexer
+}
+
+func withCondition(conditionType string, status metav1.ConditionStatus) []metav1.Condition {
+	return []metav1.Condition{{Type: conditionType, Status: status}}
+}
+
+func TestFleetCollector(t *testing.T) {
+	clusters := newIndexer(t,
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+			Status:     clusterv1.ManagedClusterStatus{Conditions: withCondition(clusterv1.ManagedClusterConditionAvailable, metav1.ConditionTrue)},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster2"},
+			Status:     clusterv1.ManagedClusterStatus{Conditions: withCondition(clusterv1.ManagedClusterConditionAvailable, metav1.ConditionTrue)},
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster3"}},
+	)
+	placements := newIndexer(t,
+		&clusterv1beta1.Placement{
+			ObjectMeta: metav1.ObjectMeta{Name: "satisfied", Namespace: "default"},
+			Status:     clusterv1beta1.PlacementStatus{Conditions: withCondition(clusterv1beta1.PlacementConditionSatisfied, metav1.ConditionTrue)},
+		},
+		&clusterv1beta1.Placement{
+			ObjectMeta: metav1.ObjectMeta{Name: "unsatisfied", Namespace: "default"},
+			Status:     clusterv1beta1.PlacementStatus{Conditions: withCondition(clusterv1beta1.PlacementConditionSatisfied, metav1.ConditionFalse)},
+		},
+	)
+	addons := newIndexer(t,
+		&addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: "governance", Namespace: "cluster1"},
+			Status:     addonv1alpha1.ManagedClusterAddOnStatus{Conditions: withCondition(addonv1alpha1.ManagedClusterAddOnConditionDegraded, metav1.ConditionTrue)},
+		},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "governance", Namespace: "cluster2"}},
+		&addonv1alpha1.ManagedClusterAddOn{ObjectMeta: metav1.ObjectMeta{Name: "observability", Namespace: "cluster1"}},
+	)
+
+	collector := &fleetCollector{}
+	assert.Equal(t, 0, testutil.CollectAndCount(collector), "no gauges before the listers are set")
+
+	collector.listers = &FleetListers{
+		Clusters:   clusterlistersv1.NewManagedClusterLister(clusters),
+		Placements: clusterlistersv1beta1.NewPlacementLister(placements),
+		Addons:     addonlisters.NewManagedClusterAddOnLister(addons),
+	}
+
+	expected := `
+# HELP ocm_dashboard_fleet_clusters Number of ManagedClusters by the status of their ManagedClusterConditionAvailable condition.
+# TYPE ocm_dashboard_fleet_clusters gauge
+ocm_dashboard_fleet_clusters{available="False"} 0
+ocm_dashboard_fleet_clusters{available="True"} 2
+ocm_dashboard_fleet_clusters{available="Unknown"} 1
+# HELP ocm_dashboard_fleet_degraded_addons Number of ManagedClusterAddOns whose Degraded condition is True, by addon name. Every installed addon is reported.
+# TYPE ocm_dashboard_fleet_degraded_addons gauge
+ocm_dashboard_fleet_degraded_addons{addon="governance"} 1
+ocm_dashboard_fleet_degraded_addons{addon="observability"} 0
+# HELP ocm_dashboard_fleet_unsatisfied_placements Number of Placements whose PlacementSatisfied condition is not True.
+# TYPE ocm_dashboard_fleet_unsatisfied_placements gauge
+ocm_dashboard_fleet_unsatisfied_placements 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestInformerCollector(t *testing.T) {
+	synced := false
+	collector := &informerCollector{synced: map[string]func() bool{
+		"managedclusters": func() bool { return true },
+		"placements":      func() bool { return synced },
+	}}
+
+	expected := `
+# HELP ocm_dashboard_informer_synced Whether the informer cache of a resource has completed its initial sync (1) or not (0).
+# TYPE ocm_dashboard_informer_synced gauge
+ocm_dashboard_informer_synced{resource="managedclusters"} 1
+ocm_dashboard_informer_synced{resource="placements"} %s
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(strings.Replace(expected, "%s", "0", 1))))
+
+	synced = true
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(strings.Replace(expected, "%s", "1", 1))))
+}
+
+func TestTokenCacheCollector(t *testing.T) {
+	collector := &tokenCacheCollector{}
+	assert.Equal(t, 0, testutil.CollectAndCount(collector), "no metrics before the cache is tracked")
+
+	collector.stats = func() TokenCacheStats {
+		return TokenCacheStats{Hits: 5, Misses: 2, Entries: 1}
+	}
+	expected := `
+# HELP ocm_dashboard_token_cache_entries Number of TokenReview results currently cached.
+# TYPE ocm_dashboard_token_cache_entries gauge
+ocm_dashboard_token_cache_entries 1
+# HELP ocm_dashboard_token_cache_hits_total Number of bearer tokens authenticated from the TokenReview cache.
+# TYPE ocm_dashboard_token_cache_hits_total counter
+ocm_dashboard_token_cache_hits_total 5
+# HELP ocm_dashboard_token_cache_misses_total Number of bearer tokens not in the TokenReview cache, each costing a TokenReview.
+# TYPE ocm_dashboard_token_cache_misses_total counter
+ocm_dashboard_token_cache_misses_total 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric the dashboard exports
+const namespace = "ocm_dashboard"
+
+// Registry holds the dashboard metrics. A dedicated registry keeps them apart
+// from anything client libraries register on the global one.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RequestDuration observes the latency of API requests by route and status
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	// RequestsTotal counts API requests by route and status
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	// TokenReviewDuration observes the TokenReview calls made on cache misses
+	TokenReviewDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tokenreview_duration_seconds",
+		Help:      "Latency of TokenReview requests sent to the Kubernetes API server.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// TokenReviewFailures counts tokens that could not be authenticated, either
+	// because the TokenReview failed (error) or the token was rejected
+	TokenReviewFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokenreview_failures_total",
+		Help:      "Number of TokenReview requests that failed (error) or rejected the token (rejected).",
+	}, []string{"reason"})
+
+	// StreamConnections is the number of open SSE connections per stream
+	StreamConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_connections",
+		Help:      "Number of open SSE connections by stream.",
+	}, []string{"stream"})
+
+	// StreamEvents counts the events sent to SSE clients per stream and type
+	StreamEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_events_total",
+		Help:      "Number of events sent to SSE clients by stream and event type.",
+	}, []string{"stream", "type"})
+
+	// KubeClientErrors counts failed requests to the Kubernetes API server
+	KubeClientErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kube_client_errors_total",
+		Help:      "Number of failed requests to the Kubernetes API server by resource, verb and status code (error when no response was received).",
+	}, []string{"resource", "verb", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestDuration,
+		RequestsTotal,
+		TokenReviewDuration,
+		TokenReviewFailures,
+		StreamConnections,
+		StreamEvents,
+		KubeClientErrors,
+		informers,
+		fleet,
+		tokenCache,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records the latency and status of every request. Requests are
+// labelled with the route pattern rather than the path, so that names and
+// namespaces in the path do not create a series each.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		code := strconv.Itoa(c.Writer.Status())
+		RequestDuration.WithLabelValues(c.Request.Method, route, code).Observe(time.Since(start).Seconds())
+		RequestsTotal.WithLabelValues(c.Request.Method, route, code).Inc()
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/api/clusters/:name", func(c *gin.Context) {
+		if c.Param("name") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	ok := RequestsTotal.WithLabelValues(http.MethodGet, "/api/clusters/:name", "200")
+	notFound := RequestsTotal.WithLabelValues(http.MethodGet, "/api/clusters/:name", "404")
+	unmatched := RequestsTotal.WithLabelValues(http.MethodGet, "unmatched", "404")
+	okBefore, notFoundBefore, unmatchedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(notFound), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/api/clusters/cluster1", "/api/clusters/cluster2", "/api/clusters/missing", "/api/unknown"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	// Requests are labelled with the route, not the path
+	assert.Equal(t, okBefore+2, testutil.ToFloat64(ok))
+	assert.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFound))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/metrics", Handler())
+
+	// The first scrape records itself, the second one exposes it
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		if i == 1 {
+			assert.Contains(t, w.Body.String(), `ocm_dashboard_http_requests_total{code="200",method="GET",route="/metrics"}`)
+			assert.Contains(t, w.Body.String(), "go_goroutines")
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// kubeClientTransport counts the requests to the Kubernetes API server that
+// fail or return an error status
+type kubeClientTransport struct {
+	next http.RoundTripper
+}
+
+// WrapTransport instruments the transport of a Kubernetes client, it is meant
+// for rest.Config.Wrap so every client built from the config is covered
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &kubeClientTransport{next: rt}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *kubeClientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		resource, verb := requestInfo(req)
+		KubeClientErrors.WithLabelValues(resource, verb, "error").Inc()
+	case resp.StatusCode >= http.StatusBadRequest:
+		resource, verb := requestInfo(req)
+		KubeClientErrors.WithLabelValues(resource, verb, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
+
+// requestInfo reads the resource, with its subresource if any, and the API
+// verb from a request to a path such as
+// /apis/<group>/<version>/namespaces/<namespace>/<resource>/<name>. Paths
+// outside the resource API are reported as resource other.
+func requestInfo(req *http.Request) (string, string) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return "other", strings.ToLower(req.Method)
+	}
+
+	// A namespaced resource, unless the namespaces themselves are requested
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	resource := parts[0]
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		if len(parts) > 1 {
+			return resource, "get"
+		}
+		if req.URL.Query().Get("watch") == "true" {
+			return resource, "watch"
+		}
+		return resource, "list"
+	case http.MethodPost:
+		return resource, "create"
+	case http.MethodPut:
+		return resource, "update"
+	case http.MethodPatch:
+		return resource, "patch"
+	case http.MethodDelete:
+		return resource, "delete"
+	}
+	return resource, strings.ToLower(req.Method)
+}
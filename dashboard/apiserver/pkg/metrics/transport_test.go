@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc lets a function stand in for the wrapped transport
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		url              string
+		expectedResource string
+		expectedVerb     string
+	}{
+		{
+			name:             "cluster scoped list",
+			method:           http.MethodGet,
+			url:              "https://hub/apis/cluster.open-cluster-management.io/v1/managedclusters?limit=500",
+			expectedResource: "managedclusters",
+			expectedVerb:     "list",
+		},
+		{
+			name:             "namespaced watch",
+			method:           http.MethodGet,
+			url:              "https://hub/apis/work.open-cluster-management.io/v1/namespaces/cluster1/manifestworks?watch=true",
+			expectedResource: "manifestworks",
+			expectedVerb:     "watch",
+		},
+		{
+			name:             "namespaced get",
+			method:           http.MethodGet,
+			url:              "https://hub/apis/cluster.open-cluster-management.io/v1beta1/namespaces/default/placements/p1",
+			expectedResource: "placements",
+			expectedVerb:     "get",
+		},
+		{
+			name:             "subresource",
+			method:           http.MethodPut,
+			url:              "https://hub/apis/certificates.k8s.io/v1/certificatesigningrequests/csr1/approval",
+			expectedResource: "certificatesigningrequests/approval",
+			expectedVerb:     "update",
+		},
+		{
+			name:             "core group create",
+			method:           http.MethodPost,
+			url:              "https://hub/apis/authentication.k8s.io/v1/tokenreviews",
+			expectedResource: "tokenreviews",
+			expectedVerb:     "create",
+		},
+		{
+			name:             "namespace itself",
+			method:           http.MethodDelete,
+			url:              "https://hub/api/v1/namespaces/cluster1",
+			expectedResource: "namespaces",
+			expectedVerb:     "delete",
+		},
+		{
+			name:             "non resource path",
+			method:           http.MethodGet,
+			url:              "https://hub/version",
+			expectedResource: "other",
+			expectedVerb:     "get",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			require.NoError(t, err)
+
+			resource, verb := requestInfo(req)
+			assert.Equal(t, tt.expectedResource, resource)
+			assert.Equal(t, tt.expectedVerb, verb)
+		})
+	}
+}
+
+func TestWrapTransportCountsErrors(t *testing.T) {
+	status := http.StatusOK
+	var transportErr error
+	rt := WrapTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if transportErr != nil {
+			return nil, transportErr
+		}
+		return &http.Response{StatusCode: status}, nil
+	}))
+
+	send := func() {
+		req, _ := http.NewRequest(http.MethodPatch, "https://hub/apis/cluster.open-cluster-management.io/v1/managedclusters/cluster1", nil)
+		rt.RoundTrip(req)
+	}
+	forbidden := KubeClientErrors.WithLabelValues("managedclusters", "patch", "403")
+	failed := KubeClientErrors.WithLabelValues("managedclusters", "patch", "error")
+	forbiddenBefore, failedBefore := testutil.ToFloat64(forbidden), testutil.ToFloat64(failed)
+
+	// Successful requests are not counted
+	send()
+	assert.Equal(t, forbiddenBefore, testutil.ToFloat64(forbidden))
+
+	status = http.StatusForbidden
+	send()
+	assert.Equal(t, forbiddenBefore+1, testutil.ToFloat64(forbidden))
+
+	transportErr = errors.New("connection refused")
+	send()
+	assert.Equal(t, failedBefore+1, testutil.ToFloat64(failed))
+}
@@ -28,4 +28,213 @@ type ManagedClusterAddon struct {
 	Conditions        []Condition            `json:"conditions,omitempty"`
 	Registrations     []AddonRegistration    `json:"registrations,omitempty"`
 	SupportedConfigs  []AddonSupportedConfig `json:"supportedConfigs,omitempty"`
+	ConfigReferences  []AddonConfigReference `json:"configReferences,omitempty"`
+	ConfigRollout     *AddonConfigRollout    `json:"configRollout,omitempty"`
+}
+
+// AddonConfigReference is a config object an addon uses, with the spec hash
+// the hub wants applied and the one last applied on the cluster. The
+// deployment config is only resolved on the addon detail endpoint.
+type AddonConfigReference struct {
+	Group               string                 `json:"group"`
+	Resource            string                 `json:"resource"`
+	Namespace           string                 `json:"namespace,omitempty"`
+	Name                string                 `json:"name"`
+	DesiredSpecHash     string                 `json:"desiredSpecHash,omitempty"`
+	LastAppliedSpecHash string                 `json:"lastAppliedSpecHash,omitempty"`
+	InSync              bool                   `json:"inSync"`
+	DeploymentConfig    *AddOnDeploymentConfig `json:"deploymentConfig,omitempty"`
+}
+
+// AddonConfigRollout is the state of an addon's config rollout. Stuck is set
+// when the configs are out of sync and the rollout failed or stopped moving.
+type AddonConfigRollout struct {
+	InSync  bool   `json:"inSync"`
+	Stuck   bool   `json:"stuck"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Since   string `json:"since,omitempty"`
+}
+
+// AddOnDeploymentConfig represents a simplified OCM AddOnDeploymentConfig
+type AddOnDeploymentConfig struct {
+	Namespace             string                    `json:"namespace"`
+	Name                  string                    `json:"name"`
+	CustomizedVariables   []AddonCustomizedVariable `json:"customizedVariables,omitempty"`
+	NodePlacement         *AddonNodePlacement       `json:"nodePlacement,omitempty"`
+	Registries            []AddonImageMirror        `json:"registries,omitempty"`
+	ProxyConfig           *AddonProxyConfig         `json:"proxyConfig,omitempty"`
+	AgentInstallNamespace string                    `json:"agentInstallNamespace,omitempty"`
+}
+
+// AddonCustomizedVariable is a name and value passed to the addon's manifests
+type AddonCustomizedVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// AddonNodePlacement is where the addon agent runs on the managed cluster
+type AddonNodePlacement struct {
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations  []AddonToleration `json:"tolerations,omitempty"`
+}
+
+// AddonToleration is a pod toleration of the addon agent
+type AddonToleration struct {
+	Key               string `json:"key,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Value             string `json:"value,omitempty"`
+	Effect            string `json:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
+}
+
+// AddonImageMirror replaces the source image registry with the mirror
+type AddonImageMirror struct {
+	Mirror string `json:"mirror"`
+	Source string `json:"source"`
+}
+
+// AddonProxyConfig is the proxy the addon agent uses, the CA bundle is left out
+type AddonProxyConfig struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
+}
+
+// AddonConfigReferent is the namespace and name of an addon config object
+type AddonConfigReferent struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// AddonConfig is an addon config object of a given group and resource
+type AddonConfig struct {
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// AddonConfigMeta is a config type an addon supports and its default config
+type AddonConfigMeta struct {
+	Group         string               `json:"group"`
+	Resource      string               `json:"resource"`
+	DefaultConfig *AddonConfigReferent `json:"defaultConfig,omitempty"`
+}
+
+// AddonConfigSpecHash is a config object with the hash of its spec
+type AddonConfigSpecHash struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	SpecHash  string `json:"specHash"`
+}
+
+// AddonDefaultConfigReference is the resolved default config of a config type
+type AddonDefaultConfigReference struct {
+	Group         string               `json:"group"`
+	Resource      string               `json:"resource"`
+	DesiredConfig *AddonConfigSpecHash `json:"desiredConfig,omitempty"`
+}
+
+// AddonPlacementStrategy installs an addon on the clusters a placement selects
+type AddonPlacementStrategy struct {
+	Namespace       string          `json:"namespace"`
+	Name            string          `json:"name"`
+	Configs         []AddonConfig   `json:"configs,omitempty"`
+	RolloutStrategy RolloutStrategy `json:"rolloutStrategy"`
+}
+
+// AddonInstallStrategy is how an addon is installed, Manual or Placements
+type AddonInstallStrategy struct {
+	Type       string                   `json:"type"`
+	Placements []AddonPlacementStrategy `json:"placements,omitempty"`
+}
+
+// ClusterManagementAddOn represents a simplified OCM ClusterManagementAddOn
+type ClusterManagementAddOn struct {
+	ID                      string                        `json:"id"`
+	Name                    string                        `json:"name"`
+	DisplayName             string                        `json:"displayName,omitempty"`
+	Description             string                        `json:"description,omitempty"`
+	CreationTimestamp       string                        `json:"creationTimestamp,omitempty"`
+	SupportedConfigs        []AddonConfigMeta             `json:"supportedConfigs,omitempty"`
+	InstallStrategy         AddonInstallStrategy          `json:"installStrategy"`
+	DefaultConfigReferences []AddonDefaultConfigReference `json:"defaultConfigReferences,omitempty"`
+}
+
+// AddonHealth is the state of one addon on one cluster. The condition fields
+// hold the condition status, empty when the condition is not reported.
+type AddonHealth struct {
+	Installed   bool   `json:"installed"`
+	Available   string `json:"available,omitempty"`
+	Degraded    string `json:"degraded,omitempty"`
+	Progressing string `json:"progressing,omitempty"`
+}
+
+// AddonHealthRow is the state of every addon on one cluster, keyed by addon name
+type AddonHealthRow struct {
+	ClusterName string                 `json:"clusterName"`
+	Addons      map[string]AddonHealth `json:"addons"`
+}
+
+// AddonHealthMatrix is the state of every addon on every cluster
+type AddonHealthMatrix struct {
+	Addons   []string         `json:"addons"`
+	Clusters []AddonHealthRow `json:"clusters"`
+}
+
+// AddonRollout is the rollout progress of a ClusterManagementAddOn for each
+// placement of its install strategy
+type AddonRollout struct {
+	Name       string                  `json:"name"`
+	Placements []AddonPlacementRollout `json:"placements"`
+}
+
+// AddonPlacementRollout is the rollout progress of an addon on the clusters
+// one placement selects. ActiveDecisionGroup is the first decision group with
+// clusters that have not succeeded yet, unset once the rollout is complete.
+type AddonPlacementRollout struct {
+	Namespace           string                     `json:"namespace"`
+	Name                string                     `json:"name"`
+	RolloutStrategy     RolloutStrategy            `json:"rolloutStrategy"`
+	Configs             []AddonInstallConfig       `json:"configs,omitempty"`
+	Conditions          []Condition                `json:"conditions,omitempty"`
+	ActiveDecisionGroup *AddonRolloutDecisionGroup `json:"activeDecisionGroup,omitempty"`
+	Summary             AddonRolloutSummary        `json:"summary"`
+	Clusters            []AddonRolloutCluster      `json:"clusters"`
+}
+
+// AddonInstallConfig is a config rolled out to a placement's clusters
+type AddonInstallConfig struct {
+	Group               string               `json:"group"`
+	Resource            string               `json:"resource"`
+	DesiredConfig       *AddonConfigSpecHash `json:"desiredConfig,omitempty"`
+	LastKnownGoodConfig *AddonConfigSpecHash `json:"lastKnownGoodConfig,omitempty"`
+	LastAppliedConfig   *AddonConfigSpecHash `json:"lastAppliedConfig,omitempty"`
+}
+
+// AddonRolloutDecisionGroup is a decision group of a placement
+type AddonRolloutDecisionGroup struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+}
+
+// AddonRolloutSummary counts the clusters of a placement by rollout status
+type AddonRolloutSummary struct {
+	Total       int `json:"total"`
+	ToApply     int `json:"toApply"`
+	Progressing int `json:"progressing"`
+	Succeeded   int `json:"succeeded"`
+	Failed      int `json:"failed"`
+	TimeOut     int `json:"timeOut"`
+}
+
+// AddonRolloutCluster is the rollout status of the addon on one cluster, one
+// of ToApply, Progressing, Succeeded, Failed or TimeOut
+type AddonRolloutCluster struct {
+	ClusterName        string `json:"clusterName"`
+	DecisionGroupIndex int    `json:"decisionGroupIndex"`
+	DecisionGroupName  string `json:"decisionGroupName,omitempty"`
+	Status             string `json:"status"`
+	Since              string `json:"since,omitempty"`
 }
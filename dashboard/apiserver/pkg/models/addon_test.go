@@ -80,3 +80,126 @@ func TestManagedClusterAddonModel(t *testing.T) {
 	assert.Len(t, addon.SupportedConfigs, 1)
 	assert.Equal(t, "addon.open-cluster-management.io", addon.SupportedConfigs[0].Group)
 }
+
+func TestClusterManagementAddOnJSON(t *testing.T) {
+	assertJSONRoundTrip(t,
+		ClusterManagementAddOn{
+			ID:          "1234",
+			Name:        "governance-policy-framework",
+			DisplayName: "Policy Framework",
+			SupportedConfigs: []AddonConfigMeta{
+				{
+					Group:         "addon.open-cluster-management.io",
+					Resource:      "addondeploymentconfigs",
+					DefaultConfig: &AddonConfigReferent{Namespace: "open-cluster-management", Name: "default"},
+				},
+				{Group: "addon.open-cluster-management.io", Resource: "addontemplates"},
+			},
+			InstallStrategy: AddonInstallStrategy{
+				Type: "Placements",
+				Placements: []AddonPlacementStrategy{
+					{Namespace: "default", Name: "all", RolloutStrategy: RolloutStrategy{Type: "All"}},
+				},
+			},
+			DefaultConfigReferences: []AddonDefaultConfigReference{
+				{
+					Group:         "addon.open-cluster-management.io",
+					Resource:      "addondeploymentconfigs",
+					DesiredConfig: &AddonConfigSpecHash{Namespace: "open-cluster-management", Name: "default", SpecHash: "abc"},
+				},
+			},
+		},
+		`{
+			"id": "1234",
+			"name": "governance-policy-framework",
+			"displayName": "Policy Framework",
+			"supportedConfigs": [
+				{
+					"group": "addon.open-cluster-management.io",
+					"resource": "addondeploymentconfigs",
+					"defaultConfig": {"namespace": "open-cluster-management", "name": "default"}
+				},
+				{"group": "addon.open-cluster-management.io", "resource": "addontemplates"}
+			],
+			"installStrategy": {
+				"type": "Placements",
+				"placements": [{"namespace": "default", "name": "all", "rolloutStrategy": {"type": "All"}}]
+			},
+			"defaultConfigReferences": [
+				{
+					"group": "addon.open-cluster-management.io",
+					"resource": "addondeploymentconfigs",
+					"desiredConfig": {"namespace": "open-cluster-management", "name": "default", "specHash": "abc"}
+				}
+			]
+		}`)
+
+	// A manually installed addon without configs only sends its install strategy type
+	assertJSONRoundTrip(t,
+		ClusterManagementAddOn{ID: "5678", Name: "managed-serviceaccount", InstallStrategy: AddonInstallStrategy{Type: "Manual"}},
+		`{"id": "5678", "name": "managed-serviceaccount", "installStrategy": {"type": "Manual"}}`)
+}
+
+func TestAddonHealthMatrixJSON(t *testing.T) {
+	// Conditions a cluster does not report are left out, installed is always sent
+	assertJSONRoundTrip(t,
+		AddonHealthMatrix{
+			Addons: []string{"observability"},
+			Clusters: []AddonHealthRow{
+				{ClusterName: "cluster1", Addons: map[string]AddonHealth{"observability": {Installed: true, Available: "True", Degraded: "False"}}},
+				{ClusterName: "cluster2", Addons: map[string]AddonHealth{"observability": {}}},
+			},
+		},
+		`{
+			"addons": ["observability"],
+			"clusters": [
+				{"clusterName": "cluster1", "addons": {"observability": {"installed": true, "available": "True", "degraded": "False"}}},
+				{"clusterName": "cluster2", "addons": {"observability": {"installed": false}}}
+			]
+		}`)
+}
+
+func TestAddonConfigReferenceModel(t *testing.T) {
+	ref := AddonConfigReference{
+		Group:               "addon.open-cluster-management.io",
+		Resource:            "addondeploymentconfigs",
+		Namespace:           "open-cluster-management",
+		Name:                "default",
+		DesiredSpecHash:     "new",
+		LastAppliedSpecHash: "old",
+		DeploymentConfig: &AddOnDeploymentConfig{
+			Name:                "default",
+			CustomizedVariables: []AddonCustomizedVariable{{Name: "retention", Value: "24h"}},
+			Registries:          []AddonImageMirror{{Source: "quay.io", Mirror: "mirror.example.com"}},
+		},
+	}
+
+	assert.False(t, ref.InSync)
+	assert.NotEqual(t, ref.DesiredSpecHash, ref.LastAppliedSpecHash)
+	assert.Equal(t, "24h", ref.DeploymentConfig.CustomizedVariables[0].Value)
+	assert.Equal(t, "mirror.example.com", ref.DeploymentConfig.Registries[0].Mirror)
+}
+
+func TestAddonRolloutModel(t *testing.T) {
+	rollout := AddonRollout{
+		Name: "observability",
+		Placements: []AddonPlacementRollout{
+			{
+				Namespace:           "default",
+				Name:                "prod",
+				RolloutStrategy:     RolloutStrategy{Type: "ProgressivePerGroup"},
+				ActiveDecisionGroup: &AddonRolloutDecisionGroup{Index: 1, Name: "prod-eu"},
+				Summary:             AddonRolloutSummary{Total: 2, Succeeded: 1, Progressing: 1},
+				Clusters: []AddonRolloutCluster{
+					{ClusterName: "cluster1", DecisionGroupIndex: 0, Status: "Succeeded"},
+					{ClusterName: "cluster2", DecisionGroupIndex: 1, DecisionGroupName: "prod-eu", Status: "Progressing"},
+				},
+			},
+		},
+	}
+
+	placement := rollout.Placements[0]
+	assert.Equal(t, 1, placement.ActiveDecisionGroup.Index)
+	assert.Equal(t, placement.Summary.Total, len(placement.Clusters))
+	assert.Equal(t, "Progressing", placement.Clusters[1].Status)
+}
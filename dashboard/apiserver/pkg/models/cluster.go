@@ -41,11 +41,20 @@ type Cluster struct {
 	Taints                      []Taint                      `json:"taints,omitempty"`
 	ManagedClusterClientConfigs []ManagedClusterClientConfig `json:"managedClusterClientConfigs,omitempty"`
 	CreationTimestamp           string                       `json:"creationTimestamp,omitempty"`
+	// SkippedCSRs lists the pending CSRs accepting the cluster left pending
+	SkippedCSRs []SkippedCSR `json:"skippedCSRs,omitempty"`
+}
+
+// ClusterLabelsPatch is the request body for patching cluster labels. A null
+// value removes the label.
+type ClusterLabelsPatch struct {
+	Labels map[string]*string `json:"labels"`
 }
 
 // LabelSelector represents a Kubernetes label selector
 type LabelSelector struct {
-	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	MatchLabels      map[string]string `json:"matchLabels,omitempty"`
+	MatchExpressions []MatchExpression `json:"matchExpressions,omitempty"`
 }
 
 // ClusterSelector represents the selector for clusters in a ManagedClusterSet
@@ -0,0 +1,19 @@
+package models
+
+// ClusterPlacementRelation is a placement whose decisions select a cluster
+type ClusterPlacementRelation struct {
+	Namespace         string `json:"namespace"`
+	Name              string `json:"name"`
+	DecisionName      string `json:"decisionName"`
+	DecisionGroupName string `json:"decisionGroupName,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// ClusterRelations lists everything that targets a cluster
+type ClusterRelations struct {
+	ClusterName   string                     `json:"clusterName"`
+	Placements    []ClusterPlacementRelation `json:"placements"`
+	ClusterSets   []string                   `json:"clusterSets"`
+	ManifestWorks []ManifestWork             `json:"manifestWorks"`
+	Addons        []ManagedClusterAddon      `json:"addons"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterRelationsModel(t *testing.T) {
+	relations := ClusterRelations{
+		ClusterName: "cluster1",
+		Placements: []ClusterPlacementRelation{
+			{Namespace: "app", Name: "web", DecisionName: "web-decision-1", DecisionGroupName: "canary"},
+		},
+		ClusterSets:   []string{"default", "global"},
+		ManifestWorks: []ManifestWork{},
+		Addons:        []ManagedClusterAddon{},
+	}
+
+	data, err := json.Marshal(relations)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "cluster1", decoded["clusterName"])
+	assert.Len(t, decoded["placements"], 1)
+	assert.Len(t, decoded["clusterSets"], 2)
+	// Empty lists are sent as [] so the UI does not need null checks
+	assert.Equal(t, []interface{}{}, decoded["manifestWorks"])
+	assert.Equal(t, []interface{}{}, decoded["addons"])
+}
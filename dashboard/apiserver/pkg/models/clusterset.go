@@ -10,12 +10,37 @@ type ClusterSetStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 }
 
+// ClusterSetMembership is the resolved membership of a ManagedClusterSet
+type ClusterSetMembership struct {
+	ClusterNames    []string `json:"clusterNames"`
+	ClusterCount    int      `json:"clusterCount"`
+	OnlineCount     int      `json:"onlineCount"`
+	OfflineCount    int      `json:"offlineCount"`
+	BoundNamespaces []string `json:"boundNamespaces"`
+	// Clusters is only set when the request asks for ?expand=clusters
+	Clusters []Cluster `json:"clusters,omitempty"`
+}
+
 // ClusterSet represents a simplified OCM ManagedClusterSet
 type ClusterSet struct {
-	ID                string            `json:"id"`
-	Name              string            `json:"name"`
-	Labels            map[string]string `json:"labels,omitempty"`
-	Spec              ClusterSetSpec    `json:"spec,omitempty"`
-	Status            ClusterSetStatus  `json:"status,omitempty"`
-	CreationTimestamp string            `json:"creationTimestamp,omitempty"`
+	ID                string                `json:"id"`
+	Name              string                `json:"name"`
+	Labels            map[string]string     `json:"labels,omitempty"`
+	Spec              ClusterSetSpec        `json:"spec,omitempty"`
+	Status            ClusterSetStatus      `json:"status,omitempty"`
+	Membership        *ClusterSetMembership `json:"membership,omitempty"`
+	CreationTimestamp string                `json:"creationTimestamp,omitempty"`
+}
+
+// ClusterSetMoveRequest is the request body for moving clusters into a cluster set
+type ClusterSetMoveRequest struct {
+	Clusters []string `json:"clusters"`
+}
+
+// ClusterSetMoveResult is the outcome of moving one cluster into a cluster set
+type ClusterSetMoveResult struct {
+	Cluster string `json:"cluster"`
+	// PreviousClusterSet is the set the cluster belonged to before the move
+	PreviousClusterSet string `json:"previousClusterSet,omitempty"`
+	Error              string `json:"error,omitempty"`
 }
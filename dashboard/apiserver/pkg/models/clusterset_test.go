@@ -1,9 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClusterSetSpecModel(t *testing.T) {
@@ -82,3 +84,38 @@ func TestClusterSetWithMinimalFields(t *testing.T) {
 	assert.Nil(t, clusterSet.Labels)
 	assert.Empty(t, clusterSet.CreationTimestamp)
 }
+
+func TestClusterSetMoveJSON(t *testing.T) {
+	assertJSONRoundTrip(t, ClusterSetMoveRequest{Clusters: []string{"cluster1", "cluster2"}}, `{"clusters": ["cluster1", "cluster2"]}`)
+
+	// A cluster without a previous set or an error only reports its name
+	assertJSONRoundTrip(t,
+		[]ClusterSetMoveResult{
+			{Cluster: "cluster1", PreviousClusterSet: "default"},
+			{Cluster: "cluster2"},
+			{Cluster: "cluster3", Error: "forbidden"},
+		},
+		`[
+			{"cluster": "cluster1", "previousClusterSet": "default"},
+			{"cluster": "cluster2"},
+			{"cluster": "cluster3", "error": "forbidden"}
+		]`)
+}
+
+func TestClusterSetMembershipJSON(t *testing.T) {
+	// Clusters are only sent when expanded
+	assertJSONRoundTrip(t,
+		ClusterSetMembership{
+			ClusterNames:    []string{"cluster1", "cluster2"},
+			ClusterCount:    2,
+			OnlineCount:     1,
+			OfflineCount:    1,
+			BoundNamespaces: []string{"app"},
+		},
+		`{"clusterNames": ["cluster1", "cluster2"], "clusterCount": 2, "onlineCount": 1, "offlineCount": 1, "boundNamespaces": ["app"]}`)
+
+	// Cluster sets listed without membership leave it out
+	data, err := json.Marshal(ClusterSet{ID: "dev", Name: "dev"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "membership")
+}
@@ -1,11 +1,26 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// assertJSONRoundTrip checks the wire form of a model and that decoding it
+// gives the model back
+func assertJSONRoundTrip[T any](t *testing.T, value T, expected string) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(data))
+
+	var decoded T
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, value, decoded)
+}
+
 func TestConditionModel(t *testing.T) {
 	condition := Condition{
 		Type:               "Available",
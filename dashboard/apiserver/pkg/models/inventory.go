@@ -0,0 +1,45 @@
+package models
+
+// InventoryResources is the sum of a resource across clusters, as a quantity
+// and in base units
+type InventoryResources struct {
+	CPU           string `json:"cpu"`
+	CPUMillicores int64  `json:"cpuMillicores"`
+	Memory        string `json:"memory"`
+	MemoryBytes   int64  `json:"memoryBytes"`
+}
+
+// InventorySummary counts a set of clusters and sums their resources
+type InventorySummary struct {
+	Clusters    int                `json:"clusters"`
+	Available   int                `json:"available"`
+	Capacity    InventoryResources `json:"capacity"`
+	Allocatable InventoryResources `json:"allocatable"`
+}
+
+// InventoryGroup is the clusters sharing a value for every groupBy dimension
+type InventoryGroup struct {
+	Values map[string]string `json:"values"`
+	InventorySummary
+}
+
+// InventoryBucket is the clusters sharing one value of a facet dimension
+type InventoryBucket struct {
+	Value string `json:"value"`
+	InventorySummary
+}
+
+// InventoryFacet counts clusters by each value of one dimension
+type InventoryFacet struct {
+	Dimension string            `json:"dimension"`
+	Buckets   []InventoryBucket `json:"buckets"`
+}
+
+// Inventory aggregates the fleet. Dimensions are label:<key>, claim:<name>,
+// version or status, clusters without a value are counted under "".
+type Inventory struct {
+	Summary InventorySummary `json:"summary"`
+	GroupBy []string         `json:"groupBy,omitempty"`
+	Groups  []InventoryGroup `json:"groups,omitempty"`
+	Facets  []InventoryFacet `json:"facets,omitempty"`
+}
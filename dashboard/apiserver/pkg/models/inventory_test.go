@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryJSON(t *testing.T) {
+	empty := `"capacity": {"cpu": "", "cpuMillicores": 0, "memory": "", "memoryBytes": 0}, "allocatable": {"cpu": "", "cpuMillicores": 0, "memory": "", "memoryBytes": 0}`
+
+	// Group summaries sit next to their values
+	assertJSONRoundTrip(t,
+		Inventory{
+			Summary: InventorySummary{Clusters: 3, Available: 2},
+			GroupBy: []string{"status"},
+			Groups: []InventoryGroup{
+				{Values: map[string]string{"status": "Online"}, InventorySummary: InventorySummary{Clusters: 2, Available: 2}},
+			},
+		},
+		`{
+			"summary": {"clusters": 3, "available": 2, `+empty+`},
+			"groupBy": ["status"],
+			"groups": [{"values": {"status": "Online"}, "clusters": 2, "available": 2, `+empty+`}]
+		}`)
+
+	// Without groupBy and facets only the summary is sent
+	assertJSONRoundTrip(t,
+		Inventory{Summary: InventorySummary{Clusters: 1}},
+		`{"summary": {"clusters": 1, "available": 0, `+empty+`}}`)
+}
+
+func TestInventoryBucketJSON(t *testing.T) {
+	bucket := InventoryBucket{
+		Value: "AWS",
+		InventorySummary: InventorySummary{
+			Clusters: 1,
+			Capacity: InventoryResources{CPU: "8", CPUMillicores: 8000, Memory: "32Gi", MemoryBytes: 32 << 30},
+		},
+	}
+
+	data, err := json.Marshal(bucket)
+	require.NoError(t, err)
+
+	// The summary fields sit next to the value
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "AWS", raw["value"])
+	assert.Equal(t, float64(1), raw["clusters"])
+	assert.Equal(t, "32Gi", raw["capacity"].(map[string]interface{})["memory"])
+}
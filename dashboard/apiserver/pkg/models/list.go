@@ -0,0 +1,10 @@
+package models
+
+// ListResponse is a page of a list. Total counts the items matching the
+// filters across all pages, Continue fetches the next page and is empty on
+// the last one.
+type ListResponse[T any] struct {
+	Items    []T    `json:"items"`
+	Total    int    `json:"total"`
+	Continue string `json:"continue,omitempty"`
+}
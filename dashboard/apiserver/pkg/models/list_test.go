@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListResponseJSON(t *testing.T) {
+	page := ListResponse[Cluster]{
+		Items:    []Cluster{{Name: "cluster1"}},
+		Total:    3,
+		Continue: "token",
+	}
+
+	data, err := json.Marshal(page)
+	require.NoError(t, err)
+
+	var decoded ListResponse[Cluster]
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, page, decoded)
+
+	// The last page has no continue token
+	data, err = json.Marshal(ListResponse[Cluster]{Items: []Cluster{}, Total: 0})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items": [], "total": 0}`, string(data))
+}
@@ -7,11 +7,40 @@ type ManifestWork struct {
 	Namespace         string                 `json:"namespace"`
 	Labels            map[string]string      `json:"labels,omitempty"`
 	Manifests         []Manifest             `json:"manifests,omitempty"`
+	DeleteOption      *DeleteOption          `json:"deleteOption,omitempty"`
+	ManifestConfigs   []ManifestConfigOption `json:"manifestConfigs,omitempty"`
+	Executor          *ManifestWorkExecutor  `json:"executor,omitempty"`
 	Conditions        []Condition            `json:"conditions,omitempty"`
 	ResourceStatus    ManifestResourceStatus `json:"resourceStatus,omitempty"`
+	StatusSummary     ManifestStatusSummary  `json:"statusSummary"`
 	CreationTimestamp string                 `json:"creationTimestamp,omitempty"`
 }
 
+// ManifestStatusSummary counts the manifests of a work by their conditions
+type ManifestStatusSummary struct {
+	Total     int `json:"total"`
+	Applied   int `json:"applied"`
+	Available int `json:"available"`
+	Degraded  int `json:"degraded"`
+}
+
+// ManifestWorkExecutor is the identity the work agent applies the manifests as
+type ManifestWorkExecutor struct {
+	Subject ManifestWorkExecutorSubject `json:"subject"`
+}
+
+// ManifestWorkExecutorSubject identifies the executor
+type ManifestWorkExecutorSubject struct {
+	Type           string                   `json:"type"`
+	ServiceAccount *ServiceAccountReference `json:"serviceAccount,omitempty"`
+}
+
+// ServiceAccountReference identifies a service account on the managed cluster
+type ServiceAccountReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
 // Manifest represents a resource to be deployed on a managed cluster
 type Manifest struct {
 	RawExtension map[string]interface{} `json:"rawExtension,omitempty"`
@@ -24,8 +53,24 @@ type ManifestResourceStatus struct {
 
 // ManifestCondition represents the conditions of resources deployed on a managed cluster
 type ManifestCondition struct {
-	ResourceMeta ManifestResourceMeta `json:"resourceMeta"`
-	Conditions   []Condition          `json:"conditions"`
+	ResourceMeta    ManifestResourceMeta `json:"resourceMeta"`
+	StatusFeedbacks []FeedbackValue      `json:"statusFeedbacks,omitempty"`
+	Conditions      []Condition          `json:"conditions"`
+}
+
+// FeedbackValue is a status value returned by a feedback rule
+type FeedbackValue struct {
+	Name       string     `json:"name"`
+	FieldValue FieldValue `json:"fieldValue"`
+}
+
+// FieldValue holds a feedback value, only the field matching Type is set
+type FieldValue struct {
+	Type    string  `json:"type"`
+	Integer *int64  `json:"integer,omitempty"`
+	String  *string `json:"string,omitempty"`
+	Boolean *bool   `json:"boolean,omitempty"`
+	JsonRaw *string `json:"jsonRaw,omitempty"`
 }
 
 // ManifestResourceMeta represents the metadata of a resource in a manifest
@@ -112,6 +157,13 @@ type JsonPath struct {
 	Path    string `json:"path"`
 }
 
+// ManifestWorkRequest is the body for creating or updating a ManifestWork
+type ManifestWorkRequest struct {
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Spec   ManifestWorkSpec  `json:"spec"`
+}
+
 // ManifestWorkList represents a list of ManifestWork objects
 type ManifestWorkList struct {
 	Items []ManifestWork `json:"items"`
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -309,3 +310,56 @@ func TestManifestWorkListModel(t *testing.T) {
 	assert.Equal(t, "manifestwork-1", list.Items[0].Name)
 	assert.Equal(t, "work2", list.Items[1].ID)
 }
+
+func TestManifestWorkRequestModel(t *testing.T) {
+	body := `{
+		"name": "web",
+		"labels": {"app": "web"},
+		"spec": {
+			"workload": [{"rawExtension": {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web"}}}],
+			"deleteOption": {"propagationPolicy": "Orphan"},
+			"manifestConfigs": [{
+				"resourceIdentifier": {"group": "apps", "resource": "deployments", "name": "web", "namespace": "default"},
+				"feedbackRules": [{"type": "WellKnownStatus"}],
+				"updateStrategy": {"type": "ServerSideApply", "serverSideApply": {"force": true, "fieldManager": "dashboard"}}
+			}]
+		}
+	}`
+
+	var request ManifestWorkRequest
+	assert.NoError(t, json.Unmarshal([]byte(body), &request))
+	assert.Equal(t, "web", request.Name)
+	assert.Equal(t, "web", request.Labels["app"])
+	assert.Len(t, request.Spec.Workload, 1)
+	assert.Equal(t, "ConfigMap", request.Spec.Workload[0].RawExtension["kind"])
+	assert.Equal(t, "Orphan", request.Spec.DeleteOption.PropagationPolicy)
+	assert.Equal(t, "deployments", request.Spec.ManifestConfigs[0].ResourceIdentifier.Resource)
+	assert.Equal(t, "WellKnownStatus", request.Spec.ManifestConfigs[0].FeedbackRules[0].Type)
+	assert.True(t, request.Spec.ManifestConfigs[0].UpdateStrategy.ServerSideApply.Force)
+}
+
+func TestManifestWorkStatusModels(t *testing.T) {
+	readyReplicas := int64(2)
+	manifestWork := ManifestWork{
+		Name: "web",
+		Executor: &ManifestWorkExecutor{Subject: ManifestWorkExecutorSubject{
+			Type:           "ServiceAccount",
+			ServiceAccount: &ServiceAccountReference{Namespace: "default", Name: "deployer"},
+		}},
+		ResourceStatus: ManifestResourceStatus{Manifests: []ManifestCondition{{
+			StatusFeedbacks: []FeedbackValue{{Name: "ReadyReplicas", FieldValue: FieldValue{Type: "Integer", Integer: &readyReplicas}}},
+		}}},
+		StatusSummary: ManifestStatusSummary{Total: 1, Applied: 1, Available: 1},
+	}
+
+	data, err := json.Marshal(manifestWork)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]interface{}{"total": float64(1), "applied": float64(1), "available": float64(1), "degraded": float64(0)}, decoded["statusSummary"])
+	assert.Equal(t, "deployer", decoded["executor"].(map[string]interface{})["subject"].(map[string]interface{})["serviceAccount"].(map[string]interface{})["name"])
+
+	feedback := decoded["resourceStatus"].(map[string]interface{})["manifests"].([]interface{})[0].(map[string]interface{})["statusFeedbacks"].([]interface{})[0]
+	assert.Equal(t, map[string]interface{}{"name": "ReadyReplicas", "fieldValue": map[string]interface{}{"type": "Integer", "integer": float64(2)}}, feedback)
+}
@@ -0,0 +1,73 @@
+package models
+
+// ManifestWorkReplicaSet represents a simplified version of the OCM
+// ManifestWorkReplicaSet resource, which creates a ManifestWork on every
+// cluster selected by its placements
+type ManifestWorkReplicaSet struct {
+	ID                 string                          `json:"id"`
+	Name               string                          `json:"name"`
+	Namespace          string                          `json:"namespace"`
+	Labels             map[string]string               `json:"labels,omitempty"`
+	Manifests          []Manifest                      `json:"manifests,omitempty"`
+	PlacementRefs      []LocalPlacementReference       `json:"placementRefs"`
+	Conditions         []Condition                     `json:"conditions,omitempty"`
+	Summary            ManifestWorkReplicaSetSummary   `json:"summary"`
+	PlacementSummaries []ManifestWorkPlacementSummary  `json:"placementSummaries,omitempty"`
+	Clusters           []ManifestWorkReplicaSetCluster `json:"clusters"`
+	CreationTimestamp  string                          `json:"creationTimestamp,omitempty"`
+}
+
+// LocalPlacementReference is a placement in the replica set's namespace and
+// how the works are rolled out to the clusters it selects
+type LocalPlacementReference struct {
+	Name            string          `json:"name"`
+	RolloutStrategy RolloutStrategy `json:"rolloutStrategy"`
+}
+
+// RolloutStrategy flattens the OCM rollout strategy, the settings of the
+// All, Progressive and ProgressivePerGroup types are merged into one object
+type RolloutStrategy struct {
+	Type                    string                   `json:"type"`
+	MinSuccessTime          string                   `json:"minSuccessTime,omitempty"`
+	ProgressDeadline        string                   `json:"progressDeadline,omitempty"`
+	MaxFailures             string                   `json:"maxFailures,omitempty"`
+	MaxConcurrency          string                   `json:"maxConcurrency,omitempty"`
+	MandatoryDecisionGroups []MandatoryDecisionGroup `json:"mandatoryDecisionGroups,omitempty"`
+}
+
+// MandatoryDecisionGroup is a decision group that must succeed before the
+// rollout continues, identified by name or index
+type MandatoryDecisionGroup struct {
+	GroupName  string `json:"groupName,omitempty"`
+	GroupIndex int32  `json:"groupIndex,omitempty"`
+}
+
+// ManifestWorkReplicaSetSummary counts the ManifestWorks of a replica set by state
+type ManifestWorkReplicaSetSummary struct {
+	Total       int `json:"total"`
+	Progressing int `json:"progressing"`
+	Available   int `json:"available"`
+	Degraded    int `json:"degraded"`
+	Applied     int `json:"applied"`
+}
+
+// ManifestWorkPlacementSummary is the rollout progress for one placement
+type ManifestWorkPlacementSummary struct {
+	Name                    string                        `json:"name"`
+	AvailableDecisionGroups string                        `json:"availableDecisionGroups,omitempty"`
+	Summary                 ManifestWorkReplicaSetSummary `json:"summary"`
+}
+
+// ManifestWorkReplicaSetCluster is the ManifestWork a replica set created on
+// one cluster and its state
+type ManifestWorkReplicaSetCluster struct {
+	ClusterName   string                `json:"clusterName"`
+	Placement     string                `json:"placement,omitempty"`
+	ManifestWork  string                `json:"manifestWork"`
+	Applied       bool                  `json:"applied"`
+	Available     bool                  `json:"available"`
+	Degraded      bool                  `json:"degraded"`
+	Progressing   bool                  `json:"progressing"`
+	StatusSummary ManifestStatusSummary `json:"statusSummary"`
+	Conditions    []Condition           `json:"conditions,omitempty"`
+}
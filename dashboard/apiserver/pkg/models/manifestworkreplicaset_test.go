@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestWorkReplicaSetModel(t *testing.T) {
+	replicaSet := ManifestWorkReplicaSet{
+		ID:        "mwrs-1",
+		Name:      "web",
+		Namespace: "app",
+		PlacementRefs: []LocalPlacementReference{{
+			Name: "canary",
+			RolloutStrategy: RolloutStrategy{
+				Type:                    "ProgressivePerGroup",
+				MaxFailures:             "1",
+				MandatoryDecisionGroups: []MandatoryDecisionGroup{{GroupIndex: 0}},
+			},
+		}},
+		Summary: ManifestWorkReplicaSetSummary{Total: 2, Applied: 2, Available: 1},
+		Clusters: []ManifestWorkReplicaSetCluster{
+			{ClusterName: "cluster1", Placement: "canary", ManifestWork: "app-web", Applied: true, Available: true},
+		},
+	}
+
+	data, err := json.Marshal(replicaSet)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "web", decoded["name"])
+	assert.Equal(t, map[string]interface{}{"total": float64(2), "progressing": float64(0), "available": float64(1), "degraded": float64(0), "applied": float64(2)}, decoded["summary"])
+
+	strategy := decoded["placementRefs"].([]interface{})[0].(map[string]interface{})["rolloutStrategy"].(map[string]interface{})
+	assert.Equal(t, "ProgressivePerGroup", strategy["type"])
+	assert.Equal(t, "1", strategy["maxFailures"])
+	assert.NotContains(t, strategy, "maxConcurrency")
+
+	cluster := decoded["clusters"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "cluster1", cluster["clusterName"])
+	assert.Equal(t, true, cluster["available"])
+	assert.Equal(t, false, cluster["degraded"])
+}
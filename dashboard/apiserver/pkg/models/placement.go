@@ -82,6 +82,19 @@ type DecisionStrategy struct {
 	GroupStrategy GroupStrategy `json:"groupStrategy,omitempty"`
 }
 
+// SpreadConstraint represents a spread constraint term of a placement
+type SpreadConstraint struct {
+	TopologyKey       string `json:"topologyKey"`
+	TopologyKeyType   string `json:"topologyKeyType"`
+	MaxSkew           int32  `json:"maxSkew,omitempty"`
+	WhenUnsatisfiable string `json:"whenUnsatisfiable,omitempty"`
+}
+
+// SpreadPolicy represents how placement decisions are spread across topologies
+type SpreadPolicy struct {
+	SpreadConstraints []SpreadConstraint `json:"spreadConstraints,omitempty"`
+}
+
 // PlacementToleration represents a toleration for placement
 type PlacementToleration struct {
 	Key               string `json:"key,omitempty"`
@@ -109,6 +122,7 @@ type Placement struct {
 	NumberOfClusters         *int32                `json:"numberOfClusters,omitempty"`
 	Predicates               []Predicate           `json:"predicates,omitempty"`
 	PrioritizerPolicy        *PrioritizerPolicy    `json:"prioritizerPolicy,omitempty"`
+	SpreadPolicy             *SpreadPolicy         `json:"spreadPolicy,omitempty"`
 	Tolerations              []PlacementToleration `json:"tolerations,omitempty"`
 	DecisionStrategy         *DecisionStrategy     `json:"decisionStrategy,omitempty"`
 	NumberOfSelectedClusters int32                 `json:"numberOfSelectedClusters"`
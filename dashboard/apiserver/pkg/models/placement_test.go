@@ -164,3 +164,20 @@ func TestDecisionGroupStatusModel(t *testing.T) {
 	assert.Len(t, group.Decisions, 2)
 	assert.Equal(t, int32(2), group.ClusterCount)
 }
+
+func TestSpreadPolicyJSON(t *testing.T) {
+	// maxSkew and whenUnsatisfiable are left out when unset so the API defaults apply
+	assertJSONRoundTrip(t,
+		SpreadPolicy{
+			SpreadConstraints: []SpreadConstraint{
+				{TopologyKey: "zone", TopologyKeyType: "Label", MaxSkew: 2, WhenUnsatisfiable: "ScheduleAnyway"},
+				{TopologyKey: "region", TopologyKeyType: "Claim"},
+			},
+		},
+		`{"spreadConstraints": [
+			{"topologyKey": "zone", "topologyKeyType": "Label", "maxSkew": 2, "whenUnsatisfiable": "ScheduleAnyway"},
+			{"topologyKey": "region", "topologyKeyType": "Claim"}
+		]}`)
+
+	assertJSONRoundTrip(t, SpreadPolicy{}, `{}`)
+}
@@ -0,0 +1,13 @@
+package models
+
+// PlacementHistoryEvent records a cluster being added to or removed from a
+// placement's decisions
+type PlacementHistoryEvent struct {
+	Time        string `json:"time"`
+	Namespace   string `json:"namespace"`
+	Placement   string `json:"placement"`
+	ClusterName string `json:"clusterName"`
+	// Type is "Added" or "Removed"
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
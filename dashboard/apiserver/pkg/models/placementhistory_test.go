@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementHistoryEventModel(t *testing.T) {
+	event := PlacementHistoryEvent{
+		Time:        "2025-06-01T12:00:00Z",
+		Namespace:   "app",
+		Placement:   "web",
+		ClusterName: "cluster1",
+		Type:        "Removed",
+		Reason:      "Tolerations: taint cluster.open-cluster-management.io/unreachable:NoSelect is not tolerated",
+	}
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"time": "2025-06-01T12:00:00Z",
+		"namespace": "app",
+		"placement": "web",
+		"clusterName": "cluster1",
+		"type": "Removed",
+		"reason": "Tolerations: taint cluster.open-cluster-management.io/unreachable:NoSelect is not tolerated"
+	}`, string(data))
+}
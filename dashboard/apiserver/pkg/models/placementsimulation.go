@@ -0,0 +1,57 @@
+package models
+
+// SimulatedCluster is a cluster a simulated Placement would select
+type SimulatedCluster struct {
+	ClusterName string `json:"clusterName"`
+	// Score is the weighted sum of the prioritizer scores
+	Score int64 `json:"score"`
+	// Scores holds the unweighted score of each prioritizer, keyed by prioritizer name
+	Scores map[string]int64 `json:"scores,omitempty"`
+}
+
+// RejectedCluster is a cluster of the eligible cluster sets that a simulated
+// Placement would not select
+type RejectedCluster struct {
+	ClusterName string `json:"clusterName"`
+	// Stage is the scheduling step that rejected the cluster: "Predicates",
+	// "Tolerations" or "NumberOfClusters"
+	Stage  string `json:"stage"`
+	Reason string `json:"reason"`
+}
+
+// PlacementSimulation is the result of evaluating a Placement against the hub
+// without creating it
+type PlacementSimulation struct {
+	// ClusterSets are the cluster sets the Placement could select from
+	ClusterSets    []string              `json:"clusterSets"`
+	Selected       []SimulatedCluster    `json:"selected"`
+	Rejected       []RejectedCluster     `json:"rejected"`
+	DecisionGroups []DecisionGroupStatus `json:"decisionGroups"`
+	// Satisfied is false when fewer clusters than numberOfClusters were selected
+	Satisfied bool `json:"satisfied"`
+	// Warnings lists parts of the Placement the simulation could only approximate
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ExplanationStage is the outcome of one scheduling step for a single cluster
+type ExplanationStage struct {
+	// Stage is "ClusterSetBinding", "ClusterSetMembership", "Predicate",
+	// "Tolerations" or "Ranking"
+	Stage string `json:"stage"`
+	// Predicate is the index of the predicate for "Predicate" stages
+	Predicate *int32 `json:"predicate,omitempty"`
+	Passed    bool   `json:"passed"`
+	Details   string `json:"details"`
+}
+
+// PlacementExplanation explains why a Placement does or does not select a cluster
+type PlacementExplanation struct {
+	Placement   string `json:"placement"`
+	Namespace   string `json:"namespace"`
+	ClusterName string `json:"clusterName"`
+	// Selected reports whether the current PlacementDecisions include the cluster
+	Selected bool `json:"selected"`
+	// WouldBeSelected reports whether scheduling the Placement now selects the cluster
+	WouldBeSelected bool               `json:"wouldBeSelected"`
+	Stages          []ExplanationStage `json:"stages"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlacementSimulationJSON(t *testing.T) {
+	assertJSONRoundTrip(t,
+		PlacementSimulation{
+			ClusterSets: []string{"default"},
+			Selected: []SimulatedCluster{
+				{ClusterName: "cluster1", Score: 200, Scores: map[string]int64{"Balance": 100, "Steady": 0}},
+				{ClusterName: "cluster3"},
+			},
+			Rejected: []RejectedCluster{
+				{ClusterName: "cluster2", Stage: "Tolerations", Reason: "taint gpu=true:NoSelect is not tolerated"},
+			},
+			DecisionGroups: []DecisionGroupStatus{
+				{DecisionGroupIndex: 0, Decisions: []string{"cluster1", "cluster3"}, ClusterCount: 2},
+			},
+			Satisfied: true,
+		},
+		`{
+			"clusterSets": ["default"],
+			"selected": [
+				{"clusterName": "cluster1", "score": 200, "scores": {"Balance": 100, "Steady": 0}},
+				{"clusterName": "cluster3", "score": 0}
+			],
+			"rejected": [{"clusterName": "cluster2", "stage": "Tolerations", "reason": "taint gpu=true:NoSelect is not tolerated"}],
+			"decisionGroups": [{"decisionGroupIndex": 0, "decisions": ["cluster1", "cluster3"], "clusterCount": 2}],
+			"satisfied": true
+		}`)
+
+	// An empty simulation still sends its lists, warnings only when there are some
+	assertJSONRoundTrip(t,
+		PlacementSimulation{
+			ClusterSets:    []string{},
+			Selected:       []SimulatedCluster{},
+			Rejected:       []RejectedCluster{},
+			DecisionGroups: []DecisionGroupStatus{},
+			Warnings:       []string{"no cluster set is bound to namespace app"},
+		},
+		`{
+			"clusterSets": [],
+			"selected": [],
+			"rejected": [],
+			"decisionGroups": [],
+			"satisfied": false,
+			"warnings": ["no cluster set is bound to namespace app"]
+		}`)
+}
+
+func TestPlacementExplanationModel(t *testing.T) {
+	explanation := PlacementExplanation{
+		Placement:   "web",
+		Namespace:   "app",
+		ClusterName: "cluster1",
+		Selected:    true,
+		Stages: []ExplanationStage{
+			{Stage: "ClusterSetBinding", Passed: true, Details: "cluster sets bound to namespace app: default"},
+			{Stage: "Predicate", Predicate: IntPtr(0), Passed: false, Details: `labels do not match "env=prod"`},
+		},
+	}
+
+	assert.Equal(t, "web", explanation.Placement)
+	assert.True(t, explanation.Selected)
+	assert.False(t, explanation.WouldBeSelected)
+	assert.Len(t, explanation.Stages, 2)
+	assert.Nil(t, explanation.Stages[0].Predicate)
+	assert.Equal(t, int32(0), *explanation.Stages[1].Predicate)
+	assert.False(t, explanation.Stages[1].Passed)
+}
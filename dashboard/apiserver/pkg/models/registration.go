@@ -0,0 +1,35 @@
+package models
+
+// RegistrationRequest is a CertificateSigningRequest a klusterlet created to join the hub
+type RegistrationRequest struct {
+	Name              string   `json:"name"`
+	Username          string   `json:"username"`
+	Groups            []string `json:"groups,omitempty"`
+	SignerName        string   `json:"signerName"`
+	Status            string   `json:"status"` // "Pending", "Approved" or "Denied"
+	CreationTimestamp string   `json:"creationTimestamp,omitempty"`
+	Age               string   `json:"age,omitempty"`
+}
+
+// Registration is a cluster waiting to be accepted by the hub, or whose
+// registration CSRs are waiting for approval
+type Registration struct {
+	ClusterName string `json:"clusterName"`
+	// ClusterExists reports whether the klusterlet has created the ManagedCluster yet
+	ClusterExists bool `json:"clusterExists"`
+	HubAccepted   bool `json:"hubAccepted"`
+	// Requester is the identity of the most recent pending CSR
+	Requester         string                `json:"requester,omitempty"`
+	CreationTimestamp string                `json:"creationTimestamp,omitempty"`
+	Age               string                `json:"age,omitempty"`
+	Requests          []RegistrationRequest `json:"requests,omitempty"`
+	// SkippedCSRs lists the pending CSRs an approval left pending
+	SkippedCSRs []SkippedCSR `json:"skippedCSRs,omitempty"`
+}
+
+// SkippedCSR is a pending registration CSR that was not approved because it
+// does not request a valid registration certificate
+type SkippedCSR struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationModel(t *testing.T) {
+	registration := Registration{
+		ClusterName:   "cluster1",
+		ClusterExists: true,
+		Requester:     "system:open-cluster-management:cluster1:agent",
+		Requests: []RegistrationRequest{
+			{
+				Name:       "cluster1-abcde",
+				Username:   "system:open-cluster-management:cluster1:agent",
+				SignerName: "kubernetes.io/kube-apiserver-client",
+				Status:     "Pending",
+			},
+		},
+		SkippedCSRs: []SkippedCSR{{Name: "cluster1-fghij", Reason: "CSR cluster1-fghij uses signer \"kubernetes.io/kubelet-serving\""}},
+	}
+
+	data, err := json.Marshal(registration)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "cluster1", decoded["clusterName"])
+	assert.Equal(t, false, decoded["hubAccepted"])
+	assert.Len(t, decoded["requests"], 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"name":   "cluster1-fghij",
+		"reason": "CSR cluster1-fghij uses signer \"kubernetes.io/kubelet-serving\"",
+	}}, decoded["skippedCSRs"])
+}
@@ -0,0 +1,25 @@
+package models
+
+// Workload is a Kubernetes object shipped through ManifestWorks, with every
+// cluster it is shipped to. The counts are numbers of distinct clusters.
+type Workload struct {
+	Group         string                 `json:"group,omitempty"`
+	Kind          string                 `json:"kind"`
+	Namespace     string                 `json:"namespace,omitempty"`
+	Name          string                 `json:"name"`
+	Clusters      int                    `json:"clusters"`
+	Applied       int                    `json:"applied"`
+	Available     int                    `json:"available"`
+	Degraded      int                    `json:"degraded"`
+	ManifestWorks []WorkloadManifestWork `json:"manifestWorks"`
+}
+
+// WorkloadManifestWork is one ManifestWork shipping a workload and the state
+// of the workload's manifest in it
+type WorkloadManifestWork struct {
+	ClusterName  string `json:"clusterName"`
+	ManifestWork string `json:"manifestWork"`
+	Applied      bool   `json:"applied"`
+	Available    bool   `json:"available"`
+	Degraded     bool   `json:"degraded"`
+}
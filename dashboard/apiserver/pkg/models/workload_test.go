@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadModel(t *testing.T) {
+	workload := Workload{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "shop",
+		Name:      "web",
+		Clusters:  2,
+		Applied:   2,
+		Degraded:  1,
+		ManifestWorks: []WorkloadManifestWork{
+			{ClusterName: "cluster1", ManifestWork: "shop", Applied: true},
+			{ClusterName: "cluster2", ManifestWork: "shop", Applied: true, Degraded: true},
+		},
+	}
+
+	data, err := json.Marshal(workload)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "Deployment", decoded["kind"])
+	assert.Equal(t, float64(2), decoded["clusters"])
+	assert.Equal(t, float64(0), decoded["available"])
+	assert.Len(t, decoded["manifestWorks"], 2)
+
+	// Cluster scoped workloads have no namespace
+	data, err = json.Marshal(Workload{Kind: "ClusterRole", Name: "viewer"})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), `"namespace"`)
+	assert.NotContains(t, string(data), `"group"`)
+}
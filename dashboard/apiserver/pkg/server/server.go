@@ -11,42 +11,35 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 	"open-cluster-management-io/lab/apiserver/pkg/handlers"
+	"open-cluster-management-io/lab/apiserver/pkg/metrics"
 
 	authv1 "k8s.io/api/authentication/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// validateToken validates a Bearer token using Kubernetes TokenReview API
-func validateToken(token string, ocmClient *client.OCMClient, ctx context.Context) bool {
-	if ocmClient == nil || ocmClient.KubernetesClient == nil {
-		log.Println("OCM client or Kubernetes client is nil")
-		return false
+// validateToken validates a Bearer token using the cached Kubernetes TokenReview
+// API and returns the authenticated user
+func validateToken(token string, ocmClient *client.OCMClient, ctx context.Context) (*authv1.UserInfo, bool) {
+	if ocmClient == nil || ocmClient.TokenAuthenticator == nil {
+		log.Println("OCM client or token authenticator is nil")
+		return nil, false
 	}
 
-	// Create TokenReview request
-	tokenReview := &authv1.TokenReview{
-		Spec: authv1.TokenReviewSpec{
-			Token: token,
-		},
-	}
-
-	// Send TokenReview to Kubernetes API
-	result, err := ocmClient.KubernetesClient.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
+	user, authenticated, err := ocmClient.TokenAuthenticator.Authenticate(ctx, token)
 	if err != nil {
 		log.Printf("TokenReview API call failed: %v", err)
-		return false
+		return nil, false
 	}
 
 	// Check if token is authenticated
-	if !result.Status.Authenticated {
-		log.Printf("Token not authenticated: %s", result.Status.Error)
-		return false
+	if !authenticated {
+		return nil, false
 	}
 
-	log.Printf("Token authenticated for user: %s", result.Status.User.Username)
-	return true
+	log.Printf("Token authenticated for user: %s", user.Username)
+	return user, true
 }
 
 // min returns the minimum of two integers
@@ -57,6 +50,27 @@ func min(a, b int) int {
 	return b
 }
 
+// streamTokenMiddleware lets EventSource clients, which cannot set headers,
+// send their bearer token as ?token= on the stream routes. The token is moved
+// to the Authorization header before the request is logged.
+func streamTokenMiddleware(c *gin.Context) {
+	if !strings.HasPrefix(c.Request.URL.Path, "/api/stream/") {
+		return
+	}
+
+	query := c.Request.URL.Query()
+	token := query.Get("token")
+	if token == "" {
+		return
+	}
+	query.Del("token")
+	c.Request.URL.RawQuery = query.Encode()
+
+	if c.GetHeader("Authorization") == "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 // SetupServer initializes the HTTP server with all required routes
 func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode bool) *gin.Engine {
 	// Check if debug mode is enabled
@@ -67,19 +81,23 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Set up Gin router
-	r := gin.Default()
+	// Set up Gin router, stream tokens are taken out of the URL before logging
+	r := gin.New()
+	r.Use(streamTokenMiddleware, gin.Logger(), gin.Recovery())
 
 	// Configure CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// Record the latency and status of every request
+	r.Use(metrics.Middleware())
+
 	// API routes
 	api := r.Group("/api")
 	{
@@ -111,14 +129,24 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 
 			token := tokenParts[1]
 
+			if ocmClient == nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Kubernetes client not initialized"})
+				c.Abort()
+				return
+			}
+
 			// Validate token using Kubernetes TokenReview API
-			if !validateToken(token, ocmClient, ctx) {
+			user, ok := validateToken(token, ocmClient, ctx)
+			if !ok {
 				log.Printf("Token validation failed for token: %s...", token[:min(len(token), 10)])
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 				c.Abort()
 				return
 			}
 
+			// Handlers only return what this user is allowed to read
+			auth.SetUser(c, user)
+
 			log.Println("Token validation successful")
 			c.Next()
 		}
@@ -132,6 +160,49 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetCluster(c, ocmClient, ctx)
 		})
 
+		// Cluster lifecycle actions run as the calling user
+		api.POST("/clusters/:name/accept", authMiddleware, func(c *gin.Context) {
+			handlers.AcceptCluster(c, ocmClient, ctx)
+		})
+		api.POST("/clusters/:name/deny", authMiddleware, func(c *gin.Context) {
+			handlers.DenyCluster(c, ocmClient, ctx)
+		})
+		api.PATCH("/clusters/:name/labels", authMiddleware, func(c *gin.Context) {
+			handlers.PatchClusterLabels(c, ocmClient, ctx)
+		})
+		api.POST("/clusters/:name/taints", authMiddleware, func(c *gin.Context) {
+			handlers.AddClusterTaint(c, ocmClient, ctx)
+		})
+		// Taint keys may have a prefix with a slash, the catch-all keeps it
+		api.DELETE("/clusters/:name/taints/*key", authMiddleware, func(c *gin.Context) {
+			handlers.RemoveClusterTaint(c, ocmClient, ctx)
+		})
+		api.DELETE("/clusters/:name", authMiddleware, func(c *gin.Context) {
+			handlers.DeleteCluster(c, ocmClient, ctx)
+		})
+
+		// Register cluster registration routes
+		api.GET("/registrations", authMiddleware, func(c *gin.Context) {
+			handlers.GetRegistrations(c, ocmClient, ctx)
+		})
+		api.GET("/registrations/:name", authMiddleware, func(c *gin.Context) {
+			handlers.GetRegistration(c, ocmClient, ctx)
+		})
+		api.POST("/registrations/:name/approve", authMiddleware, func(c *gin.Context) {
+			handlers.ApproveRegistration(c, ocmClient, ctx)
+		})
+		api.POST("/registrations/:name/deny", authMiddleware, func(c *gin.Context) {
+			handlers.DenyRegistration(c, ocmClient, ctx)
+		})
+
+		api.GET("/inventory", authMiddleware, func(c *gin.Context) {
+			handlers.GetInventory(c, ocmClient, ctx)
+		})
+
+		api.GET("/clusters/:name/relations", authMiddleware, func(c *gin.Context) {
+			handlers.GetClusterRelations(c, ocmClient, ctx)
+		})
+
 		// Register cluster addon routes
 		api.GET("/clusters/:name/addons", authMiddleware, func(c *gin.Context) {
 			handlers.GetClusterAddons(c, ocmClient, ctx)
@@ -141,6 +212,23 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetClusterAddon(c, ocmClient, ctx)
 		})
 
+		// Register addon routes
+		api.GET("/clustermanagementaddons", authMiddleware, func(c *gin.Context) {
+			handlers.GetClusterManagementAddOns(c, ocmClient, ctx)
+		})
+
+		api.GET("/clustermanagementaddons/:name", authMiddleware, func(c *gin.Context) {
+			handlers.GetClusterManagementAddOn(c, ocmClient, ctx)
+		})
+
+		api.GET("/clustermanagementaddons/:name/rollout", authMiddleware, func(c *gin.Context) {
+			handlers.GetClusterManagementAddOnRollout(c, ocmClient, ctx)
+		})
+
+		api.GET("/addons/health", authMiddleware, func(c *gin.Context) {
+			handlers.GetAddonHealthMatrix(c, ocmClient, ctx)
+		})
+
 		// Register clusterset routes
 		api.GET("/clustersets", authMiddleware, func(c *gin.Context) {
 			handlers.GetClusterSets(c, ocmClient, ctx)
@@ -150,6 +238,20 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetClusterSet(c, ocmClient, ctx)
 		})
 
+		// Cluster set changes run as the calling user
+		api.POST("/clustersets", authMiddleware, func(c *gin.Context) {
+			handlers.CreateClusterSet(c, ocmClient, ctx)
+		})
+		api.PUT("/clustersets/:name", authMiddleware, func(c *gin.Context) {
+			handlers.UpdateClusterSet(c, ocmClient, ctx)
+		})
+		api.DELETE("/clustersets/:name", authMiddleware, func(c *gin.Context) {
+			handlers.DeleteClusterSet(c, ocmClient, ctx)
+		})
+		api.POST("/clustersets/:name/clusters", authMiddleware, func(c *gin.Context) {
+			handlers.MoveClustersToClusterSet(c, ocmClient, ctx)
+		})
+
 		// Register clustersetbinding routes
 		api.GET("/clustersetbindings", authMiddleware, func(c *gin.Context) {
 			handlers.GetAllClusterSetBindings(c, ocmClient, ctx)
@@ -163,7 +265,21 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetClusterSetBinding(c, ocmClient, ctx)
 		})
 
+		api.POST("/namespaces/:namespace/clustersetbindings", authMiddleware, func(c *gin.Context) {
+			handlers.CreateClusterSetBinding(c, ocmClient, ctx)
+		})
+		api.PUT("/namespaces/:namespace/clustersetbindings/:name", authMiddleware, func(c *gin.Context) {
+			handlers.UpdateClusterSetBinding(c, ocmClient, ctx)
+		})
+		api.DELETE("/namespaces/:namespace/clustersetbindings/:name", authMiddleware, func(c *gin.Context) {
+			handlers.DeleteClusterSetBinding(c, ocmClient, ctx)
+		})
+
 		// Register manifestwork routes
+		api.GET("/manifestworks", authMiddleware, func(c *gin.Context) {
+			handlers.SearchManifestWorks(c, ocmClient, ctx)
+		})
+
 		api.GET("/namespaces/:namespace/manifestworks", authMiddleware, func(c *gin.Context) {
 			handlers.GetManifestWorks(c, ocmClient, ctx)
 		})
@@ -172,6 +288,30 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetManifestWork(c, ocmClient, ctx)
 		})
 
+		// ManifestWork changes run as the calling user
+		api.POST("/namespaces/:namespace/manifestworks", authMiddleware, func(c *gin.Context) {
+			handlers.CreateManifestWork(c, ocmClient, ctx)
+		})
+		api.PUT("/namespaces/:namespace/manifestworks/:name", authMiddleware, func(c *gin.Context) {
+			handlers.UpdateManifestWork(c, ocmClient, ctx)
+		})
+		api.DELETE("/namespaces/:namespace/manifestworks/:name", authMiddleware, func(c *gin.Context) {
+			handlers.DeleteManifestWork(c, ocmClient, ctx)
+		})
+
+		// Register manifestworkreplicaset routes
+		api.GET("/manifestworkreplicasets", authMiddleware, func(c *gin.Context) {
+			handlers.GetManifestWorkReplicaSets(c, ocmClient, ctx)
+		})
+
+		api.GET("/namespaces/:namespace/manifestworkreplicasets", authMiddleware, func(c *gin.Context) {
+			handlers.GetManifestWorkReplicaSetsByNamespace(c, ocmClient, ctx)
+		})
+
+		api.GET("/namespaces/:namespace/manifestworkreplicasets/:name", authMiddleware, func(c *gin.Context) {
+			handlers.GetManifestWorkReplicaSet(c, ocmClient, ctx)
+		})
+
 		// Register placement routes
 		api.GET("/placements", authMiddleware, func(c *gin.Context) {
 			handlers.GetPlacements(c, ocmClient, ctx)
@@ -189,6 +329,18 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			handlers.GetPlacementDecisions(c, ocmClient, ctx)
 		})
 
+		api.GET("/namespaces/:namespace/placements/:name/explain", authMiddleware, func(c *gin.Context) {
+			handlers.ExplainPlacement(c, ocmClient, ctx)
+		})
+
+		api.GET("/namespaces/:namespace/placements/:name/history", authMiddleware, func(c *gin.Context) {
+			handlers.GetPlacementHistory(c, ocmClient, ctx)
+		})
+
+		api.POST("/placements/simulate", authMiddleware, func(c *gin.Context) {
+			handlers.SimulatePlacement(c, ocmClient, ctx)
+		})
+
 		// Register placementdecision routes
 		api.GET("/placementdecisions", authMiddleware, func(c *gin.Context) {
 			handlers.GetAllPlacementDecisions(c, ocmClient, ctx)
@@ -208,7 +360,34 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 
 		// Register streaming routes
 		api.GET("/stream/clusters", authMiddleware, func(c *gin.Context) {
-			handlers.StreamClusters(c, ocmClient.Interface, ctx)
+			handlers.StreamClusters(c, ocmClient, ctx)
+		})
+		api.GET("/stream/clusters/:name/addons", authMiddleware, func(c *gin.Context) {
+			handlers.StreamClusterAddons(c, ocmClient, ctx)
+		})
+		api.GET("/stream/clustersets", authMiddleware, func(c *gin.Context) {
+			handlers.StreamClusterSets(c, ocmClient, ctx)
+		})
+		api.GET("/stream/clustersetbindings", authMiddleware, func(c *gin.Context) {
+			handlers.StreamClusterSetBindings(c, ocmClient, ctx)
+		})
+		api.GET("/stream/namespaces/:namespace/clustersetbindings", authMiddleware, func(c *gin.Context) {
+			handlers.StreamClusterSetBindings(c, ocmClient, ctx)
+		})
+		api.GET("/stream/placements", authMiddleware, func(c *gin.Context) {
+			handlers.StreamPlacements(c, ocmClient, ctx)
+		})
+		api.GET("/stream/namespaces/:namespace/placements", authMiddleware, func(c *gin.Context) {
+			handlers.StreamPlacements(c, ocmClient, ctx)
+		})
+		api.GET("/stream/placementdecisions", authMiddleware, func(c *gin.Context) {
+			handlers.StreamPlacementDecisions(c, ocmClient, ctx)
+		})
+		api.GET("/stream/namespaces/:namespace/placementdecisions", authMiddleware, func(c *gin.Context) {
+			handlers.StreamPlacementDecisions(c, ocmClient, ctx)
+		})
+		api.GET("/stream/namespaces/:namespace/manifestworks", authMiddleware, func(c *gin.Context) {
+			handlers.StreamManifestWorks(c, ocmClient, ctx)
 		})
 	}
 
@@ -222,12 +401,23 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 	})
 
 	// Alternative health check endpoint following Kubernetes conventions
+	// Reports not ready until the informer caches have synced
 	r.GET("/healthz", func(c *gin.Context) {
+		if ocmClient == nil || !ocmClient.InformersSynced() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "informer caches not synced",
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 		})
 	})
 
+	// Prometheus metrics (no authentication required, like the health checks)
+	r.GET("/metrics", metrics.Handler())
+
 	// API status endpoint
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -237,6 +427,7 @@ func SetupServer(ocmClient *client.OCMClient, ctx context.Context, debugMode boo
 			"endpoints": gin.H{
 				"health":  "/health",
 				"healthz": "/healthz",
+				"metrics": "/metrics",
 				"api":     "/api/*",
 			},
 		})
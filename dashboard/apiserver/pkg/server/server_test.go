@@ -5,12 +5,25 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"open-cluster-management-io/lab/apiserver/pkg/auth"
 	"open-cluster-management-io/lab/apiserver/pkg/client"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	fakeaddon "open-cluster-management.io/api/client/addon/clientset/versioned/fake"
+	addoninformers "open-cluster-management.io/api/client/addon/informers/externalversions"
+	fakecluster "open-cluster-management.io/api/client/cluster/clientset/versioned/fake"
+	clusterinformers "open-cluster-management.io/api/client/cluster/informers/externalversions"
+	fakework "open-cluster-management.io/api/client/work/clientset/versioned/fake"
+	workinformers "open-cluster-management.io/api/client/work/informers/externalversions"
 )
 
 func TestSetupServer(t *testing.T) {
@@ -153,6 +166,53 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 }
 
+func TestStreamTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                  string
+		url                   string
+		authHeader            string
+		expectedAuthorization string
+		expectedQuery         string
+	}{
+		{
+			name:                  "stream token",
+			url:                   "/api/stream/clusters?token=abc&labelSelector=env%3Dprod",
+			expectedAuthorization: "Bearer abc",
+			expectedQuery:         "labelSelector=env%3Dprod",
+		},
+		{
+			name:                  "header takes precedence",
+			url:                   "/api/stream/clusters?token=abc",
+			authHeader:            "Bearer header-token",
+			expectedAuthorization: "Bearer header-token",
+			expectedQuery:         "",
+		},
+		{
+			name:                  "only on stream routes",
+			url:                   "/api/clusters?token=abc",
+			expectedAuthorization: "",
+			expectedQuery:         "token=abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.authHeader != "" {
+				c.Request.Header.Set("Authorization", tt.authHeader)
+			}
+
+			streamTokenMiddleware(c)
+
+			assert.Equal(t, tt.expectedAuthorization, c.Request.Header.Get("Authorization"))
+			assert.Equal(t, tt.expectedQuery, c.Request.URL.RawQuery)
+		})
+	}
+}
+
 func TestCORSConfiguration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctx := context.Background()
@@ -166,6 +226,8 @@ func TestCORSConfiguration(t *testing.T) {
 
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
 	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "GET")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "DELETE")
 	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
 }
 
@@ -181,3 +243,131 @@ func TestRootRedirect(t *testing.T) {
 	assert.Equal(t, http.StatusMovedPermanently, w.Code)
 	assert.Equal(t, "/static/index.html", w.Header().Get("Location"))
 }
+
+func TestHealthzReportsInformerSync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Without a client the caches can never sync
+	router := SetupServer(nil, context.Background(), false)
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	clusterClient := fakecluster.NewSimpleClientset()
+	addonClient := fakeaddon.NewSimpleClientset()
+	workClient := fakework.NewSimpleClientset()
+	ocmClient := &client.OCMClient{
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+		AddonInformerFactory:   addoninformers.NewSharedInformerFactory(addonClient, 0),
+		WorkInformerFactory:    workinformers.NewSharedInformerFactory(workClient, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router = SetupServer(ocmClient, ctx, false)
+	ocmClient.StartInformers(ctx)
+
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clusterClient := fakecluster.NewSimpleClientset()
+	addonClient := fakeaddon.NewSimpleClientset()
+	workClient := fakework.NewSimpleClientset()
+	ocmClient := &client.OCMClient{
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+		AddonInformerFactory:   addoninformers.NewSharedInformerFactory(addonClient, 0),
+		WorkInformerFactory:    workinformers.NewSharedInformerFactory(workClient, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := SetupServer(ocmClient, ctx, false)
+	ocmClient.StartInformers(ctx)
+
+	// Metrics need no token, the informer and fleet gauges come from the caches
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		body := w.Body.String()
+		return w.Code == http.StatusOK &&
+			strings.Contains(body, `ocm_dashboard_informer_synced{resource="managedclusters"} 1`) &&
+			strings.Contains(body, `ocm_dashboard_fleet_clusters{available="True"} 0`) &&
+			strings.Contains(body, `ocm_dashboard_http_requests_total{code="200",method="GET",route="/metrics"}`)
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestAuthMiddlewareCachesTokenReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("DASHBOARD_BYPASS_AUTH", "false")
+	defer os.Unsetenv("DASHBOARD_BYPASS_AUTH")
+
+	reviews := 0
+	kubeClient := fakekube.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.TokenReview)
+		if review.Spec.Token == "valid-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authv1.UserInfo{Username: "alice"}
+		}
+		return true, review, nil
+	})
+
+	clusterClient := fakecluster.NewSimpleClientset()
+	ocmClient := &client.OCMClient{
+		KubernetesClient:       kubeClient,
+		ClusterInformerFactory: clusterinformers.NewSharedInformerFactory(clusterClient, 0),
+		TokenAuthenticator: auth.NewTokenAuthenticator(kubeClient, auth.TokenCacheOptions{
+			TTL:         time.Minute,
+			NegativeTTL: time.Minute,
+			MaxEntries:  10,
+		}),
+	}
+	router := SetupServer(ocmClient, context.Background(), false)
+
+	tests := []struct {
+		name            string
+		token           string
+		expectedStatus  int
+		expectedReviews int
+	}{
+		{
+			name:            "valid token",
+			token:           "valid-token",
+			expectedStatus:  http.StatusOK,
+			expectedReviews: 1,
+		},
+		{
+			name:            "rejected token",
+			token:           "invalid-token",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedReviews: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repeated requests with the same token only send one TokenReview
+			for i := 0; i < 3; i++ {
+				req, _ := http.NewRequest("GET", "/api/clusters", nil)
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+			assert.Equal(t, tt.expectedReviews, reviews)
+		})
+	}
+}
@@ -12,10 +12,15 @@ import { MuiThemeProvider } from './theme/ThemeProvider';
 
 // Protected route component that redirects to login if not authenticated
 const ProtectedRoute = ({ children }: { children: React.ReactNode }) => {
-  const { isAuthenticated } = useAuth();
+  const { isAuthenticated, isLoading } = useAuth();
 
   console.log('ProtectedRoute: isAuthenticated =', isAuthenticated);
 
+  // Wait until we know whether there is an SSO session
+  if (isLoading) {
+    return null;
+  }
+
   if (!isAuthenticated) {
     console.log('Redirecting to login...');
     return <Navigate to="/login" />;
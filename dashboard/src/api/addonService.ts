@@ -24,6 +24,48 @@ export interface ManagedClusterAddon {
     group: string;
     resource: string;
   }[];
+  configReferences?: AddonConfigReference[];
+  configRollout?: {
+    inSync: boolean;
+    stuck: boolean;
+    reason?: string;
+    message?: string;
+    since?: string;
+  };
+}
+
+export interface AddonConfigReference {
+  group: string;
+  resource: string;
+  namespace?: string;
+  name: string;
+  desiredSpecHash?: string;
+  lastAppliedSpecHash?: string;
+  inSync: boolean;
+  deploymentConfig?: AddOnDeploymentConfig;
+}
+
+export interface AddOnDeploymentConfig {
+  namespace: string;
+  name: string;
+  customizedVariables?: { name: string; value: string }[];
+  nodePlacement?: {
+    nodeSelector?: Record<string, string>;
+    tolerations?: {
+      key?: string;
+      operator?: string;
+      value?: string;
+      effect?: string;
+      tolerationSeconds?: number;
+    }[];
+  };
+  registries?: { mirror: string; source: string }[];
+  proxyConfig?: {
+    httpProxy?: string;
+    httpsProxy?: string;
+    noProxy?: string;
+  };
+  agentInstallNamespace?: string;
 }
 
 // Backend API base URL - configurable for production
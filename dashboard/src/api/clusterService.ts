@@ -351,8 +351,11 @@ export const fetchClusterByName = async (name: string): Promise<Cluster | null>
   }
 };
 
-// SSE for real-time cluster updates
+// SSE for real-time cluster updates. The stream starts with a snapshot of all
+// clusters and then sends only the cluster that changed. On reconnect the
+// browser sends Last-Event-ID so the server can resume without a new snapshot.
 export const setupClusterEventSource = (
+  onSnapshot: (clusters: Cluster[]) => void,
   onAdd: (cluster: Cluster) => void,
   onUpdate: (cluster: Cluster) => void,
   onDelete: (clusterId: string) => void,
@@ -363,26 +366,31 @@ export const setupClusterEventSource = (
     return () => {}; // Return no-op cleanup function
   }
 
-  // Create EventSource for SSE
+  // EventSource cannot send headers, so a pasted token goes in ?token=. With
+  // OIDC login there is no token and the uiserver adds it from the session.
   const token = localStorage.getItem('authToken');
-  // Extract the token part without 'Bearer ' prefix for URL parameter
   const tokenParam = token ? token.replace('Bearer ', '') : '';
   const eventSource = new EventSource(
-    `${API_BASE}/api/stream/clusters${tokenParam ? `?token=${tokenParam}` : ''}`
+    `${API_BASE}/api/stream/clusters${tokenParam ? `?token=${encodeURIComponent(tokenParam)}` : ''}`
   );
 
   // Set up event listeners
-  eventSource.addEventListener('ADDED', (event) => {
+  eventSource.addEventListener('snapshot', (event) => {
+    const clusters = JSON.parse(event.data);
+    onSnapshot(clusters);
+  });
+
+  eventSource.addEventListener('added', (event) => {
     const cluster = JSON.parse(event.data);
     onAdd(cluster);
   });
 
-  eventSource.addEventListener('MODIFIED', (event) => {
+  eventSource.addEventListener('modified', (event) => {
     const cluster = JSON.parse(event.data);
     onUpdate(cluster);
   });
 
-  eventSource.addEventListener('DELETED', (event) => {
+  eventSource.addEventListener('deleted', (event) => {
     const clusterId = JSON.parse(event.data).id;
     onDelete(clusterId);
   });
@@ -393,4 +401,4 @@ export const setupClusterEventSource = (
   return () => {
     eventSource.close();
   };
-};
\ No newline at end of file
+};
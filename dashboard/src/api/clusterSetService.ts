@@ -10,6 +10,11 @@ export interface ClusterSet {
       selectorType: string;
       labelSelector?: {
         matchLabels?: Record<string, string>;
+        matchExpressions?: {
+          key: string;
+          operator: string;
+          values?: string[];
+        }[];
       };
     };
   };
@@ -22,6 +27,14 @@ export interface ClusterSet {
       lastTransitionTime?: string;
     }[];
   };
+  // Membership resolved by the API server
+  membership?: {
+    clusterNames: string[];
+    clusterCount: number;
+    onlineCount: number;
+    offlineCount: number;
+    boundNamespaces: string[];
+  };
 }
 
 // Make sure we also export a type to avoid compiler issues
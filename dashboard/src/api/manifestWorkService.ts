@@ -7,8 +7,56 @@ export interface ManifestWork {
   labels?: Record<string, string>;
   creationTimestamp?: string;
   manifests?: Manifest[];
+  deleteOption?: DeleteOption;
+  manifestConfigs?: ManifestConfigOption[];
+  executor?: ManifestWorkExecutor;
   conditions?: Condition[];
   resourceStatus?: ManifestResourceStatus;
+  statusSummary?: ManifestStatusSummary;
+}
+
+export interface ManifestStatusSummary {
+  total: number;
+  applied: number;
+  available: number;
+  degraded: number;
+}
+
+export interface DeleteOption {
+  propagationPolicy: string;
+  selectivelyOrphans?: {
+    orphaningRules?: ResourceIdentifier[];
+  };
+}
+
+export interface ResourceIdentifier {
+  group?: string;
+  resource: string;
+  name: string;
+  namespace?: string;
+}
+
+export interface ManifestConfigOption {
+  resourceIdentifier: ResourceIdentifier;
+  feedbackRules?: {
+    type: string;
+    jsonPaths?: { name: string; version?: string; path: string }[];
+  }[];
+  updateStrategy?: {
+    type?: string;
+    serverSideApply?: {
+      force: boolean;
+      fieldManager?: string;
+      ignoreFields?: { condition: string; jsonPaths: string[] }[];
+    };
+  };
+}
+
+export interface ManifestWorkExecutor {
+  subject: {
+    type: string;
+    serviceAccount?: { namespace: string; name: string };
+  };
 }
 
 export interface Manifest {
@@ -29,9 +77,21 @@ export interface ManifestResourceStatus {
 
 export interface ManifestCondition {
   resourceMeta: ManifestResourceMeta;
+  statusFeedbacks?: FeedbackValue[];
   conditions: Condition[];
 }
 
+export interface FeedbackValue {
+  name: string;
+  fieldValue: {
+    type: string;
+    integer?: number;
+    string?: string;
+    boolean?: boolean;
+    jsonRaw?: string;
+  };
+}
+
 export interface ManifestResourceMeta {
   ordinal: number;
   group?: string;
@@ -55,6 +55,14 @@ export interface Placement {
       };
     };
   }[];
+  spreadPolicy?: {
+    spreadConstraints?: {
+      topologyKey: string;
+      topologyKeyType: string;
+      maxSkew?: number;
+      whenUnsatisfiable?: string;
+    }[];
+  };
   tolerations?: {
     key?: string;
     operator?: string;
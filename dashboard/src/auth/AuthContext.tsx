@@ -5,6 +5,8 @@ import { createContext, useState, useContext, type ReactNode, useEffect } from '
 interface AuthContextType {
   token: string | null;
   isAuthenticated: boolean;
+  // Whether the uiserver offers OIDC login
+  ssoEnabled: boolean;
   login: (token: string) => void;
   logout: () => void;
   isLoading: boolean;
@@ -18,10 +20,26 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
     return localStorage.getItem('authToken');
   });
 
-  const [isLoading, setIsLoading] = useState(false);
+  const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [ssoEnabled, setSsoEnabled] = useState(false);
+  const [hasSession, setHasSession] = useState(false);
 
-  const isAuthenticated = !!token;
+  // With an OIDC session the uiserver adds the token to API requests itself
+  const isAuthenticated = !!token || hasSession;
+
+  useEffect(() => {
+    fetch('/auth/session')
+      .then((response) => (response.ok ? response.json() : null))
+      .then((session) => {
+        setSsoEnabled(!!session?.enabled);
+        setHasSession(!!session?.authenticated);
+      })
+      .catch(() => {
+        // Older uiservers and the vite dev server do not serve /auth/session
+      })
+      .finally(() => setIsLoading(false));
+  }, []);
 
   useEffect(() => {
     if (token) {
@@ -49,11 +67,21 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
     setToken(null);
     setError(null);
     setIsLoading(false);
+    if (hasSession) {
+      setHasSession(false);
+      // Logout only accepts POST, so submit a form and follow the redirect
+      const form = document.createElement('form');
+      form.method = 'POST';
+      form.action = '/auth/logout';
+      document.body.appendChild(form);
+      form.submit();
+    }
   };
 
   const value = {
     token,
     isAuthenticated,
+    ssoEnabled,
     login,
     logout,
     isLoading,
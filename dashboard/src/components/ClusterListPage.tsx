@@ -31,7 +31,7 @@ import {
   Error as ErrorIcon,
   Launch as LaunchIcon,
 } from "@mui/icons-material";
-import { fetchClusters } from '../api/clusterService';
+import { fetchClusters, setupClusterEventSource } from '../api/clusterService';
 import type { Cluster } from '../api/clusterService';
 import { useCluster } from '../hooks/useCluster';
 import ClusterDetailContent from './ClusterDetailContent';
@@ -85,6 +85,32 @@ export default function ClusterListPage() {
     loadClusters();
   }, []);
 
+  // Keep the list up to date from the cluster stream. Addon counts are
+  // fetched separately, so they are carried over to the streamed clusters.
+  useEffect(() => {
+    const withAddons = (cluster: Cluster, previous?: Cluster): Cluster =>
+      previous ? { ...cluster, addonCount: previous.addonCount, addonNames: previous.addonNames } : cluster;
+
+    return setupClusterEventSource(
+      (snapshot) => {
+        setClusters(prev => snapshot.map(cluster => withAddons(cluster, prev.find(c => c.id === cluster.id))));
+      },
+      (cluster) => {
+        setClusters(prev => prev.some(c => c.id === cluster.id) ? prev : [...prev, cluster]);
+      },
+      (cluster) => {
+        setClusters(prev => prev.map(c => c.id === cluster.id ? withAddons(cluster, c) : c));
+      },
+      (clusterId) => {
+        setClusters(prev => prev.filter(c => c.id !== clusterId));
+      },
+      (error) => {
+        // The browser reconnects on its own and resumes with Last-Event-ID
+        console.error('Cluster stream error:', error);
+      }
+    );
+  }, []);
+
   // Fetch addon counts/names for all clusters after clusters are loaded
   useEffect(() => {
     if (!clusters.length) return;
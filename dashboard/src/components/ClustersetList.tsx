@@ -130,6 +130,12 @@ const ClustersetList = () => {
     const counts: Record<string, number> = {};
 
     clusterSets.forEach(clusterSet => {
+      // Prefer the membership resolved by the API server
+      if (clusterSet.membership) {
+        counts[clusterSet.id] = clusterSet.membership.clusterCount;
+        return;
+      }
+
       // Get the selector type from the cluster set
       const selectorType = clusterSet.spec?.clusterSelector?.selectorType || 'ExclusiveClusterSetLabel';
       let count = 0;
@@ -24,7 +24,7 @@ const Login = () => {
   const [token, setToken] = useState('');
   const [error, setError] = useState<string | null>(null);
   const [testing, setTesting] = useState(false);
-  const { login, isLoading, error: authError } = useAuth();
+  const { login, isLoading, ssoEnabled, error: authError } = useAuth();
   const navigate = useNavigate();
   const theme = useTheme();
 
@@ -164,6 +164,22 @@ kubectl create token dashboard-user --duration=24h`}
             </Box>
           )}
 
+          {ssoEnabled && (
+            <CardContent sx={{ pt: 2, pb: 0 }}>
+              <Button
+                variant="contained"
+                fullWidth
+                href="/auth/login"
+                sx={{ textTransform: "none" }}
+              >
+                Sign in with SSO
+              </Button>
+              <Typography variant="caption" color="text.secondary" component="p" sx={{ textAlign: "center", mt: 2 }}>
+                or paste a token below
+              </Typography>
+            </CardContent>
+          )}
+
           <form onSubmit={handleSubmit}>
             <CardContent sx={{ pt: 2 }}>
               <TextField
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// sessionCookieName holds the encrypted ID token of a logged in user
+	sessionCookieName = "ocm_dashboard_session"
+
+	// loginCookieName holds the encrypted state, nonce and PKCE verifier while
+	// the user is at the identity provider
+	loginCookieName = "ocm_dashboard_login"
+
+	// loginCookieTTL bounds how long a user may take to log in at the provider
+	loginCookieTTL = 10 * time.Minute
+
+	// refreshableSessionTTL is how long a session with a refresh token is kept
+	// without being used. The provider may end it earlier by refusing a refresh.
+	refreshableSessionTTL = 12 * time.Hour
+
+	// refreshLeeway refreshes ID tokens shortly before they expire, so they do
+	// not expire while a proxied request is in flight
+	refreshLeeway = time.Minute
+
+	// cookieChunkSize keeps each cookie below the 4096 bytes browsers accept,
+	// including the name and attributes. Larger values are split over the
+	// cookies <name>, <name>.1, <name>.2 and so on.
+	cookieChunkSize = 3800
+
+	// maxCookieChunks bounds the number of cookies a value is split over
+	maxCookieChunks = 5
+)
+
+// oidcConfig configures the OIDC relying party
+type oidcConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// SessionKey is the secret used to encrypt the session cookies
+	SessionKey string
+	// SecureCookies marks the session cookies as HTTPS only
+	SecureCookies bool
+}
+
+// oidcConfigFromEnv reads the OIDC configuration. OIDC login is disabled and
+// nil is returned when OIDC_ISSUER_URL is not set.
+func oidcConfigFromEnv() (*oidcConfig, error) {
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	cfg := &oidcConfig{
+		IssuerURL:     issuer,
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        []string{oidc.ScopeOpenID, "profile", "email"},
+		SessionKey:    os.Getenv("OIDC_SESSION_KEY"),
+		SecureCookies: os.Getenv("OIDC_INSECURE_COOKIES") != "true",
+	}
+
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		cfg.Scopes = strings.FieldsFunc(scopes, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	if cfg.ClientID == "" {
+		return nil, errors.New("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+	}
+	if cfg.RedirectURL == "" {
+		return nil, errors.New("OIDC_REDIRECT_URL is required when OIDC_ISSUER_URL is set")
+	}
+	if len(cfg.SessionKey) < 32 {
+		return nil, errors.New("OIDC_SESSION_KEY must be at least 32 characters when OIDC_ISSUER_URL is set")
+	}
+
+	return cfg, nil
+}
+
+// loginState is kept in the login cookie between /auth/login and /auth/callback
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect"`
+}
+
+// session is kept in the session cookie once the user has logged in
+type session struct {
+	IDToken string `json:"idToken"`
+	// RefreshToken is only set when the provider issues one, usually when the
+	// offline_access scope is requested
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Subject      string    `json:"sub"`
+	Email        string    `json:"email,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// oidcAuth is an OIDC relying party that keeps the ID token in an encrypted
+// HttpOnly cookie and injects it into proxied API requests
+type oidcAuth struct {
+	cfg          *oidcConfig
+	oauth2Config oauth2.Config
+	verifier     *oidc.IDTokenVerifier
+	endSession   string
+	aead         cipher.AEAD
+	now          func() time.Time
+
+	// refreshed remembers the sessions refreshed per refresh token, so
+	// concurrent requests of one browser use a refresh token only once
+	mu        sync.Mutex
+	refreshed map[string]*session
+}
+
+// newOIDCAuth discovers the provider and prepares the relying party
+func newOIDCAuth(ctx context.Context, cfg *oidcConfig) (*oidcAuth, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	// The end_session_endpoint is optional, logout falls back to the app root
+	var claims struct {
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := provider.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to read OIDC provider metadata: %w", err)
+	}
+
+	// Derive a fixed size AES-256 key from the configured secret
+	key := sha256.Sum256([]byte(cfg.SessionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcAuth{
+		cfg: cfg,
+		oauth2Config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       cfg.Scopes,
+		},
+		verifier:   provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		endSession: claims.EndSessionEndpoint,
+		aead:       aead,
+		now:        time.Now,
+		refreshed:  map[string]*session{},
+	}, nil
+}
+
+// registerRoutes adds the /auth/* routes to the router
+func (a *oidcAuth) registerRoutes(r *gin.Engine) {
+	r.GET("/auth/login", a.login)
+	r.GET("/auth/callback", a.callback)
+	r.POST("/auth/logout", a.logout)
+	r.GET("/auth/session", a.sessionInfo)
+}
+
+// login redirects the user to the identity provider
+func (a *oidcAuth) login(c *gin.Context) {
+	state := loginState{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+		Redirect: safeRedirect(c.Query("redirect")),
+	}
+
+	if err := a.setCookie(c, loginCookieName, state, a.now().Add(loginCookieTTL)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	authURL := a.oauth2Config.AuthCodeURL(state.State,
+		oidc.Nonce(state.Nonce),
+		oauth2.S256ChallengeOption(state.Verifier),
+	)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// callback completes the authorization code flow and starts the session
+func (a *oidcAuth) callback(c *gin.Context) {
+	var state loginState
+	if err := a.readCookie(c.Request, loginCookieName, &state); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "login session missing or expired"})
+		return
+	}
+	a.clearCookie(c, loginCookieName)
+
+	if errParam := c.Query("error"); errParam != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("%s: %s", errParam, c.Query("error_description"))})
+		return
+	}
+
+	if c.Query("state") != state.State {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state mismatch"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := a.oauth2Config.Exchange(ctx, c.Query("code"), oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "failed to exchange authorization code: " + err.Error()})
+		return
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token response did not include an id_token"})
+		return
+	}
+
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid id_token: " + err.Error()})
+		return
+	}
+	if idToken.Nonce != state.Nonce {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "nonce mismatch"})
+		return
+	}
+
+	if err := a.setSession(c, newSession(rawIDToken, idToken, token.RefreshToken)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusFound, state.Redirect)
+}
+
+// newSession builds the session of a verified ID token
+func newSession(rawIDToken string, idToken *oidc.IDToken, refreshToken string) *session {
+	var claims struct {
+		Email string `json:"email"`
+	}
+	_ = idToken.Claims(&claims)
+
+	return &session{
+		IDToken:      rawIDToken,
+		RefreshToken: refreshToken,
+		Subject:      idToken.Subject,
+		Email:        claims.Email,
+		Expiry:       idToken.Expiry,
+	}
+}
+
+// logout ends the session and, when supported, the provider session as well.
+// It only accepts POST, so other sites cannot log the user out with a link.
+func (a *oidcAuth) logout(c *gin.Context) {
+	var sess session
+	hasSession := a.readCookie(c.Request, sessionCookieName, &sess) == nil
+	a.clearCookie(c, sessionCookieName)
+
+	// See Other makes the browser follow the redirect with a GET
+	if a.endSession == "" {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+
+	logoutURL, err := url.Parse(a.endSession)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+	query := logoutURL.Query()
+	query.Set("client_id", a.cfg.ClientID)
+	if hasSession {
+		query.Set("id_token_hint", sess.IDToken)
+	}
+	if redirectURL, err := url.Parse(a.cfg.RedirectURL); err == nil {
+		query.Set("post_logout_redirect_uri", redirectURL.Scheme+"://"+redirectURL.Host+"/")
+	}
+	logoutURL.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusSeeOther, logoutURL.String())
+}
+
+// sessionInfo tells the frontend whether OIDC login is available and who is logged in
+func (a *oidcAuth) sessionInfo(c *gin.Context) {
+	sess, ok := a.currentSession(c)
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"enabled": true, "authenticated": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":       true,
+		"authenticated": true,
+		"subject":       sess.Subject,
+		"email":         sess.Email,
+		"expiry":        sess.Expiry.UTC().Format(time.RFC3339),
+	})
+}
+
+// authorizeAPI adds the session's ID token as a bearer token to a proxied
+// API request. Requests that already carry an Authorization header are left
+// alone, and the session cookies are never forwarded.
+func (a *oidcAuth) authorizeAPI(c *gin.Context) {
+	sess, ok := a.currentSession(c)
+	stripCookies(c.Request, sessionCookieName, loginCookieName)
+
+	if !ok || c.Request.Header.Get("Authorization") != "" {
+		return
+	}
+	c.Request.Header.Set("Authorization", "Bearer "+sess.IDToken)
+}
+
+// currentSession returns the unexpired session of a request. An ID token that
+// is about to expire is refreshed when the session has a refresh token, and
+// the refreshed session is stored in the response cookies.
+func (a *oidcAuth) currentSession(c *gin.Context) (*session, bool) {
+	var sess session
+	if err := a.readCookie(c.Request, sessionCookieName, &sess); err != nil {
+		return nil, false
+	}
+
+	now := a.now()
+	if sess.RefreshToken == "" || now.Add(refreshLeeway).Before(sess.Expiry) {
+		return &sess, now.Before(sess.Expiry)
+	}
+
+	refreshed, err := a.refresh(c.Request.Context(), &sess)
+	if err != nil {
+		// The provider ended the session, keep the ID token while it lasts
+		if now.Before(sess.Expiry) {
+			return &sess, true
+		}
+		a.clearCookie(c, sessionCookieName)
+		return nil, false
+	}
+	if err := a.setSession(c, refreshed); err != nil {
+		return nil, false
+	}
+	return refreshed, true
+}
+
+// refresh exchanges the session's refresh token for a new ID token. A refresh
+// token is only used once, concurrent callers get the same refreshed session.
+func (a *oidcAuth) refresh(ctx context.Context, sess *session) (*session, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for refreshToken, refreshed := range a.refreshed {
+		if !now.Before(refreshed.Expiry) {
+			delete(a.refreshed, refreshToken)
+		}
+	}
+	if refreshed, ok := a.refreshed[sess.RefreshToken]; ok {
+		return refreshed, nil
+	}
+
+	// The provider may not return a new refresh token, the current one is kept then
+	token, err := a.oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: sess.RefreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh the session: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("refresh response did not include an id_token")
+	}
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refreshed id_token: %w", err)
+	}
+	if idToken.Subject != sess.Subject {
+		return nil, errors.New("refreshed id_token is for another subject")
+	}
+
+	refreshed := newSession(rawIDToken, idToken, token.RefreshToken)
+	a.refreshed[sess.RefreshToken] = refreshed
+	return refreshed, nil
+}
+
+// setSession stores the session in the session cookie. Sessions with a
+// refresh token outlive their ID token.
+func (a *oidcAuth) setSession(c *gin.Context, sess *session) error {
+	expiry := sess.Expiry
+	if sess.RefreshToken != "" {
+		expiry = a.now().Add(refreshableSessionTTL)
+	}
+	return a.setCookie(c, sessionCookieName, sess, expiry)
+}
+
+// setCookie encrypts value into HttpOnly cookies that expire at expiry
+func (a *oidcAuth) setCookie(c *gin.Context, name string, value interface{}, expiry time.Time) error {
+	encrypted, err := a.encrypt(name, value)
+	if err != nil {
+		return err
+	}
+
+	var chunks []string
+	for len(encrypted) > cookieChunkSize {
+		chunks = append(chunks, encrypted[:cookieChunkSize])
+		encrypted = encrypted[cookieChunkSize:]
+	}
+	chunks = append(chunks, encrypted)
+	if len(chunks) > maxCookieChunks {
+		return fmt.Errorf("%s cookie is too large", name)
+	}
+
+	for i, chunk := range chunks {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     chunkName(name, i),
+			Value:    chunk,
+			Path:     "/",
+			Expires:  expiry,
+			MaxAge:   int(expiry.Sub(a.now()).Seconds()),
+			HttpOnly: true,
+			Secure:   a.cfg.SecureCookies,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	// Drop the chunks of a previous, larger value
+	for i := len(chunks); i < maxCookieChunks; i++ {
+		if _, err := c.Request.Cookie(chunkName(name, i)); err == nil {
+			a.expireCookie(c, chunkName(name, i))
+		}
+	}
+	return nil
+}
+
+// readCookie decrypts the cookies set by setCookie into value
+func (a *oidcAuth) readCookie(req *http.Request, name string, value interface{}) error {
+	var encrypted strings.Builder
+	for i := 0; i < maxCookieChunks; i++ {
+		cookie, err := req.Cookie(chunkName(name, i))
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			break
+		}
+		encrypted.WriteString(cookie.Value)
+	}
+	return a.decrypt(name, encrypted.String(), value)
+}
+
+// clearCookie removes the cookies set by setCookie from the browser
+func (a *oidcAuth) clearCookie(c *gin.Context, name string) {
+	a.expireCookie(c, name)
+	for i := 1; i < maxCookieChunks; i++ {
+		if _, err := c.Request.Cookie(chunkName(name, i)); err == nil {
+			a.expireCookie(c, chunkName(name, i))
+		}
+	}
+}
+
+// expireCookie removes a single cookie from the browser
+func (a *oidcAuth) expireCookie(c *gin.Context, name string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   a.cfg.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// chunkName is the name of the cookie holding chunk i of a value
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return name + "." + strconv.Itoa(i)
+}
+
+// encrypt seals the JSON encoding of value with AES-GCM. The cookie name is
+// authenticated too, so a value cannot be replayed in another cookie.
+func (a *oidcAuth) encrypt(name string, value interface{}) (string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := a.aead.Seal(nonce, nonce, plaintext, []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a value sealed by encrypt for the same cookie name
+func (a *oidcAuth) decrypt(name, encoded string, value interface{}) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	nonceSize := a.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return errors.New("encrypted cookie too short")
+	}
+
+	plaintext, err := a.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, value)
+}
+
+// stripCookies removes the named cookies and their chunks from a request
+func stripCookies(req *http.Request, names ...string) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		keep := true
+		for _, name := range names {
+			if cookie.Name == name || strings.HasPrefix(cookie.Name, name+".") {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			req.AddCookie(cookie)
+		}
+	}
+}
+
+// safeRedirect only allows redirects to local paths after login
+func safeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
+
+// randomString returns a random URL safe string for state and nonce values
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockProvider is a minimal OIDC provider that logs in every user as alice
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	nonce         string
+	codeChallenge string
+	lastIDToken   string
+
+	// refreshToken is issued with every token response when set, and
+	// rotated on every refresh
+	refreshToken string
+	refreshes    int
+	// expiresIn is the lifetime of the issued ID tokens
+	expiresIn time.Duration
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockProvider{t: t, key: key, expiresIn: time.Hour}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/keys",
+			"end_session_endpoint":                  p.server.URL + "/logout",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &p.key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		p.nonce = query.Get("nonce")
+		p.codeChallenge = query.Get("code_challenge")
+
+		redirect, _ := url.Parse(query.Get("redirect_uri"))
+		values := redirect.Query()
+		values.Set("code", "test-code")
+		values.Set("state", query.Get("state"))
+		redirect.RawQuery = values.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		nonce := p.nonce
+		switch {
+		case r.Form.Get("grant_type") == "refresh_token" && p.refreshToken != "" && r.Form.Get("refresh_token") == p.refreshToken:
+			// Refreshed ID tokens carry no nonce
+			nonce = ""
+			p.refreshes++
+			p.refreshToken = fmt.Sprintf("refresh-token-%d", p.refreshes)
+		case r.Form.Get("code") != "test-code" || r.Form.Get("code_verifier") == "":
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		p.lastIDToken = p.signIDToken(nonce)
+		response := map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     p.lastIDToken,
+		}
+		if p.refreshToken != "" {
+			response["refresh_token"] = p.refreshToken
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// signIDToken issues an ID token for alice with the given nonce
+func (p *mockProvider) signIDToken(nonce string) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key}, (&jose.SignerOptions{}).WithHeader("kid", "test"))
+	require.NoError(p.t, err)
+
+	now := time.Now()
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{
+		Issuer:   p.server.URL,
+		Subject:  "alice",
+		Audience: jwt.Audience{"ocm-dashboard"},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(p.expiresIn)),
+	}).Claims(map[string]interface{}{
+		"nonce": nonce,
+		"email": "alice@example.com",
+	}).Serialize()
+	require.NoError(p.t, err)
+	return token
+}
+
+// newTestUIServer starts a uiserver with OIDC login in front of a fake API
+// server that echoes the Authorization header and cookies it receives
+func newTestUIServer(t *testing.T, provider *mockProvider) (*httptest.Server, *oidcAuth) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"authorization": r.Header.Get("Authorization"),
+			"cookie":        r.Header.Get("Cookie"),
+		})
+	}))
+	t.Cleanup(api.Close)
+
+	apiURL, _ := url.Parse(api.URL)
+	proxy := newAPIProxy(apiURL)
+
+	r := gin.New()
+	ui := httptest.NewServer(r)
+	t.Cleanup(ui.Close)
+
+	auth, err := newOIDCAuth(context.Background(), &oidcConfig{
+		IssuerURL:   provider.server.URL,
+		ClientID:    "ocm-dashboard",
+		RedirectURL: ui.URL + "/auth/callback",
+		Scopes:      []string{"openid", "email"},
+		SessionKey:  "0123456789abcdef0123456789abcdef",
+	})
+	require.NoError(t, err)
+
+	auth.registerRoutes(r)
+	r.Any("/api/*path", auth.authorizeAPI, func(c *gin.Context) {
+		proxy.ServeHTTP(c.Writer, c.Request)
+	})
+	r.GET("/clusters", func(c *gin.Context) {
+		c.String(http.StatusOK, "app")
+	})
+
+	return ui, auth
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	provider := newMockProvider(t)
+	ui, _ := newTestUIServer(t, provider)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	httpClient := &http.Client{Jar: jar}
+
+	// Before logging in nothing is injected
+	resp, err := httpClient.Get(ui.URL + "/api/clusters")
+	require.NoError(t, err)
+	var echoed map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&echoed))
+	resp.Body.Close()
+	assert.Empty(t, echoed["authorization"])
+
+	// Login goes through the provider and lands back on the requested page
+	resp, err = httpClient.Get(ui.URL + "/auth/login?redirect=/clusters")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/clusters", resp.Request.URL.Path)
+	assert.NotEmpty(t, provider.nonce)
+	assert.NotEmpty(t, provider.codeChallenge)
+
+	// The session cookie is HttpOnly and does not contain the raw ID token
+	uiURL, _ := url.Parse(ui.URL)
+	var sessionCookie *http.Cookie
+	for _, cookie := range jar.Cookies(uiURL) {
+		if cookie.Name == sessionCookieName {
+			sessionCookie = cookie
+		}
+	}
+	require.NotNil(t, sessionCookie)
+	assert.NotContains(t, sessionCookie.Value, provider.lastIDToken)
+
+	// API requests now carry the ID token, and the session cookie stays in the uiserver
+	resp, err = httpClient.Get(ui.URL + "/api/clusters")
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&echoed))
+	resp.Body.Close()
+	assert.Equal(t, "Bearer "+provider.lastIDToken, echoed["authorization"])
+	assert.NotContains(t, echoed["cookie"], sessionCookieName)
+
+	resp, err = httpClient.Get(ui.URL + "/auth/session")
+	require.NoError(t, err)
+	var info map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	resp.Body.Close()
+	assert.Equal(t, true, info["authenticated"])
+	assert.Equal(t, "alice@example.com", info["email"])
+
+	// Logout is POST only, it clears the session and hands off to the provider
+	noFollow := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err = noFollow.Get(ui.URL + "/auth/logout")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = noFollow.Post(ui.URL+"/auth/logout", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", location.Path)
+	assert.Equal(t, provider.lastIDToken, location.Query().Get("id_token_hint"))
+
+	resp, err = httpClient.Get(ui.URL + "/api/clusters")
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&echoed))
+	resp.Body.Close()
+	assert.Empty(t, echoed["authorization"])
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	provider := newMockProvider(t)
+	ui, _ := newTestUIServer(t, provider)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	noFollow := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	// Start a login to get the login cookie, then return with a forged state
+	resp, err := noFollow.Get(ui.URL + "/auth/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	resp, err = noFollow.Get(ui.URL + "/auth/callback?code=test-code&state=forged")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Without a login cookie the callback is rejected too
+	resp, err = http.Get(ui.URL + "/auth/callback?code=test-code&state=forged")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestOIDCSessionRefresh(t *testing.T) {
+	provider := newMockProvider(t)
+	provider.refreshToken = "refresh-token-0"
+	// Every ID token is within the refresh leeway, so each request refreshes it
+	provider.expiresIn = refreshLeeway / 2
+	ui, _ := newTestUIServer(t, provider)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	httpClient := &http.Client{Jar: jar}
+
+	resp, err := httpClient.Get(ui.URL + "/auth/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+	loginIDToken := provider.lastIDToken
+
+	// The refreshed ID token is sent upstream and the rotated refresh token is kept
+	for i := 1; i <= 2; i++ {
+		resp, err = httpClient.Get(ui.URL + "/api/clusters")
+		require.NoError(t, err)
+		var echoed map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&echoed))
+		resp.Body.Close()
+		assert.Equal(t, i, provider.refreshes)
+		assert.NotEqual(t, "Bearer "+loginIDToken, echoed["authorization"])
+		assert.Equal(t, "Bearer "+provider.lastIDToken, echoed["authorization"])
+	}
+
+	// Once the provider refuses the refresh token the session ends with the ID token
+	provider.refreshToken = "revoked"
+	resp, err = httpClient.Get(ui.URL + "/auth/session")
+	require.NoError(t, err)
+	var info map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	resp.Body.Close()
+	assert.Equal(t, true, info["authenticated"])
+	assert.Equal(t, 2, provider.refreshes)
+}
+
+func TestOIDCAuthorizeAPI(t *testing.T) {
+	provider := newMockProvider(t)
+	_, auth := newTestUIServer(t, provider)
+
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	validSession, err := auth.encrypt(sessionCookieName, session{IDToken: "id-token", Subject: "alice", Expiry: now.Add(time.Hour)})
+	require.NoError(t, err)
+	expiredSession, err := auth.encrypt(sessionCookieName, session{IDToken: "id-token", Subject: "alice", Expiry: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	otherCookie, err := auth.encrypt(loginCookieName, session{IDToken: "id-token", Subject: "alice", Expiry: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                  string
+		cookie                string
+		authorization         string
+		expectedAuthorization string
+	}{
+		{
+			name:                  "valid session",
+			cookie:                validSession,
+			expectedAuthorization: "Bearer id-token",
+		},
+		{
+			name:                  "existing header is kept",
+			cookie:                validSession,
+			authorization:         "Bearer pasted-token",
+			expectedAuthorization: "Bearer pasted-token",
+		},
+		{
+			name:                  "expired session",
+			cookie:                expiredSession,
+			expectedAuthorization: "",
+		},
+		{
+			name:                  "tampered cookie",
+			cookie:                strings.ToUpper(validSession),
+			expectedAuthorization: "",
+		},
+		{
+			name:                  "value of another cookie",
+			cookie:                otherCookie,
+			expectedAuthorization: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/clusters", nil)
+			c.Request.AddCookie(&http.Cookie{Name: sessionCookieName, Value: tt.cookie})
+			c.Request.AddCookie(&http.Cookie{Name: loginCookieName + ".1", Value: "chunk"})
+			c.Request.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+			if tt.authorization != "" {
+				c.Request.Header.Set("Authorization", tt.authorization)
+			}
+
+			auth.authorizeAPI(c)
+
+			assert.Equal(t, tt.expectedAuthorization, c.Request.Header.Get("Authorization"))
+			assert.Equal(t, "theme=dark", c.Request.Header.Get("Cookie"))
+		})
+	}
+}
+
+func TestOIDCChunkedCookies(t *testing.T) {
+	provider := newMockProvider(t)
+	_, auth := newTestUIServer(t, provider)
+
+	setCookies := func(req *http.Request, value interface{}) []*http.Cookie {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		require.NoError(t, auth.setCookie(c, sessionCookieName, value, time.Now().Add(time.Hour)))
+		return w.Result().Cookies()
+	}
+
+	// A large ID token is split over several cookies, each below the browser limit
+	large := session{IDToken: strings.Repeat("x", 2*cookieChunkSize), Subject: "alice"}
+	cookies := setCookies(httptest.NewRequest(http.MethodGet, "/", nil), large)
+	require.Len(t, cookies, 3)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i, cookie := range cookies {
+		assert.Equal(t, chunkName(sessionCookieName, i), cookie.Name)
+		assert.Less(t, len(cookie.String()), 4096)
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	var decoded session
+	require.NoError(t, auth.readCookie(req, sessionCookieName, &decoded))
+	assert.Equal(t, large, decoded)
+
+	// Chunks are not interchangeable with another value's
+	reordered := httptest.NewRequest(http.MethodGet, "/", nil)
+	reordered.AddCookie(&http.Cookie{Name: sessionCookieName, Value: cookies[1].Value})
+	reordered.AddCookie(&http.Cookie{Name: sessionCookieName + ".1", Value: cookies[0].Value})
+	assert.Error(t, auth.readCookie(reordered, sessionCookieName, &decoded))
+
+	// A smaller value expires the chunks left over from the larger one
+	cookies = setCookies(req, session{IDToken: "id-token", Subject: "alice"})
+	require.Len(t, cookies, 3)
+	assert.Equal(t, sessionCookieName, cookies[0].Name)
+	assert.Equal(t, -1, cookies[1].MaxAge)
+	assert.Equal(t, -1, cookies[2].MaxAge)
+
+	// Values beyond the chunk limit are refused
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Error(t, auth.setCookie(c, sessionCookieName, session{IDToken: strings.Repeat("x", maxCookieChunks*cookieChunkSize)}, time.Now().Add(time.Hour)))
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := []struct {
+		redirect string
+		expected string
+	}{
+		{redirect: "", expected: "/"},
+		{redirect: "/clusters?tab=addons", expected: "/clusters?tab=addons"},
+		{redirect: "https://evil.example.com", expected: "/"},
+		{redirect: "//evil.example.com", expected: "/"},
+		{redirect: "/\\evil.example.com", expected: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.redirect, func(t *testing.T) {
+			assert.Equal(t, tt.expected, safeRedirect(tt.redirect))
+		})
+	}
+}
+
+func TestOIDCConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		expectDisabled bool
+		expectError    bool
+		expectedScopes []string
+	}{
+		{
+			name:           "disabled without issuer",
+			env:            map[string]string{},
+			expectDisabled: true,
+		},
+		{
+			name: "default scopes",
+			env: map[string]string{
+				"OIDC_ISSUER_URL":   "https://issuer.example.com",
+				"OIDC_CLIENT_ID":    "ocm-dashboard",
+				"OIDC_REDIRECT_URL": "https://dashboard.example.com/auth/callback",
+				"OIDC_SESSION_KEY":  "0123456789abcdef0123456789abcdef",
+			},
+			expectedScopes: []string{"openid", "profile", "email"},
+		},
+		{
+			name: "custom scopes",
+			env: map[string]string{
+				"OIDC_ISSUER_URL":   "https://issuer.example.com",
+				"OIDC_CLIENT_ID":    "ocm-dashboard",
+				"OIDC_REDIRECT_URL": "https://dashboard.example.com/auth/callback",
+				"OIDC_SESSION_KEY":  "0123456789abcdef0123456789abcdef",
+				"OIDC_SCOPES":       "openid, groups",
+			},
+			expectedScopes: []string{"openid", "groups"},
+		},
+		{
+			name: "short session key",
+			env: map[string]string{
+				"OIDC_ISSUER_URL":   "https://issuer.example.com",
+				"OIDC_CLIENT_ID":    "ocm-dashboard",
+				"OIDC_REDIRECT_URL": "https://dashboard.example.com/auth/callback",
+				"OIDC_SESSION_KEY":  "short",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing client id",
+			env: map[string]string{
+				"OIDC_ISSUER_URL": "https://issuer.example.com",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"OIDC_ISSUER_URL", "OIDC_CLIENT_ID", "OIDC_REDIRECT_URL", "OIDC_SESSION_KEY", "OIDC_SCOPES"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := oidcConfigFromEnv()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectDisabled {
+				assert.Nil(t, cfg)
+				return
+			}
+			assert.Equal(t, tt.expectedScopes, cfg.Scopes)
+		})
+	}
+}
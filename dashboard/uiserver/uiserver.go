@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
@@ -11,6 +12,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// newAPIProxy creates the reverse proxy that forwards /api/* to the API server
+func newAPIProxy(apiURL *url.URL) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(apiURL)
+
+	// Modify proxy to handle headers properly
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		// Allow CORS
+		resp.Header.Set("Access-Control-Allow-Origin", "*")
+		resp.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		resp.Header.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		return nil
+	}
+
+	return proxy
+}
+
 func main() {
 	// Set Gin to release mode for production
 	gin.SetMode(gin.ReleaseMode)
@@ -42,22 +59,37 @@ func main() {
 		apiURL, _ = url.Parse("http://localhost:8080")
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(apiURL)
+	proxy := newAPIProxy(apiURL)
 
-	// Modify proxy to handle headers properly
-	proxy.ModifyResponse = func(resp *http.Response) error {
-		// Allow CORS
-		resp.Header.Set("Access-Control-Allow-Origin", "*")
-		resp.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		resp.Header.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		return nil
+	// Optional OIDC login, the session's ID token is sent to the API as a bearer token
+	var apiHandlers []gin.HandlerFunc
+	oidcCfg, err := oidcConfigFromEnv()
+	if err != nil {
+		fmt.Printf("Invalid OIDC configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if oidcCfg != nil {
+		auth, err := newOIDCAuth(context.Background(), oidcCfg)
+		if err != nil {
+			fmt.Printf("Error setting up OIDC login: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("OIDC login enabled with issuer %s\n", oidcCfg.IssuerURL)
+		auth.registerRoutes(r)
+		apiHandlers = append(apiHandlers, auth.authorizeAPI)
+	} else {
+		// Let the frontend know to fall back to token login
+		r.GET("/auth/session", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"enabled": false, "authenticated": false})
+		})
 	}
 
 	// API proxy routes - forward all /api/* requests to API container
-	r.Any("/api/*path", func(c *gin.Context) {
+	apiHandlers = append(apiHandlers, func(c *gin.Context) {
 		fmt.Printf("Proxying API request: %s %s\n", c.Request.Method, c.Request.URL.Path)
 		proxy.ServeHTTP(c.Writer, c.Request)
 	})
+	r.Any("/api/*path", apiHandlers...)
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {